@@ -0,0 +1,58 @@
+// Package analysis содержит проверки модулей 1С поверх разобранного дерева и разрешения имен пакета ast:
+// неиспользуемые переменные, чтение до присваивания, недостижимый код и т.п. Каждая проверка возвращает
+// диагностики с участками кода и, где это возможно, исправления в виде правок текста ast.TextEdit
+package analysis
+
+import (
+	"sort"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+// Severity важность диагностики
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return ""
+	}
+}
+
+// Diagnostic найденная проблема. Code - идентификатор проверки (UnusedVariable, UnreachableCode...)
+type Diagnostic struct {
+	Code     string
+	Severity Severity
+	Message  string
+	Range    ast.Range
+	Fixes    []Fix
+}
+
+// Fix предлагаемое исправление: правки исходного кода, которые применяются вместе через ast.ApplyEdits
+type Fix struct {
+	Message string
+	Edits   []ast.TextEdit
+}
+
+// SortDiagnostics упорядочивает диагностики по положению в исходном коде
+func SortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Range.Start, diagnostics[j].Range.Start
+		if a.Offset != b.Offset {
+			return a.Offset < b.Offset
+		}
+		return diagnostics[i].Code < diagnostics[j].Code
+	})
+}
@@ -0,0 +1,249 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const CodeUseBeforeAssignment = "UseBeforeAssignment"
+
+// UseBeforeAssignment находит чтения локальных переменных, которым на каком-то пути выполнения метода
+// еще не присвоено значение. Учитываются ветки Если, циклы (тело может не выполниться ни разу),
+// Попытка (исключение может возникнуть на любом операторе), Возврат, Прервать и Перейти.
+// Параметры, переменные Перем, переменные модуля и имена глобального контекста считаются определенными,
+// так что обычно диагностика указывает на опечатку в имени или забытое присваивание.
+// Имена, которые нигде в модуле не объявлены, могут быть реквизитами формы или объекта, поэтому
+// проверяются только с conf.Undeclared
+func UseBeforeAssignment(module *ast.ModuleStatement, conf AssignedConf) []Diagnostic {
+	res := ast.Resolve(module)
+	c := &assignCheck{res: res, conf: conf, heads: map[int]bool{}}
+
+	var body ast.Statements
+	for _, item := range module.Body {
+		if pf, ok := item.(*ast.FunctionOrProcedure); ok {
+			c.labels = map[string]assigned{}
+			c.block(pf.Body, assigned{})
+		} else {
+			body = append(body, item)
+		}
+	}
+	c.labels = map[string]assigned{}
+	c.block(body, assigned{})
+
+	SortDiagnostics(c.diagnostics)
+	return c.diagnostics
+}
+
+// AssignedConf настройки UseBeforeAssignment
+type AssignedConf struct {
+	// Undeclared сообщать о чтении имен, не объявленных в модуле. Подходит для модулей без контекста формы
+	// или объекта (общие модули, внешние обработки без реквизитов). Первое имя в цепочке (ОбщийМодуль.Метод())
+	// не проверяется - это общий модуль или менеджер
+	Undeclared bool
+}
+
+// assigned переменные, которым значение присвоено на всех путях. nil - точка недостижима
+type assigned map[*ast.Symbol]bool
+
+func (a assigned) copy() assigned {
+	if a == nil {
+		return nil
+	}
+
+	result := make(assigned, len(a))
+	for k := range a {
+		result[k] = true
+	}
+
+	return result
+}
+
+// join объединяет состояния двух путей: присвоенными остаются переменные, присвоенные на обоих
+func join(a, b assigned) assigned {
+	switch {
+	case a == nil:
+		return b.copy()
+	case b == nil:
+		return a.copy()
+	}
+
+	result := assigned{}
+	for k := range a {
+		if b[k] {
+			result[k] = true
+		}
+	}
+
+	return result
+}
+
+type assignCheck struct {
+	res         *ast.Resolution
+	conf        AssignedConf
+	heads       map[int]bool        // смещения имен, с которых начинаются цепочки вызовов
+	labels      map[string]assigned // состояния в переходах Перейти по именам меток
+	diagnostics []Diagnostic
+}
+
+func (c *assignCheck) block(items ast.Statements, state assigned) assigned {
+	for _, item := range items {
+		state = c.statement(item, state)
+	}
+
+	return state
+}
+
+func (c *assignCheck) statement(stm ast.Statement, state assigned) assigned {
+	switch v := stm.(type) {
+	case ast.AssignmentStatement:
+		c.expression(v.Expr, state)
+		c.assign(v.Var, state)
+	case *ast.IfStatement:
+		c.expression(v.Expression, state)
+		result := c.block(v.TrueBlock, state.copy())
+		for _, item := range v.IfElseBlock {
+			elseIf := item.(*ast.IfStatement)
+			c.expression(elseIf.Expression, state)
+			result = join(result, c.block(elseIf.TrueBlock, state.copy()))
+		}
+		return join(result, c.block(v.ElseBlock, state.copy()))
+	case *ast.LoopStatement:
+		body := state.copy()
+		switch {
+		case v.WhileExpr != nil:
+			c.expression(v.WhileExpr, state)
+		case v.In != nil:
+			c.expression(v.In, state)
+			c.loopVariable(v, body)
+		default:
+			if exp, ok := v.For.(*ast.ExpStatement); ok {
+				c.expression(exp.Right, state)
+				c.assign(exp.Left, state)
+				c.assign(exp.Left, body)
+			}
+			c.expression(v.To, state)
+		}
+
+		// тело может не выполниться ни разу, а присваивания после Прервать не влияют на состояние после цикла
+		c.block(v.Body, body)
+	case ast.TryStatement:
+		// исключение может возникнуть до первого присваивания в Попытке
+		result := c.block(v.Body, state.copy())
+		return join(result, c.block(v.Catch, state.copy()))
+	case ast.ThrowStatement:
+		c.expression(v.Param, state)
+		return nil
+	case *ast.ReturnStatement:
+		c.expression(v.Param, state)
+		return nil
+	case ast.BreakStatement, ast.ContinueStatement:
+		return nil
+	case ast.GoToStatement:
+		if v.Label != nil && state != nil {
+			key := ast.NormalizeName(v.Label.Name)
+			if prev, ok := c.labels[key]; ok {
+				c.labels[key] = join(prev, state)
+			} else {
+				c.labels[key] = state.copy()
+			}
+		}
+		return nil
+	case *ast.GoToLabelStatement:
+		// при переходе назад присвоено не меньше, чем при первом проходе через метку, учитываем только переходы вперед
+		if jump, ok := c.labels[ast.NormalizeName(v.Name)]; ok {
+			if state == nil {
+				return jump.copy()
+			}
+			return join(state, jump)
+		}
+		if state == nil {
+			// на метку переходят только ниже по тексту, проверять дальше нечего
+			return assigned{}
+		}
+	default:
+		c.expression(stm, state)
+	}
+
+	return state
+}
+
+func (c *assignCheck) loopVariable(loop *ast.LoopStatement, state assigned) {
+	if state == nil {
+		return
+	}
+
+	for _, ref := range c.res.References {
+		if ref.Loop && ref.Range.Start == loop.Pos {
+			state[ref.Symbol] = true
+		}
+	}
+}
+
+func (c *assignCheck) assign(target ast.Statement, state assigned) {
+	if v, ok := target.(ast.VarStatement); ok {
+		if ref := c.res.ReferenceOf(v); ref != nil && ref.Write && state != nil {
+			state[ref.Symbol] = true
+		}
+		return
+	}
+
+	c.expression(target, state)
+}
+
+func (c *assignCheck) expression(expr ast.Statement, state assigned) {
+	if state == nil {
+		// в недостижимом коде чтения не проверяются
+		return
+	}
+
+	inspect(expr, func(stm ast.Statement) bool {
+		if chain, ok := stm.(ast.CallChainStatement); ok {
+			if head, ok := chainHead(chain).(ast.VarStatement); ok {
+				c.heads[head.Pos.Offset] = true
+			}
+			return true
+		}
+
+		v, ok := stm.(ast.VarStatement)
+		if !ok {
+			return true
+		}
+
+		ref := c.res.ReferenceOf(v)
+		if ref == nil || ref.Write || state[ref.Symbol] {
+			return true
+		}
+
+		message := "variable %q may be used before assignment"
+		switch {
+		case ref.Symbol.Kind == ast.SymbolLocal:
+		case ref.Symbol.Kind == ast.SymbolExternal && c.conf.Undeclared && !c.heads[v.Pos.Offset]:
+			message = "variable %q is not declared and never assigned"
+		default:
+			return true
+		}
+
+		c.diagnostics = append(c.diagnostics, Diagnostic{
+			Code:     CodeUseBeforeAssignment,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf(message, v.Name),
+			Range:    ref.Range,
+		})
+
+		// об одной переменной сообщаем один раз на путь, дальше считаем ее присвоенной
+		state[ref.Symbol] = true
+		return true
+	})
+}
+
+// chainHead первое звено цепочки вызовов а.б.в()
+func chainHead(chain ast.CallChainStatement) ast.Statement {
+	for {
+		next, ok := chain.Call.(ast.CallChainStatement)
+		if !ok {
+			return chain.Call
+		}
+		chain = next
+	}
+}
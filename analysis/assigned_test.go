@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseBeforeAssignment(t *testing.T) {
+	check := func(t *testing.T, code string, conf ...AssignedConf) []string {
+		a := ast.NewAST(code)
+		if !assert.NoError(t, a.Parse()) {
+			return nil
+		}
+
+		var result []string
+		conf = append(conf, AssignedConf{})
+		for _, d := range UseBeforeAssignment(&a.ModuleStatement, conf[0]) {
+			result = append(result, d.Message)
+		}
+		return result
+	}
+
+	t.Run("branches", func(t *testing.T) {
+		code := `Функция Ф(Параметр)
+	Перем Явная;
+	Если Параметр Тогда
+		Итог = 1;
+		Частично = 1;
+	ИначеЕсли Явная Тогда
+		Итог = 2;
+	Иначе
+		Итог = 3;
+		Частично = 2;
+	КонецЕсли;
+
+	Сообщить(Справочники);
+	Сообщить(Итог + ЧАСТИЧНО);
+	Сообщить(Частично);
+	Возврат Итог;
+КонецФункции`
+
+		assert.Equal(t, []string{`variable "ЧАСТИЧНО" may be used before assignment`}, check(t, code))
+	})
+	t.Run("loops and try", func(t *testing.T) {
+		code := `Процедура П(Список)
+	Для Инд = 0 По 10 Цикл
+		Сумма = Сумма + Инд;
+	КонецЦикла;
+	Сообщить(Инд);
+
+	Для Каждого Элемент Из Список Цикл
+		Последний = Элемент;
+	КонецЦикла;
+	Сообщить(Элемент);
+	Сообщить(Последний);
+
+	Попытка
+		Результат = Список[0];
+	Исключение
+		Сообщить(Результат);
+		Результат = Неопределено;
+	КонецПопытки;
+	Сообщить(Результат);
+
+	Пока Истина Цикл
+		Флаг = 1;
+		Прервать;
+	КонецЦикла;
+	Сообщить(Флаг);
+КонецПроцедуры`
+
+		assert.Equal(t, []string{
+			`variable "Сумма" may be used before assignment`,
+			`variable "Элемент" may be used before assignment`,
+			`variable "Последний" may be used before assignment`,
+			`variable "Результат" may be used before assignment`,
+			`variable "Флаг" may be used before assignment`,
+		}, check(t, code))
+	})
+	t.Run("goto", func(t *testing.T) {
+		code := `Процедура П()
+	Значение = 1;
+	Если Значение Тогда
+		Перейти ~Конец;
+	КонецЕсли;
+	Прочее = 2;
+	~Конец:
+	Сообщить(Значение + Прочее);
+КонецПроцедуры
+
+Значение = 1;
+Сообщить(Значение);`
+
+		assert.Equal(t, []string{`variable "Прочее" may be used before assignment`}, check(t, code))
+	})
+	t.Run("undeclared", func(t *testing.T) {
+		code := `Функция Ф()
+	Сообщить(Справочники);
+	Сообщить(ОбщегоНазначения.Модуль().Значение);
+	Если СтрокаСОпечаткой = "" Тогда
+		Возврат СтрокаСОпечаткой;
+	КонецЕсли;
+	Возврат Реквизит;
+КонецФункции`
+
+		// без настройки имена могут быть реквизитами формы или объекта
+		assert.Empty(t, check(t, code))
+		assert.Equal(t, []string{
+			`variable "СтрокаСОпечаткой" is not declared and never assigned`,
+			`variable "Реквизит" is not declared and never assigned`,
+		}, check(t, code, AssignedConf{Undeclared: true}))
+	})
+}
@@ -0,0 +1,226 @@
+package analysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const (
+	CodeCyclomaticComplexity = "CyclomaticComplexity"
+	CodeCognitiveComplexity  = "CognitiveComplexity"
+)
+
+// Metrics метрики сложности метода
+type Metrics struct {
+	Method string `json:"method"`
+	Line   int    `json:"line"`
+	// Cyclomatic цикломатическая сложность: 1 + ветки Если и ИначеЕсли, циклы, ?(), Исключение и операторы И/ИЛИ
+	Cyclomatic int `json:"cyclomatic"`
+	// Cognitive когнитивная сложность (методика SonarSource): Если, циклы, Исключение и ?() увеличивают ее
+	// на 1 плюс уровень вложенности, ИначеЕсли, Иначе, Перейти и каждая последовательность одинаковых И/ИЛИ - на 1
+	Cognitive int `json:"cognitive"`
+	// MaxNesting наибольшая вложенность Если, циклов и Попытка друг в друга
+	MaxNesting int `json:"maxNesting"`
+	// Statements количество операторов, включая вложенные
+	Statements int `json:"statements"`
+	Params     int `json:"params"`
+}
+
+// Complexity считает метрики каждой процедуры и функции модуля
+func Complexity(module *ast.ModuleStatement) []Metrics {
+	var result []Metrics
+	for _, pf := range methods(module) {
+		m := &metricsCounter{metrics: Metrics{
+			Method:     pf.Name,
+			Line:       ast.PositionOf(pf).Line,
+			Cyclomatic: 1,
+			Params:     len(pf.Params),
+		}}
+		for _, p := range pf.Params {
+			m.expression(p.Default, 0)
+		}
+		m.statements(pf.Body, 0, 0)
+		result = append(result, m.metrics)
+	}
+
+	return result
+}
+
+// ComplexityConf пороги сложности метода, 0 - не проверять
+type ComplexityConf struct {
+	MaxCyclomatic int
+	MaxCognitive  int
+}
+
+// ComplexMethods сообщает о методах, сложность которых превышает пороги conf
+func ComplexMethods(module *ast.ModuleStatement, conf ComplexityConf) []Diagnostic {
+	var result []Diagnostic
+	metrics := Complexity(module)
+	for i, pf := range methods(module) {
+		m := metrics[i]
+		if conf.MaxCyclomatic > 0 && m.Cyclomatic > conf.MaxCyclomatic {
+			result = append(result, Diagnostic{
+				Code:     CodeCyclomaticComplexity,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("cyclomatic complexity of %q is %d, maximum is %d", pf.Name, m.Cyclomatic, conf.MaxCyclomatic),
+				Range:    ast.RangeOf(pf),
+			})
+		}
+		if conf.MaxCognitive > 0 && m.Cognitive > conf.MaxCognitive {
+			result = append(result, Diagnostic{
+				Code:     CodeCognitiveComplexity,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("cognitive complexity of %q is %d, maximum is %d", pf.Name, m.Cognitive, conf.MaxCognitive),
+				Range:    ast.RangeOf(pf),
+			})
+		}
+	}
+
+	return result
+}
+
+// MetricsReport метрики методов одного модуля
+type MetricsReport struct {
+	File    string    `json:"file"`
+	Methods []Metrics `json:"methods"`
+}
+
+// WriteMetricsJSON выводит метрики модулей массивом MetricsReport
+func WriteMetricsJSON(w io.Writer, reports []MetricsReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(reports)
+}
+
+// WriteMetricsCSV выводит метрики модулей в CSV, по строке на метод
+func WriteMetricsCSV(w io.Writer, reports []MetricsReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"file", "method", "line", "cyclomatic", "cognitive", "maxNesting", "statements", "params"}); err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		for _, m := range report.Methods {
+			err := writer.Write([]string{
+				report.File, m.Method, strconv.Itoa(m.Line), strconv.Itoa(m.Cyclomatic), strconv.Itoa(m.Cognitive),
+				strconv.Itoa(m.MaxNesting), strconv.Itoa(m.Statements), strconv.Itoa(m.Params),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+type metricsCounter struct {
+	metrics Metrics
+}
+
+// structure учитывает управляющую конструкцию на уровне вложенности nesting
+func (m *metricsCounter) structure(nesting int) {
+	m.metrics.Cyclomatic++
+	m.metrics.Cognitive += 1 + nesting
+}
+
+// statements обходит операторы: nesting - уровень вложенности для когнитивной сложности, depth - для MaxNesting.
+// Они отличаются только в теле Попытки
+func (m *metricsCounter) statements(items ast.Statements, nesting, depth int) {
+	m.metrics.MaxNesting = max(m.metrics.MaxNesting, depth)
+
+	for _, item := range items {
+		m.metrics.Statements++
+
+		switch v := item.(type) {
+		case *ast.IfStatement:
+			m.structure(nesting)
+			m.expression(v.Expression, nesting)
+			m.statements(v.TrueBlock, nesting+1, depth+1)
+			for _, elseIf := range v.IfElseBlock {
+				branch := elseIf.(*ast.IfStatement)
+				m.metrics.Cyclomatic++
+				m.metrics.Cognitive++
+				m.expression(branch.Expression, nesting)
+				m.statements(branch.TrueBlock, nesting+1, depth+1)
+			}
+			if v.ElseBlock != nil {
+				m.metrics.Cognitive++
+				m.statements(v.ElseBlock, nesting+1, depth+1)
+			}
+		case *ast.LoopStatement:
+			m.structure(nesting)
+			if _, ok := v.For.(string); !ok {
+				m.expression(v.For, nesting)
+			}
+			m.expression(v.In, nesting)
+			m.expression(v.To, nesting)
+			m.expression(v.WhileExpr, nesting)
+			m.statements(v.Body, nesting+1, depth+1)
+		case ast.TryStatement:
+			// сама Попытка когнитивную сложность и вложенность для нее не увеличивает, в отличие от Исключение
+			m.statements(v.Body, nesting, depth+1)
+			m.structure(nesting)
+			m.statements(v.Catch, nesting+1, depth+1)
+		case ast.GoToStatement:
+			m.metrics.Cognitive++
+		default:
+			m.expression(item, nesting)
+		}
+	}
+}
+
+// expression учитывает ?() и последовательности И/ИЛИ в выражении
+func (m *metricsCounter) expression(stm ast.Statement, nesting int) {
+	inspect(stm, func(item ast.Statement) bool {
+		switch v := item.(type) {
+		case ast.TernaryStatement:
+			m.structure(nesting)
+			m.expression(v.Expression, nesting)
+			m.expression(v.TrueBlock, nesting+1)
+			m.expression(v.ElseBlock, nesting+1)
+			return false
+		case *ast.ExpStatement:
+			if v.Operation != ast.OpAnd && v.Operation != ast.OpOr {
+				return true
+			}
+
+			var operands []ast.Statement
+			var last ast.OperationType = -1
+			logical(v, func(op ast.OperationType) {
+				m.metrics.Cyclomatic++
+				if op != last {
+					m.metrics.Cognitive++
+				}
+				last = op
+			}, func(operand ast.Statement) {
+				operands = append(operands, operand)
+			})
+			for _, operand := range operands {
+				m.expression(operand, nesting)
+			}
+			return false
+		}
+		return true
+	})
+}
+
+// logical обходит цепочку И/ИЛИ слева направо: op вызывается для операторов, operand - для операндов,
+// которые сами не являются И/ИЛИ
+func logical(stm ast.Statement, op func(ast.OperationType), operand func(ast.Statement)) {
+	if v, ok := stm.(*ast.ExpStatement); ok && (v.Operation == ast.OpAnd || v.Operation == ast.OpOr) {
+		logical(v.Left, op, operand)
+		op(v.Operation)
+		logical(v.Right, op, operand)
+		return
+	}
+
+	operand(stm)
+}
@@ -0,0 +1,83 @@
+package analysis
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplexity(t *testing.T) {
+	code := `Процедура Простая()
+	Сообщить(1);
+КонецПроцедуры
+
+Функция Сложная(а, б = Истина, в)
+	Для Каждого Стр Из а Цикл                 // +1
+		Если Стр.Сумма > 0 И б И в Тогда    // +2 (вложенность 1), +1 за И И
+			Продолжить;
+		ИначеЕсли Стр.Сумма < 0 ИЛИ б И в Тогда // +1, +2 за ИЛИ и И
+			Попытка
+				Стр.Сумма = ?(б, 1, 2);    // +3 (вложенность 2)
+			Исключение                    // +3 (вложенность 2)
+				Перейти ~Конец;           // +1
+			КонецПопытки;
+		Иначе                              // +1
+			Пока в Цикл                       // +3 (вложенность 2)
+				в = Ложь;
+			КонецЦикла;
+		КонецЕсли;
+	КонецЦикла;
+	~Конец:
+	Возврат а ИЛИ б;                       // +1
+КонецФункции`
+
+	a := ast.NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	metrics := Complexity(&a.ModuleStatement)
+	assert.Equal(t, []Metrics{
+		{Method: "Простая", Line: 1, Cyclomatic: 1, Statements: 1},
+		{Method: "Сложная", Line: 5, Cyclomatic: 12, Cognitive: 19, MaxNesting: 3, Statements: 10, Params: 3},
+	}, metrics)
+
+	t.Run("diagnostics", func(t *testing.T) {
+		diagnostics := ComplexMethods(&a.ModuleStatement, ComplexityConf{MaxCyclomatic: 10, MaxCognitive: 15})
+		if assert.Len(t, diagnostics, 2) {
+			assert.Equal(t, `cyclomatic complexity of "Сложная" is 12, maximum is 10`, diagnostics[0].Message)
+			assert.Equal(t, `cognitive complexity of "Сложная" is 19, maximum is 15`, diagnostics[1].Message)
+		}
+		assert.Empty(t, ComplexMethods(&a.ModuleStatement, ComplexityConf{}))
+	})
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, WriteMetricsJSON(buf, []MetricsReport{{File: "Module.bsl", Methods: metrics[:1]}}))
+		assert.Equal(t, `[
+  {
+    "file": "Module.bsl",
+    "methods": [
+      {
+        "method": "Простая",
+        "line": 1,
+        "cyclomatic": 1,
+        "cognitive": 0,
+        "maxNesting": 0,
+        "statements": 1,
+        "params": 0
+      }
+    ]
+  }
+]
+`, buf.String())
+	})
+	t.Run("csv", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, WriteMetricsCSV(buf, []MetricsReport{{File: "Module.bsl", Methods: metrics}}))
+		assert.Equal(t, "file,method,line,cyclomatic,cognitive,maxNesting,statements,params\n"+
+			"Module.bsl,Простая,1,1,0,0,1,0\n"+
+			"Module.bsl,Сложная,5,12,19,3,10,3\n", buf.String())
+	})
+}
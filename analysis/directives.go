@@ -0,0 +1,292 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const (
+	CodeClientCallFromServer     = "ClientCallFromServer"
+	CodeContextCallFromNoContext = "ContextCallFromNoContext"
+	CodeServerCallInLoop         = "ServerCallInLoop"
+	CodeTooManyServerCalls       = "TooManyServerCalls"
+)
+
+// ExecutionContext где выполняется метод, по директиве компиляции
+type ExecutionContext int
+
+const (
+	ContextUnknown                 ExecutionContext = iota // директивы нет, например в общем модуле
+	ContextClient                                          // &НаКлиенте
+	ContextServer                                          // &НаСервере
+	ContextServerNoContext                                 // &НаСервереБезКонтекста
+	ContextClientAtServer                                  // &НаКлиентеНаСервере
+	ContextClientAtServerNoContext                         // &НаКлиентеНаСервереБезКонтекста
+)
+
+var executionContexts = map[string]ExecutionContext{
+	"&наклиенте": ContextClient,
+	"&atclient":  ContextClient,
+	"&насервере": ContextServer,
+	"&atserver":  ContextServer,
+	"&насерверебезконтекста":          ContextServerNoContext,
+	"&atservernocontext":              ContextServerNoContext,
+	"&наклиентенасервере":             ContextClientAtServer,
+	"&atclientatserver":               ContextClientAtServer,
+	"&наклиентенасерверебезконтекста": ContextClientAtServerNoContext,
+	"&atclientatservernocontext":      ContextClientAtServerNoContext,
+}
+
+func (c ExecutionContext) String() string {
+	switch c {
+	case ContextClient:
+		return "&НаКлиенте"
+	case ContextServer:
+		return "&НаСервере"
+	case ContextServerNoContext:
+		return "&НаСервереБезКонтекста"
+	case ContextClientAtServer:
+		return "&НаКлиентеНаСервере"
+	case ContextClientAtServerNoContext:
+		return "&НаКлиентеНаСервереБезКонтекста"
+	default:
+		return ""
+	}
+}
+
+// server метод может выполняться на сервере
+func (c ExecutionContext) server() bool {
+	return c != ContextUnknown && c != ContextClient
+}
+
+// client метод может выполняться на клиенте
+func (c ExecutionContext) client() bool {
+	return c == ContextClient || c == ContextClientAtServer || c == ContextClientAtServerNoContext
+}
+
+// noContext методу недоступен контекст формы
+func (c ExecutionContext) noContext() bool {
+	return c == ContextServerNoContext || c == ContextClientAtServerNoContext
+}
+
+// ContextOf возвращает контекст выполнения метода по его директиве. Директивы расширений (&Перед, &Вместо...) не учитываются
+func ContextOf(pf *ast.FunctionOrProcedure) ExecutionContext {
+	for _, d := range pf.Directives {
+		if d == nil {
+			continue
+		}
+		if c, ok := executionContexts[strings.ToLower(d.Name)]; ok {
+			return c
+		}
+	}
+
+	return ContextUnknown
+}
+
+// DirectivesConf настройки проверки директив компиляции
+type DirectivesConf struct {
+	// MaxServerCalls сколько серверных вызовов допускается в клиентском методе, 0 - не проверять
+	MaxServerCalls int
+}
+
+// ServerCalls серверные вызовы клиентского метода
+type ServerCalls struct {
+	Method *ast.FunctionOrProcedure
+	// Calls вызовы серверных методов модуля, в том числе через вызываемые клиентские методы модуля.
+	// Каждый вызов - отдельное обращение клиента к серверу
+	Calls int
+	// InLoops вызовы из Calls, которые выполняются в цикле
+	InLoops int
+}
+
+// CountServerCalls считает серверные вызовы каждого метода &НаКлиенте модуля. Вызов клиентского метода модуля
+// добавляет его серверные вызовы, вызов в цикле считается один раз. Методы без серверных вызовов не возвращаются
+func CountServerCalls(module *ast.ModuleStatement) []ServerCalls {
+	d := newDirectiveCheck(module)
+
+	var result []ServerCalls
+	for _, pf := range methods(module) {
+		if ContextOf(pf) != ContextClient {
+			continue
+		}
+
+		if calls := d.serverCalls(pf); calls.Calls > 0 {
+			result = append(result, calls)
+		}
+	}
+
+	return result
+}
+
+// Directives проверяет вызовы методов модуля с учетом директив компиляции:
+//   - ClientCallFromServer - метод, выполняющийся на сервере, вызывает метод &НаКлиенте;
+//   - ContextCallFromNoContext - метод без контекста формы (&НаСервереБезКонтекста, &НаКлиентеНаСервереБезКонтекста)
+//     вызывает метод, которому контекст нужен;
+//   - ServerCallInLoop - клиентский метод обращается к серверу в цикле;
+//   - TooManyServerCalls - клиентский метод обращается к серверу больше conf.MaxServerCalls раз.
+//
+// Методы без директив (общие модули, модули объектов) не проверяются
+func Directives(module *ast.ModuleStatement, conf DirectivesConf) []Diagnostic {
+	d := newDirectiveCheck(module)
+
+	var result []Diagnostic
+	for _, pf := range methods(module) {
+		caller := ContextOf(pf)
+		if caller == ContextUnknown {
+			continue
+		}
+
+		d.calls(pf.Body, false, func(call ast.MethodStatement, callee *ast.FunctionOrProcedure, inLoop bool) {
+			switch target := ContextOf(callee); {
+			case caller.server() && target == ContextClient:
+				result = append(result, Diagnostic{
+					Code:     CodeClientCallFromServer,
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("%s method %q calls client method %q", caller, pf.Name, callee.Name),
+					Range:    ast.RangeOf(call),
+				})
+			case caller.noContext() && target != ContextUnknown && !target.noContext():
+				result = append(result, Diagnostic{
+					Code:     CodeContextCallFromNoContext,
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("%s method %q calls %s method %q that requires form context", caller, pf.Name, target, callee.Name),
+					Range:    ast.RangeOf(call),
+				})
+			case caller == ContextClient && inLoop && d.serverCall(callee):
+				result = append(result, Diagnostic{
+					Code:     CodeServerCallInLoop,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("client method %q calls server inside a loop via %q", pf.Name, callee.Name),
+					Range:    ast.RangeOf(call),
+				})
+			}
+		})
+
+		if caller != ContextClient || conf.MaxServerCalls <= 0 {
+			continue
+		}
+		if calls := d.serverCalls(pf); calls.Calls > conf.MaxServerCalls {
+			result = append(result, Diagnostic{
+				Code:     CodeTooManyServerCalls,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("client method %q makes %d server calls, maximum is %d", pf.Name, calls.Calls, conf.MaxServerCalls),
+				Range:    ast.RangeOf(pf),
+			})
+		}
+	}
+
+	SortDiagnostics(result)
+	return result
+}
+
+type directiveCheck struct {
+	local   map[string]*ast.FunctionOrProcedure
+	counted map[*ast.FunctionOrProcedure]ServerCalls // посчитанные serverCalls
+}
+
+func newDirectiveCheck(module *ast.ModuleStatement) *directiveCheck {
+	d := &directiveCheck{local: map[string]*ast.FunctionOrProcedure{}, counted: map[*ast.FunctionOrProcedure]ServerCalls{}}
+	for _, pf := range methods(module) {
+		d.local[ast.NormalizeName(pf.Name)] = pf
+	}
+
+	return d
+}
+
+// calls вызывает f для каждого вызова метода модуля в stm. inLoop - вызов находится в теле или условии цикла
+func (d *directiveCheck) calls(stm ast.Statement, inLoop bool, f func(call ast.MethodStatement, callee *ast.FunctionOrProcedure, inLoop bool)) {
+	inspect(stm, func(item ast.Statement) bool {
+		switch v := item.(type) {
+		case ast.CallChainStatement:
+			// Объект.Метод() - метод другого объекта
+			if method, ok := v.Unit.(ast.MethodStatement); ok {
+				d.calls(method.Param, inLoop, f)
+			} else {
+				d.calls(v.Unit, inLoop, f)
+			}
+			d.calls(v.Call, inLoop, f)
+			return false
+		case *ast.LoopStatement:
+			if _, ok := v.For.(string); !ok {
+				d.calls(v.For, inLoop, f)
+			}
+			d.calls(v.In, inLoop, f)
+			d.calls(v.To, inLoop, f)
+			d.calls(v.WhileExpr, true, f)
+			d.calls(v.Body, true, f)
+			return false
+		case ast.MethodStatement:
+			if callee, ok := d.local[ast.NormalizeName(v.Name)]; ok {
+				f(v, callee, inLoop)
+			}
+		}
+		return true
+	})
+}
+
+// serverCall проверяет, обращается ли вызов метода модуля с клиента к серверу: сам метод серверный
+// или это клиентский метод, который обращается к серверу
+func (d *directiveCheck) serverCall(callee *ast.FunctionOrProcedure) bool {
+	return d.serverCalls(callee).Calls > 0 || isServerOnly(callee)
+}
+
+// serverCalls считает серверные вызовы метода, выполняющегося на клиенте. Каждый метод считается один раз
+func (d *directiveCheck) serverCalls(pf *ast.FunctionOrProcedure) ServerCalls {
+	result, _ := d.countServerCalls(pf, map[*ast.FunctionOrProcedure]bool{}, map[*ast.FunctionOrProcedure]ServerCalls{})
+	return result
+}
+
+// countServerCalls считает серверные вызовы метода, visiting защищает от рекурсии. complete - при подсчете
+// не встретился метод из visiting: результат не зависит от того, откуда начат обход, и запоминается для всех
+// обходов. Остальные результаты запоминаются в partial только на время текущего обхода
+func (d *directiveCheck) countServerCalls(pf *ast.FunctionOrProcedure, visiting map[*ast.FunctionOrProcedure]bool,
+	partial map[*ast.FunctionOrProcedure]ServerCalls) (result ServerCalls, complete bool) {
+	if calls, ok := d.counted[pf]; ok {
+		return calls, true
+	}
+	if calls, ok := partial[pf]; ok {
+		return calls, false
+	}
+
+	result = ServerCalls{Method: pf}
+	if visiting[pf] {
+		return result, false
+	}
+	if !ContextOf(pf).client() {
+		return result, true
+	}
+	visiting[pf] = true
+	defer delete(visiting, pf)
+
+	complete = true
+	d.calls(pf.Body, false, func(_ ast.MethodStatement, callee *ast.FunctionOrProcedure, inLoop bool) {
+		sub := ServerCalls{Calls: 1}
+		if !isServerOnly(callee) {
+			var ok bool
+			sub, ok = d.countServerCalls(callee, visiting, partial)
+			complete = complete && ok
+		}
+
+		result.Calls += sub.Calls
+		if inLoop {
+			result.InLoops += sub.Calls
+		} else {
+			result.InLoops += sub.InLoops
+		}
+	})
+
+	if complete {
+		d.counted[pf] = result
+	} else {
+		partial[pf] = result
+	}
+	return result, complete
+}
+
+// isServerOnly метод выполняется только на сервере, его вызов с клиента - обращение к серверу
+func isServerOnly(pf *ast.FunctionOrProcedure) bool {
+	c := ContextOf(pf)
+	return c == ContextServer || c == ContextServerNoContext
+}
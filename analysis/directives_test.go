@@ -0,0 +1,120 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectives(t *testing.T) {
+	code := `&НаКлиенте
+Процедура ПриОткрытии(Отказ)
+	Для Каждого Стр Из Объект.Товары Цикл
+		Стр.Цена = ПолучитьЦену(Стр.Товар);
+		Пересчитать(Стр);
+	КонецЦикла;
+	ЗаполнитьНаСервере();
+	ОбщийМодуль.ЗаполнитьНаСервере();
+	Пересчитать(Неопределено);
+КонецПроцедуры
+
+&НаКлиенте
+Процедура Пересчитать(Стр)
+	Если Стр = Неопределено Тогда
+		Пересчитать(Стр);
+	КонецЕсли;
+	Стр.Сумма = Округлить(Стр.Сумма, 2);
+	ЗаполнитьНаСервере();
+КонецПроцедуры
+
+&НаСервереБезКонтекста
+Функция ПолучитьЦену(Товар)
+	ЗаполнитьНаСервере();
+	Возврат Общий(Товар);
+КонецФункции
+
+&НаСервере
+Процедура ЗаполнитьНаСервере()
+	Пересчитать(Неопределено);
+	ПолучитьЦену(Неопределено);
+КонецПроцедуры
+
+&НаКлиентеНаСервереБезКонтекста
+Функция Общий(Значение)
+	Возврат Значение;
+КонецФункции
+
+&AtClientAtServer
+Procedure Both()
+	ЗаполнитьНаСервере();
+	Пересчитать(1);
+EndProcedure
+
+Процедура БезДирективы()
+	Пересчитать(1);
+КонецПроцедуры`
+
+	a := ast.NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	format := func(diagnostics []Diagnostic) []string {
+		var result []string
+		for _, d := range diagnostics {
+			result = append(result, fmt.Sprintf("%d %s %s: %s", d.Range.Start.Line, d.Severity, d.Code, d.Message))
+		}
+		return result
+	}
+
+	t.Run("diagnostics", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`4 warning ServerCallInLoop: client method "ПриОткрытии" calls server inside a loop via "ПолучитьЦену"`,
+			`5 warning ServerCallInLoop: client method "ПриОткрытии" calls server inside a loop via "Пересчитать"`,
+			`23 error ContextCallFromNoContext: &НаСервереБезКонтекста method "ПолучитьЦену" calls &НаСервере method "ЗаполнитьНаСервере" that requires form context`,
+			`29 error ClientCallFromServer: &НаСервере method "ЗаполнитьНаСервере" calls client method "Пересчитать"`,
+			`41 error ClientCallFromServer: &НаКлиентеНаСервере method "Both" calls client method "Пересчитать"`,
+		}, format(Directives(&a.ModuleStatement, DirectivesConf{})))
+
+		assert.Equal(t, []string{
+			`1 warning TooManyServerCalls: client method "ПриОткрытии" makes 4 server calls, maximum is 3`,
+			`4 warning ServerCallInLoop: client method "ПриОткрытии" calls server inside a loop via "ПолучитьЦену"`,
+			`5 warning ServerCallInLoop: client method "ПриОткрытии" calls server inside a loop via "Пересчитать"`,
+		}, format(Directives(&a.ModuleStatement, DirectivesConf{MaxServerCalls: 3}))[:3])
+	})
+	t.Run("count", func(t *testing.T) {
+		var actual []string
+		for _, calls := range CountServerCalls(&a.ModuleStatement) {
+			actual = append(actual, fmt.Sprintf("%s %d %d", calls.Method.Name, calls.Calls, calls.InLoops))
+		}
+		assert.Equal(t, []string{"ПриОткрытии 4 2", "Пересчитать 1 0"}, actual)
+	})
+	t.Run("deep calls", func(t *testing.T) {
+		// каждый метод дважды вызывает следующий: без запоминания обход растет как 2^n
+		var code strings.Builder
+		for i := 0; i < 40; i++ {
+			fmt.Fprintf(&code, "&НаКлиенте\nПроцедура М%d()\n\tМ%d();\n\tМ%d();\nКонецПроцедуры\n\n", i, i+1, i+1)
+		}
+		code.WriteString("&НаКлиенте\nПроцедура М40()\n\tМ0();\n\tСервер();\nКонецПроцедуры\n\n&НаСервере\nПроцедура Сервер()\nКонецПроцедуры")
+
+		deep := ast.NewAST(code.String())
+		if !assert.NoError(t, deep.Parse()) {
+			return
+		}
+
+		calls := CountServerCalls(&deep.ModuleStatement)
+		if assert.Len(t, calls, 41) {
+			assert.Equal(t, 1<<40, calls[0].Calls)
+			assert.Equal(t, 1, calls[40].Calls, "М0 вызывает М40 рекурсивно")
+		}
+	})
+	t.Run("context", func(t *testing.T) {
+		assert.Equal(t, ContextClient, ContextOf(a.ModuleStatement.Body[0].(*ast.FunctionOrProcedure)))
+		assert.Equal(t, ContextClientAtServer, ContextOf(a.ModuleStatement.Body[5].(*ast.FunctionOrProcedure)))
+		assert.Equal(t, ContextUnknown, ContextOf(a.ModuleStatement.Body[6].(*ast.FunctionOrProcedure)))
+		assert.Equal(t, "&НаСервереБезКонтекста", ContextServerNoContext.String())
+	})
+}
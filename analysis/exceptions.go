@@ -0,0 +1,160 @@
+package analysis
+
+import (
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const (
+	CodeSwallowedException  = "SwallowedException"
+	CodeRethrowOutsideCatch = "RethrowOutsideCatch"
+)
+
+// CatchKind что блок Исключение делает с исключением
+type CatchKind int
+
+const (
+	CatchSwallowing CatchKind = iota // пустой блок или только присваивания: исключение теряется
+	CatchLogging                     // ЗаписьЖурналаРегистрации
+	CatchRethrowing                  // ВызватьИсключение
+	CatchHandling                    // ИнформацияОбОшибке(), ОписаниеОшибки() или другие действия
+)
+
+func (k CatchKind) String() string {
+	switch k {
+	case CatchSwallowing:
+		return "swallowing"
+	case CatchLogging:
+		return "logging"
+	case CatchRethrowing:
+		return "rethrowing"
+	case CatchHandling:
+		return "handling"
+	default:
+		return ""
+	}
+}
+
+// CatchBlock блок Исключение и его вид
+type CatchBlock struct {
+	Try    ast.TryStatement
+	Method *ast.FunctionOrProcedure // nil для операторов модуля
+	Kind   CatchKind
+}
+
+// ClassifyCatches находит все блоки Исключение модуля и определяет их вид. Если блок подходит под несколько
+// видов, выбирается первый из: повторный вызов исключения, запись в журнал регистрации, обработка
+func ClassifyCatches(module *ast.ModuleStatement) []CatchBlock {
+	var result []CatchBlock
+	walkExceptions(module, func(pf *ast.FunctionOrProcedure, try ast.TryStatement) {
+		result = append(result, CatchBlock{Try: try, Method: pf, Kind: classifyCatch(try.Catch)})
+	}, nil)
+
+	return result
+}
+
+// ExceptionHandlers проверяет обработку исключений:
+//   - SwallowedException - блок Исключение пустой или только присваивает переменные, не записывая ошибку
+//     в журнал регистрации, не вызывая исключение повторно и не используя ИнформацияОбОшибке();
+//   - RethrowOutsideCatch - ВызватьИсключение без параметров вне блока Исключение. Парсер проверяет это
+//     при разборе, но дерево может быть собрано программно или прочитано из JSON
+func ExceptionHandlers(module *ast.ModuleStatement) []Diagnostic {
+	var result []Diagnostic
+	walkExceptions(module, func(_ *ast.FunctionOrProcedure, try ast.TryStatement) {
+		if classifyCatch(try.Catch) != CatchSwallowing {
+			return
+		}
+
+		message := "exception is swallowed: Исключение block is empty"
+		if len(try.Catch) > 0 {
+			message = "exception is swallowed: Исключение block only assigns variables"
+		}
+		result = append(result, Diagnostic{
+			Code:     CodeSwallowedException,
+			Severity: SeverityWarning,
+			Message:  message,
+			Range:    ast.RangeOf(try),
+		})
+	}, func(throw ast.ThrowStatement) {
+		result = append(result, Diagnostic{
+			Code:     CodeRethrowOutsideCatch,
+			Severity: SeverityError,
+			Message:  "ВызватьИсключение without arguments can only be used in Исключение block",
+			Range:    ast.RangeOf(throw),
+		})
+	})
+
+	SortDiagnostics(result)
+	return result
+}
+
+// walkExceptions вызывает try для каждой Попытки модуля и rethrow для каждого ВызватьИсключение без параметров вне Исключение
+func walkExceptions(module *ast.ModuleStatement, try func(*ast.FunctionOrProcedure, ast.TryStatement), rethrow func(ast.ThrowStatement)) {
+	var pf *ast.FunctionOrProcedure
+	var walk func(stm ast.Statement, inCatch bool)
+	walk = func(stm ast.Statement, inCatch bool) {
+		inspect(stm, func(item ast.Statement) bool {
+			switch v := item.(type) {
+			case *ast.FunctionOrProcedure:
+				pf = v
+			case ast.TryStatement:
+				try(pf, v)
+				walk(v.Body, inCatch)
+				walk(v.Catch, true)
+				return false
+			case ast.ThrowStatement:
+				if v.Param == nil && !inCatch && rethrow != nil {
+					rethrow(v)
+				}
+			}
+			return true
+		})
+	}
+
+	for _, item := range module.Body {
+		pf = nil
+		walk(item, false)
+	}
+}
+
+func classifyCatch(catch ast.Statements) CatchKind {
+	var rethrows, logs, handles bool
+	// caught - оператор внутри тела вложенной Попытки, исключение из него ловит ее блок Исключение
+	var scan func(stm ast.Statement, caught bool)
+	scan = func(stm ast.Statement, caught bool) {
+		inspect(stm, func(item ast.Statement) bool {
+			switch v := item.(type) {
+			case ast.TryStatement:
+				scan(v.Body, true)
+				scan(v.Catch, caught)
+				return false
+			case ast.ThrowStatement:
+				rethrows = rethrows || !caught
+			case ast.MethodStatement:
+				switch ast.NormalizeName(v.Name) {
+				case "записьжурналарегистрации", "writelogevent":
+					logs = true
+				case "информацияобошибке", "errorinfo", "описаниеошибки", "errordescription":
+					handles = true
+				}
+			}
+			return true
+		})
+	}
+	scan(catch, false)
+
+	switch {
+	case rethrows:
+		return CatchRethrowing
+	case logs:
+		return CatchLogging
+	case handles:
+		return CatchHandling
+	}
+
+	for _, item := range catch {
+		if _, ok := item.(ast.AssignmentStatement); !ok {
+			return CatchHandling
+		}
+	}
+	return CatchSwallowing
+}
@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExceptionHandlers(t *testing.T) {
+	code := `Процедура А()
+	Попытка
+		Записать();
+	Исключение
+	КонецПопытки;
+
+	Попытка
+		Записать();
+	Исключение
+		Успех = Ложь;
+	КонецПопытки;
+
+	Попытка
+		Записать();
+	Исключение
+		ЗаписьЖурналаРегистрации("Запись", УровеньЖурналаРегистрации.Ошибка,,, ПодробноеПредставлениеОшибки(ИнформацияОбОшибке()));
+	КонецПопытки;
+
+	Попытка
+		Записать();
+	Исключение
+		Попытка
+			ВызватьИсключение "вложенное";
+		Исключение
+			Текст = ОписаниеОшибки();
+		КонецПопытки;
+		ВызватьИсключение;
+	КонецПопытки;
+КонецПроцедуры
+
+Функция Б()
+	Попытка
+		Возврат Записать();
+	Исключение
+		Ошибка = ИнформацияОбОшибке();
+		Сообщить(Ошибка.Описание);
+	КонецПопытки;
+КонецФункции
+
+Попытка
+	Записать();
+Исключение
+	Попытка
+		ВызватьИсключение "вложенное";
+	Исключение
+		Успех = Ложь;
+	КонецПопытки;
+КонецПопытки;`
+
+	a := ast.NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	var actual []string
+	for _, c := range ClassifyCatches(&a.ModuleStatement) {
+		method := ""
+		if c.Method != nil {
+			method = c.Method.Name
+		}
+		actual = append(actual, fmt.Sprintf("%d %s %s", ast.PositionOf(c.Try).Line, method, c.Kind))
+	}
+	assert.Equal(t, []string{
+		"2 А swallowing",
+		"7 А swallowing",
+		"13 А logging",
+		"19 А rethrowing",
+		"22 А handling",
+		"32 Б handling",
+		"40  handling",
+		"43  swallowing",
+	}, actual)
+
+	actual = nil
+	for _, d := range ExceptionHandlers(&a.ModuleStatement) {
+		actual = append(actual, fmt.Sprintf("%d %s: %s", d.Range.Start.Line, d.Code, d.Message))
+	}
+	assert.Equal(t, []string{
+		"2 SwallowedException: exception is swallowed: Исключение block is empty",
+		"7 SwallowedException: exception is swallowed: Исключение block only assigns variables",
+		"43 SwallowedException: exception is swallowed: Исключение block only assigns variables",
+	}, actual)
+
+	t.Run("rethrow outside catch", func(t *testing.T) {
+		// парсер такой код не пропускает, дерево собирается программно
+		module := &ast.ModuleStatement{Body: ast.Statements{
+			ast.TryStatement{
+				Body:  ast.Statements{ast.ThrowStatement{Pos: ast.Position{Line: 2, Column: 2, Offset: 10}}},
+				Catch: ast.Statements{ast.ThrowStatement{Pos: ast.Position{Line: 4, Column: 2, Offset: 40}}},
+			},
+		}}
+
+		diagnostics := ExceptionHandlers(module)
+		if assert.Len(t, diagnostics, 1) {
+			assert.Equal(t, CodeRethrowOutsideCatch, diagnostics[0].Code)
+			assert.Equal(t, 2, diagnostics[0].Range.Start.Line)
+		}
+	})
+}
@@ -0,0 +1,286 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const CodeCodeInjection = "CodeInjection"
+
+// TaintSource откуда в аргумент Выполнить или Вычислить попадает не константная строка. Значения объединяются по ИЛИ
+type TaintSource uint
+
+const (
+	TaintParameter      TaintSource = 1 << iota // параметр метода
+	TaintFormAttribute                          // реквизит формы или объекта: Объект.Реквизит, необъявленное в модуле имя
+	TaintModuleVariable                         // переменная модуля
+	TaintConcatenation                          // сложение строк, хотя бы одна из которых не константа
+	TaintCall                                   // результат вызова функции или Новый
+	TaintUnknown                                // свойство глобального контекста или выражение, которое не удалось разобрать
+)
+
+var taintNames = []string{"parameter", "form attribute", "module variable", "string concatenation", "function result", "unknown value"}
+
+func (s TaintSource) String() string {
+	var names []string
+	for i, name := range taintNames {
+		if s&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// свойства глобального контекста, через которые в модулях форм и объектов обращаются к реквизитам
+var formObjects = []string{"ЭтотОбъект", "ЭтаФорма", "Элементы", "ThisObject", "ThisForm", "Items"}
+
+// Injection вызов Выполнить или Вычислить, аргумент которого не константная строка
+type Injection struct {
+	Call    ast.MethodStatement
+	Method  *ast.FunctionOrProcedure // nil для операторов модуля
+	Sources TaintSource
+	// Origins имена параметров, реквизитов и переменных модуля, из которых получен аргумент
+	Origins []string
+}
+
+// FindInjections находит вызовы Выполнить и Вычислить с аргументом, который не является константной строкой, и
+// прослеживает в пределах метода, откуда получен аргумент. Локальные переменные прослеживаются по всем
+// присваиваниям в методе без учета порядка, результат функции наследует источники ее аргументов (СтрШаблон и т.п.)
+func FindInjections(module *ast.ModuleStatement) []Injection {
+	res := ast.Resolve(module)
+
+	var result []Injection
+	check := func(pf *ast.FunctionOrProcedure, body ast.Statements) {
+		t := &taint{res: res, assignments: map[string][]ast.Statement{}, visiting: map[string]bool{}}
+		t.collect(body)
+
+		dynamicCalls(body, func(call ast.MethodStatement) {
+			t.origins = nil
+			var sources TaintSource
+			for _, arg := range call.Param.Statements {
+				sources |= t.sources(arg)
+			}
+			if sources != 0 {
+				result = append(result, Injection{Call: call, Method: pf, Sources: sources, Origins: t.origins})
+			}
+		})
+	}
+
+	var body ast.Statements
+	for _, item := range module.Body {
+		if pf, ok := item.(*ast.FunctionOrProcedure); ok {
+			check(pf, pf.Body)
+		} else {
+			body = append(body, item)
+		}
+	}
+	check(nil, body)
+
+	return result
+}
+
+// CodeInjections сообщает о вызовах Выполнить и Вычислить с не константным аргументом. Если аргумент получен
+// из параметра или реквизита формы, то есть может прийти снаружи, важность - ошибка
+func CodeInjections(module *ast.ModuleStatement) []Diagnostic {
+	var result []Diagnostic
+	for _, inj := range FindInjections(module) {
+		d := Diagnostic{
+			Code:     CodeCodeInjection,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("%s argument is not a constant string: %s", inj.Call.Name, inj.Sources),
+			Range:    ast.RangeOf(inj.Call),
+		}
+		if inj.Sources&(TaintParameter|TaintFormAttribute) != 0 {
+			d.Severity = SeverityError
+		}
+		if len(inj.Origins) > 0 {
+			d.Message += fmt.Sprintf(" (%s)", strings.Join(inj.Origins, ", "))
+		}
+		result = append(result, d)
+	}
+
+	SortDiagnostics(result)
+	return result
+}
+
+// dynamicCalls вызывает f для каждого вызова Выполнить и Вычислить в body
+func dynamicCalls(body ast.Statements, f func(ast.MethodStatement)) {
+	var visit func(stm ast.Statement)
+	visit = func(stm ast.Statement) {
+		inspect(stm, func(item ast.Statement) bool {
+			switch v := item.(type) {
+			case ast.CallChainStatement:
+				// Запрос.Выполнить() - метод объекта
+				if method, ok := v.Unit.(ast.MethodStatement); ok {
+					visit(method.Param)
+				} else {
+					visit(v.Unit)
+				}
+				visit(v.Call)
+				return false
+			case ast.MethodStatement:
+				if isDynamicCode(v.Name) {
+					f(v)
+				}
+			}
+			return true
+		})
+	}
+
+	visit(body)
+}
+
+func isDynamicCode(name string) bool {
+	switch ast.NormalizeName(name) {
+	case "выполнить", "execute", "вычислить", "eval":
+		return true
+	default:
+		return false
+	}
+}
+
+type taint struct {
+	res         *ast.Resolution
+	assignments map[string][]ast.Statement // выражения, которые присваиваются локальным переменным, по NormalizeName
+	visiting    map[string]bool
+	origins     []string
+}
+
+// collect запоминает присваивания локальным переменным и переменным цикла Для Каждого
+func (t *taint) collect(body ast.Statements) {
+	inspect(body, func(item ast.Statement) bool {
+		switch v := item.(type) {
+		case ast.AssignmentStatement:
+			if target, ok := v.Var.(ast.VarStatement); ok {
+				key := ast.NormalizeName(target.Name)
+				t.assignments[key] = append(t.assignments[key], v.Expr)
+			}
+		case *ast.LoopStatement:
+			if name, ok := v.For.(string); ok {
+				key := ast.NormalizeName(name)
+				t.assignments[key] = append(t.assignments[key], v.In)
+			}
+		case *ast.FunctionOrProcedure:
+			return false
+		}
+		return true
+	})
+}
+
+func (t *taint) origin(name string) {
+	for _, item := range t.origins {
+		if item == name {
+			return
+		}
+	}
+	t.origins = append(t.origins, name)
+}
+
+// sources возвращает источники значения выражения, 0 - выражение константное
+func (t *taint) sources(stm ast.Statement) TaintSource {
+	switch v := stm.(type) {
+	case nil, string, bool, float64, time.Time, ast.UndefinedStatement:
+		return 0
+	case ast.ExprStatements:
+		var result TaintSource
+		for _, item := range v.Statements {
+			result |= t.sources(item)
+		}
+		return result
+	case *ast.ExpStatement:
+		result := t.sources(v.Left) | t.sources(v.Right)
+		if result != 0 && v.Operation == ast.OpPlus {
+			result |= TaintConcatenation
+		}
+		return result
+	case ast.TernaryStatement:
+		return t.sources(v.TrueBlock) | t.sources(v.ElseBlock)
+	case ast.VarStatement:
+		return t.variable(v)
+	case ast.ItemStatement:
+		return t.sources(v.Object)
+	case ast.CallChainStatement:
+		root := v.Call
+		for {
+			chain, ok := root.(ast.CallChainStatement)
+			if !ok {
+				break
+			}
+			root = chain.Call
+		}
+
+		if _, ok := v.Unit.(ast.MethodStatement); ok {
+			return TaintCall | t.sources(v.Unit)
+		}
+		// Объект.Реквизит, ЭтаФорма.Реквизит: корень цепочки не объявлен в модуле
+		if name, ok := root.(ast.VarStatement); ok && t.formObject(name) {
+			t.origin(strings.Join(chainPath(v), "."))
+			return TaintFormAttribute
+		}
+		return t.sources(root)
+	case ast.MethodStatement:
+		// результат функции содержит ее аргументы: СтрШаблон, СтрЗаменить, Формат...
+		return TaintCall | t.sources(v.Param)
+	case ast.NewObjectStatement:
+		return TaintCall
+	default:
+		return TaintUnknown
+	}
+}
+
+func (t *taint) formObject(v ast.VarStatement) bool {
+	sym := t.res.SymbolOf(v)
+	if sym == nil || sym.Kind == ast.SymbolExternal {
+		return true
+	}
+	if sym.Kind != ast.SymbolGlobal {
+		return false
+	}
+
+	for _, name := range formObjects {
+		if ast.NormalizeName(name) == ast.NormalizeName(v.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *taint) variable(v ast.VarStatement) TaintSource {
+	sym := t.res.SymbolOf(v)
+	if sym == nil {
+		return TaintUnknown
+	}
+
+	switch sym.Kind {
+	case ast.SymbolParam:
+		t.origin(sym.Name)
+		return TaintParameter
+	case ast.SymbolModuleVar:
+		t.origin(sym.Name)
+		return TaintModuleVariable
+	case ast.SymbolExternal:
+		t.origin(sym.Name)
+		return TaintFormAttribute
+	case ast.SymbolGlobal:
+		return TaintUnknown
+	}
+
+	key := ast.NormalizeName(v.Name)
+	if t.visiting[key] {
+		return 0
+	}
+	t.visiting[key] = true
+	defer delete(t.visiting, key)
+
+	var result TaintSource
+	for _, expr := range t.assignments[key] {
+		result |= t.sources(expr)
+	}
+
+	return result
+}
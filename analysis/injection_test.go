@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeInjections(t *testing.T) {
+	code := `Перем Алгоритмы;
+
+&НаСервере
+Процедура Выполнение(Алгоритм, Параметры)
+	Выполнить("Сообщить(1)");
+	Выполнить "Сообщить(" + "2)";
+	Выполнить Алгоритм;
+
+	Текст = "Результат = " + Параметры.Выражение;
+	Если Истина Тогда
+		Текст = Текст + ";";
+	КонецЕсли;
+	Выполнить(Текст);
+
+	Результат = Вычислить(Объект.Формула);
+	Результат = Вычислить(СтрШаблон("%1 + 1", ЭтаФорма.Число));
+	Результат = Вычислить(Алгоритмы[0]);
+	Результат = Вычислить(ОбщийМодуль.Формула());
+	Для Каждого Строка Из Объект.Строки Цикл
+		Выполнить(Строка.Код);
+	КонецЦикла;
+	Запрос.Выполнить();
+КонецПроцедуры
+
+Выполнить(Формула);`
+
+	a := ast.NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	var actual []string
+	for _, d := range CodeInjections(&a.ModuleStatement) {
+		assert.Equal(t, CodeCodeInjection, d.Code)
+		actual = append(actual, fmt.Sprintf("%d %s: %s", d.Range.Start.Line, d.Severity, d.Message))
+	}
+
+	assert.Equal(t, []string{
+		`7 error: Выполнить argument is not a constant string: parameter (Алгоритм)`,
+		`13 error: Выполнить argument is not a constant string: parameter, string concatenation (Параметры)`,
+		`15 error: Вычислить argument is not a constant string: form attribute (Объект.Формула)`,
+		`16 error: Вычислить argument is not a constant string: form attribute, function result (ЭтаФорма.Число)`,
+		`17 warning: Вычислить argument is not a constant string: module variable (Алгоритмы)`,
+		`18 warning: Вычислить argument is not a constant string: function result`,
+		`20 error: Выполнить argument is not a constant string: form attribute (Объект.Строки)`,
+		`25 error: Выполнить argument is not a constant string: form attribute (Формула)`,
+	}, actual)
+
+	injections := FindInjections(&a.ModuleStatement)
+	if assert.Len(t, injections, 8) {
+		assert.Equal(t, "Выполнение", injections[0].Method.Name)
+		assert.Nil(t, injections[7].Method)
+		assert.Equal(t, TaintParameter|TaintConcatenation, injections[1].Sources)
+	}
+}
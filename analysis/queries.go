@@ -0,0 +1,236 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const CodeQueryInLoop = "QueryInLoop"
+
+// DefaultQueryMethods методы, которые обращаются к базе данных. Имя без точки - метод любого объекта
+// (Ссылка.ПолучитьОбъект()), с точками - окончание цепочки вызова, * заменяет одно имя. Объект Запрос в шаблоне -
+// переменная с именем Запрос (Query) или переменная, которой в модуле присвоен Новый Запрос
+var DefaultQueryMethods = []string{
+	"Запрос.Выполнить", "Запрос.ВыполнитьПакет", "Запрос.ВыполнитьПакетСПромежуточнымиДанными", "ПолучитьОбъект",
+	"Справочники.*.НайтиПоКоду", "Справочники.*.НайтиПоНаименованию", "Справочники.*.НайтиПоРеквизиту",
+	"ПланыВидовХарактеристик.*.НайтиПоКоду", "ПланыВидовХарактеристик.*.НайтиПоНаименованию",
+	"ПланыСчетов.*.НайтиПоКоду", "ПланыСчетов.*.НайтиПоНаименованию",
+	"Документы.*.НайтиПоНомеру", "Документы.*.НайтиПоРеквизиту",
+	"РегистрыСведений.*.Получить", "РегистрыСведений.*.ПолучитьПервое", "РегистрыСведений.*.ПолучитьПоследнее",
+	"РегистрыНакопления.*.Остатки", "РегистрыНакопления.*.Обороты",
+	"ОбщегоНазначения.ЗначениеРеквизитаОбъекта", "ОбщегоНазначения.ЗначенияРеквизитовОбъекта",
+	"ОбщегоНазначения.ЗначениеРеквизитаОбъектов", "ОбщегоНазначения.ЗначенияРеквизитовОбъектов",
+}
+
+// QueryConf настройки проверки запросов в цикле
+type QueryConf struct {
+	// Methods методы, которые обращаются к базе данных, в формате DefaultQueryMethods. Если не задан,
+	// используется DefaultQueryMethods
+	Methods []string
+}
+
+// queryCall найденный вызов метода, который обращается к базе данных
+type queryCall struct {
+	Name  string // цепочка вызова, например Справочники.Товары.НайтиПоКоду
+	Range ast.Range
+}
+
+// QueriesInLoops находит обращения к базе данных в теле циклов Для, Для Каждого и Пока и в условии Пока:
+// вызовы методов из conf.Methods и вызовы методов модуля, в которых такие вызовы есть (на один уровень вложенности)
+func QueriesInLoops(module *ast.ModuleStatement, conf QueryConf) []Diagnostic {
+	patterns := conf.Methods
+	if patterns == nil {
+		patterns = DefaultQueryMethods
+	}
+
+	q := &queryCheck{
+		local:   map[string]*ast.FunctionOrProcedure{},
+		direct:  map[string][]queryCall{},
+		res:     ast.Resolve(module),
+		queries: map[*ast.Symbol]bool{},
+	}
+	for _, pattern := range patterns {
+		q.patterns = append(q.patterns, strings.Split(ast.NormalizeName(pattern), "."))
+	}
+	for _, pf := range methods(module) {
+		q.local[ast.NormalizeName(pf.Name)] = pf
+	}
+	inspect(module.Body, func(stm ast.Statement) bool {
+		if v, ok := stm.(ast.AssignmentStatement); ok && isNewQuery(v.Expr) {
+			if target, ok := v.Var.(ast.VarStatement); ok {
+				if sym := q.res.SymbolOf(target); sym != nil {
+					q.queries[sym] = true
+				}
+			}
+		}
+		return true
+	})
+
+	var result []Diagnostic
+	inspect(module.Body, func(stm ast.Statement) bool {
+		loop, ok := stm.(*ast.LoopStatement)
+		if !ok {
+			return true
+		}
+
+		// вложенные циклы проверяются вместе с внешним
+		result = append(result, q.check(ast.Statements{loop.WhileExpr, loop.Body})...)
+		return false
+	})
+
+	SortDiagnostics(result)
+	return result
+}
+
+type queryCheck struct {
+	patterns [][]string
+	local    map[string]*ast.FunctionOrProcedure
+	direct   map[string][]queryCall // найденные в методах модуля вызовы, по NormalizeName метода
+	res      *ast.Resolution
+	queries  map[*ast.Symbol]bool // переменные, которым присваивается Новый Запрос
+}
+
+func (q *queryCheck) check(stm ast.Statement) []Diagnostic {
+	var result []Diagnostic
+	q.walk(stm, func(call queryCall) {
+		result = append(result, Diagnostic{
+			Code:     CodeQueryInLoop,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("database query %s inside a loop", call.Name),
+			Range:    call.Range,
+		})
+	}, func(method ast.MethodStatement) {
+		pf, ok := q.local[ast.NormalizeName(method.Name)]
+		if !ok {
+			return
+		}
+
+		calls := q.methodCalls(pf)
+		if len(calls) > 0 {
+			result = append(result, Diagnostic{
+				Code:     CodeQueryInLoop,
+				Severity: SeverityWarning,
+				Message: fmt.Sprintf("method %q called inside a loop executes database query %s (line %d)",
+					pf.Name, calls[0].Name, calls[0].Range.Start.Line),
+				Range: ast.RangeOf(method),
+			})
+		}
+	})
+
+	return result
+}
+
+// methodCalls вызовы, обращающиеся к базе данных, прямо в теле метода модуля
+func (q *queryCheck) methodCalls(pf *ast.FunctionOrProcedure) []queryCall {
+	key := ast.NormalizeName(pf.Name)
+	if calls, ok := q.direct[key]; ok {
+		return calls
+	}
+
+	calls := []queryCall{}
+	q.walk(pf.Body, func(call queryCall) { calls = append(calls, call) }, func(ast.MethodStatement) {})
+	q.direct[key] = calls
+	return calls
+}
+
+// walk обходит stm и вызывает query для вызовов, обращающихся к базе данных, и local для вызовов
+// без объекта (Метод()), которые могут быть методами модуля
+func (q *queryCheck) walk(stm ast.Statement, query func(queryCall), local func(ast.MethodStatement)) {
+	inspect(stm, func(item ast.Statement) bool {
+		switch v := item.(type) {
+		case ast.CallChainStatement:
+			if method, ok := v.Unit.(ast.MethodStatement); ok {
+				path := append(chainPath(v.Call), method.Name)
+				if q.match(path) || (q.isQuery(v.Call) && q.match([]string{"Запрос", method.Name})) {
+					query(queryCall{Name: strings.Join(path, "."), Range: ast.RangeOf(method)})
+				}
+				q.walk(method.Param, query, local)
+			} else {
+				q.walk(v.Unit, query, local)
+			}
+			// имя метода после точки к методам модуля не относится
+			q.walk(v.Call, query, local)
+			return false
+		case ast.MethodStatement:
+			local(v)
+		}
+		return true
+	})
+}
+
+// isQuery проверяет, что объект вызова - переменная Query или переменная, которой присвоен Новый Запрос
+func (q *queryCheck) isQuery(stm ast.Statement) bool {
+	v, ok := stm.(ast.VarStatement)
+	if !ok {
+		return false
+	}
+	if ast.NormalizeName(v.Name) == "query" {
+		return true
+	}
+
+	sym := q.res.SymbolOf(v)
+	return sym != nil && q.queries[sym]
+}
+
+// match сравнивает окончание цепочки вызова с шаблонами. Шаблон из одного имени подходит к методу любого объекта
+func (q *queryCheck) match(path []string) bool {
+	for _, pattern := range q.patterns {
+		if len(pattern) > len(path) {
+			continue
+		}
+
+		ok := true
+		tail := path[len(path)-len(pattern):]
+		for i, name := range pattern {
+			if name != "*" && name != ast.NormalizeName(tail[i]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNewQuery проверяет, что выражение создает запрос: Новый Запрос, Новый Запрос(Текст) или Новый("Запрос")
+func isNewQuery(expr ast.Statement) bool {
+	if e, ok := expr.(ast.ExprStatements); ok && len(e.Statements) == 1 {
+		expr = e.Statements[0]
+	}
+
+	v, ok := expr.(ast.NewObjectStatement)
+	if !ok {
+		return false
+	}
+
+	name := v.Constructor
+	if name == "" && len(v.Param.Statements) > 0 {
+		name, _ = v.Param.Statements[0].(string)
+	}
+
+	switch ast.NormalizeName(name) {
+	case "запрос", "query":
+		return true
+	default:
+		return false
+	}
+}
+
+// chainPath имена цепочки Объект.Свойство.Метод(). Элемент, у которого нет имени (индекс, выражение), заменяется на *
+func chainPath(stm ast.Statement) []string {
+	switch v := stm.(type) {
+	case ast.CallChainStatement:
+		return append(chainPath(v.Call), chainPath(v.Unit)...)
+	case ast.VarStatement:
+		return []string{v.Name}
+	case ast.MethodStatement:
+		return []string{v.Name}
+	default:
+		return []string{"*"}
+	}
+}
@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueriesInLoops(t *testing.T) {
+	code := `Функция Цена(Товар)
+	Возврат ОбщегоНазначения.ЗначениеРеквизитаОбъекта(Товар, "Цена");
+КонецФункции
+
+Функция Сумма(а, б)
+	Возврат а + б;
+КонецФункции
+
+Процедура Заполнить(Таблица)
+	Для Каждого Стр Из Запрос.Выполнить().Выгрузить() Цикл
+		Стр.Товар = Справочники.Товары.НайтиПоКоду(Стр.Код).ПолучитьОбъект();
+		Стр.Сумма = Сумма(Цена(Стр.Товар), 1);
+		Для Сч = 1 По 10 Цикл
+			Результат = Запрос.Выполнить();
+		КонецЦикла;
+		Объект.Цена(Стр.Товар);
+	КонецЦикла;
+
+	Пока РегистрыСведений.Курсы.ПолучитьПоследнее(Дата).Курс > 0 Цикл
+		Прервать;
+	КонецЦикла;
+
+	Пока Истина Цикл
+		Запись = Таблица[0].ПолучитьОбъект();
+	КонецЦикла;
+КонецПроцедуры
+
+Процедура Обработать(Задачи)
+	Поиск = Новый Запрос(ТекстЗапроса());
+	Для Каждого Задача Из Задачи Цикл
+		Задача.Выполнить();
+		Выборка = Поиск.Выполнить().Выбрать();
+	КонецЦикла;
+КонецПроцедуры
+
+Для Каждого Ссылка Из Ссылки Цикл
+	Сообщить(Цена(Ссылка));
+КонецЦикла;`
+
+	a := ast.NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	format := func(diagnostics []Diagnostic) []string {
+		var result []string
+		for _, d := range diagnostics {
+			assert.Equal(t, CodeQueryInLoop, d.Code)
+			result = append(result, fmt.Sprintf("%d:%d %s", d.Range.Start.Line, d.Range.Start.Column, d.Message))
+		}
+		return result
+	}
+
+	t.Run("default", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"11:34 database query Справочники.Товары.НайтиПоКоду inside a loop",
+			"11:55 database query Справочники.Товары.НайтиПоКоду.ПолучитьОбъект inside a loop",
+			`12:21 method "Цена" called inside a loop executes database query ОбщегоНазначения.ЗначениеРеквизитаОбъекта (line 2)`,
+			"14:23 database query Запрос.Выполнить inside a loop",
+			"19:30 database query РегистрыСведений.Курсы.ПолучитьПоследнее inside a loop",
+			"24:23 database query *.ПолучитьОбъект inside a loop",
+			"32:19 database query Поиск.Выполнить inside a loop",
+			`37:11 method "Цена" called inside a loop executes database query ОбщегоНазначения.ЗначениеРеквизитаОбъекта (line 2)`,
+		}, format(QueriesInLoops(&a.ModuleStatement, QueryConf{})))
+	})
+	t.Run("custom", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"14:23 database query Запрос.Выполнить inside a loop",
+			"32:19 database query Поиск.Выполнить inside a loop",
+		}, format(QueriesInLoops(&a.ModuleStatement, QueryConf{Methods: []string{"запрос.выполнить"}})))
+		// метод без объекта Запрос подходит к любому объекту
+		assert.Len(t, QueriesInLoops(&a.ModuleStatement, QueryConf{Methods: []string{"Выполнить"}}), 3)
+	})
+}
@@ -0,0 +1,188 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const (
+	CodeMissingReturn         = "MissingReturn"
+	CodeInconsistentReturn    = "InconsistentReturn"
+	CodeIgnoredFunctionResult = "IgnoredFunctionResult"
+)
+
+// Returns проверяет возвраты функций модуля:
+//   - MissingReturn - выполнение функции может дойти до КонецФункции без Возврат со значением;
+//   - InconsistentReturn - в функции есть и Возврат со значением, и Возврат без значения;
+//   - IgnoredFunctionResult - результат неэкспортной функции не используется ни в одном вызове в модуле,
+//     скорее всего она должна быть процедурой. Экспортные функции могут вызываться из других модулей, их не проверяем
+func Returns(module *ast.ModuleStatement) []Diagnostic {
+	var result []Diagnostic
+	for _, pf := range methods(module) {
+		if pf.Type != ast.PFTypeFunction {
+			continue
+		}
+
+		result = append(result, functionReturns(pf)...)
+	}
+
+	calls := moduleCalls(module)
+	for _, pf := range methods(module) {
+		if pf.Type != ast.PFTypeFunction || pf.Export {
+			continue
+		}
+
+		used, ok := calls[ast.NormalizeName(pf.Name)]
+		if ok && !used {
+			result = append(result, Diagnostic{
+				Code:     CodeIgnoredFunctionResult,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("result of function %q is never used, consider making it a procedure", pf.Name),
+				Range:    ast.RangeOf(pf),
+			})
+		}
+	}
+
+	SortDiagnostics(result)
+	return result
+}
+
+func functionReturns(pf *ast.FunctionOrProcedure) []Diagnostic {
+	var result []Diagnostic
+	g := ast.BuildCFG(pf)
+
+	var withValue, withoutValue []*ast.ReturnStatement
+	for _, block := range g.Blocks {
+		if ret, ok := block.Control.(*ast.ReturnStatement); ok {
+			if ret.Param == nil {
+				withoutValue = append(withoutValue, ret)
+			} else {
+				withValue = append(withValue, ret)
+			}
+		}
+	}
+
+	if len(withValue) > 0 {
+		for _, ret := range withoutValue {
+			result = append(result, Diagnostic{
+				Code:     CodeInconsistentReturn,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("function %q returns a value on other paths, but not here", pf.Name),
+				Range:    ast.RangeOf(ret),
+			})
+		}
+	}
+
+	// до конца тела доходит блок без Возврат. Пока Истина Цикл без Прервать из функции не выходит
+	reachable := reachableBlocks(g, func(block *ast.BasicBlock, e ast.Edge) bool {
+		loop, ok := block.Control.(*ast.LoopStatement)
+		return ok && e.Kind == ast.EdgeFalse && loop.WhileExpr == true
+	})
+	for _, pred := range g.Exit.Preds {
+		if !reachable[pred] {
+			continue
+		}
+
+		for _, e := range pred.Succs {
+			if e.To == g.Exit && e.Kind == ast.EdgeNormal {
+				result = append(result, Diagnostic{
+					Code:     CodeMissingReturn,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("not all paths of function %q return a value", pf.Name),
+					Range:    ast.RangeOf(pf),
+				})
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// reachableBlocks возвращает блоки, достижимые из начала метода, не проходя по переходам, для которых skip вернет true
+func reachableBlocks(g *ast.CFG, skip func(*ast.BasicBlock, ast.Edge) bool) map[*ast.BasicBlock]bool {
+	result := map[*ast.BasicBlock]bool{}
+	stack := []*ast.BasicBlock{g.Entry}
+	for len(stack) > 0 {
+		block := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if result[block] {
+			continue
+		}
+
+		result[block] = true
+		for _, e := range block.Succs {
+			if skip == nil || !skip(block, e) {
+				stack = append(stack, e.To)
+			}
+		}
+	}
+
+	return result
+}
+
+// moduleCalls собирает вызовы методов модуля по NormalizeName: true - результат хотя бы одного вызова используется,
+// false - метод вызывается только отдельным оператором. Вызовы методов других объектов (Объект.Метод()) не учитываются
+func moduleCalls(module *ast.ModuleStatement) map[string]bool {
+	result := map[string]bool{}
+
+	var visit func(stm ast.Statement, statement bool)
+	visitAll := func(items ast.Statements, statement bool) {
+		for _, item := range items {
+			visit(item, statement)
+		}
+	}
+
+	visit = func(stm ast.Statement, statement bool) {
+		if call, ok := stm.(ast.MethodStatement); ok && statement {
+			key := ast.NormalizeName(call.Name)
+			result[key] = result[key] || false
+			visitAll(call.Param.Statements, false)
+			return
+		}
+
+		inspect(stm, func(item ast.Statement) bool {
+			switch v := item.(type) {
+			case ast.MethodStatement:
+				result[ast.NormalizeName(v.Name)] = true
+			case ast.CallChainStatement:
+				// имя метода после точки к методам модуля не относится, проверяем только объект и параметры
+				visit(v.Call, false)
+				switch unit := v.Unit.(type) {
+				case ast.MethodStatement:
+					visitAll(unit.Param.Statements, false)
+				default:
+					visit(unit, false)
+				}
+				return false
+			case *ast.IfStatement:
+				visit(v.Expression, false)
+				visitAll(v.TrueBlock, true)
+				visitAll(v.IfElseBlock, true)
+				visitAll(v.ElseBlock, true)
+				return false
+			case *ast.LoopStatement:
+				if _, ok := v.For.(string); !ok {
+					visit(v.For, false)
+				}
+				visit(v.In, false)
+				visit(v.To, false)
+				visit(v.WhileExpr, false)
+				visitAll(v.Body, true)
+				return false
+			case ast.TryStatement:
+				visitAll(v.Body, true)
+				visitAll(v.Catch, true)
+				return false
+			case *ast.FunctionOrProcedure:
+				visitAll(v.Body, true)
+				return false
+			}
+			return true
+		})
+	}
+
+	visitAll(module.Body, true)
+	return result
+}
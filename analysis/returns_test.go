@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturns(t *testing.T) {
+	code := `Функция Полная(а)
+	Если а Тогда
+		Возврат 1;
+	Иначе
+		ВызватьИсключение "ошибка";
+	КонецЕсли;
+КонецФункции
+
+Функция БезВозврата(а)
+	Если а Тогда
+		Возврат 1;
+	КонецЕсли;
+КонецФункции
+
+Функция Смешанная(а)
+	Если а Тогда
+		Возврат;
+	КонецЕсли;
+	Возврат Полная(а);
+КонецФункции
+
+Функция Бесконечная()
+	Пока Истина Цикл
+		Возврат 1;
+	КонецЦикла;
+КонецФункции
+
+Функция Проверить(а)
+	Возврат а > 0;
+КонецФункции
+
+Функция Внешняя() Экспорт
+	Возврат 1;
+КонецФункции
+
+Процедура Вызовы()
+	Проверить(1);
+	БезВозврата(Смешанная(1));
+	Объект.Бесконечная();
+	Если Истина Тогда
+		Проверить(2);
+		Внешняя();
+	КонецЕсли;
+КонецПроцедуры`
+
+	a := ast.NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	var messages []string
+	var lines []int
+	for _, d := range Returns(&a.ModuleStatement) {
+		messages = append(messages, d.Code+": "+d.Message)
+		lines = append(lines, d.Range.Start.Line)
+	}
+
+	assert.Equal(t, []string{
+		`IgnoredFunctionResult: result of function "БезВозврата" is never used, consider making it a procedure`,
+		`MissingReturn: not all paths of function "БезВозврата" return a value`,
+		`InconsistentReturn: function "Смешанная" returns a value on other paths, but not here`,
+		`IgnoredFunctionResult: result of function "Проверить" is never used, consider making it a procedure`,
+	}, messages)
+	assert.Equal(t, []int{9, 9, 17, 28}, lines)
+}
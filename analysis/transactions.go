@@ -0,0 +1,356 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const (
+	CodeUnbalancedTransaction = "UnbalancedTransaction"
+	CodeNestedTransaction     = "NestedTransaction"
+	CodeTransactionPattern    = "TransactionPattern"
+	CodeTransactionInLoop     = "TransactionInLoop"
+)
+
+type transactionOp int
+
+const (
+	opNone transactionOp = iota
+	opBegin
+	opCommit
+	opRollback
+)
+
+// transactionCall определяет, является ли оператор вызовом НачатьТранзакцию, ЗафиксироватьТранзакцию или ОтменитьТранзакцию
+func transactionCall(stm ast.Statement) (ast.MethodStatement, transactionOp) {
+	call, ok := stm.(ast.MethodStatement)
+	if !ok {
+		return call, opNone
+	}
+
+	switch ast.NormalizeName(call.Name) {
+	case "начатьтранзакцию", "begintransaction":
+		return call, opBegin
+	case "зафиксироватьтранзакцию", "committransaction":
+		return call, opCommit
+	case "отменитьтранзакцию", "rollbacktransaction":
+		return call, opRollback
+	default:
+		return call, opNone
+	}
+}
+
+// Transactions проверяет работу с транзакциями в методах модуля по стандарту 1С:
+//
+//	НачатьТранзакцию();
+//	Попытка
+//		...
+//		ЗафиксироватьТранзакцию();
+//	Исключение
+//		ОтменитьТранзакцию();
+//		ВызватьИсключение;
+//	КонецПопытки;
+//
+// Диагностики:
+//   - UnbalancedTransaction - транзакция на каком-то пути (в том числе через Возврат) не завершается до выхода
+//     из метода, ЗафиксироватьТранзакцию или ОтменитьТранзакцию вызывается, когда транзакции может не быть.
+//     Ветки Если ТранзакцияАктивна() Тогда учитываются: в Тогда транзакция есть, в Иначе ее нет;
+//   - NestedTransaction - НачатьТранзакцию вызывается, когда транзакция уже может быть открыта;
+//   - TransactionPattern - после НачатьТранзакцию нет Попытки, в ней нет ЗафиксироватьТранзакцию, в Исключение
+//     нет ОтменитьТранзакцию, или ОтменитьТранзакцию вызывается не в Исключение;
+//   - TransactionInLoop - транзакция начинается в цикле.
+func Transactions(module *ast.ModuleStatement) []Diagnostic {
+	var result []Diagnostic
+	for _, pf := range methods(module) {
+		if !hasTransactions(pf) {
+			continue
+		}
+
+		t := &transactionCheck{g: ast.BuildCFG(pf), reported: map[diagnosticKey]bool{}}
+		t.balance()
+		for _, block := range t.g.Blocks {
+			for i, stm := range block.Statements {
+				if _, op := transactionCall(stm); op == opBegin {
+					t.begin(block, i)
+				}
+			}
+		}
+
+		result = append(result, t.result...)
+		result = append(result, transactionPattern(pf.Body, false, false)...)
+	}
+
+	SortDiagnostics(result)
+	return result
+}
+
+func hasTransactions(pf *ast.FunctionOrProcedure) bool {
+	found := false
+	inspect(pf.Body, func(stm ast.Statement) bool {
+		if _, op := transactionCall(stm); op != opNone {
+			found = true
+		}
+		return !found
+	})
+
+	return found
+}
+
+// transactionActive проверяет, что условие - вызов ТранзакцияАктивна() без Не
+func transactionActive(expr ast.Statement) bool {
+	call, ok := expr.(ast.MethodStatement)
+	if !ok {
+		return false
+	}
+
+	switch ast.NormalizeName(call.Name) {
+	case "транзакцияактивна", "transactionactive":
+		return !call.IsNot()
+	default:
+		return false
+	}
+}
+
+// depths множество возможных уровней вложенности транзакций: бит i - уровень i. Уровни выше maxDepth
+// считаются равными maxDepth, иначе в цикле с НачатьТранзакцию множество росло бы бесконечно
+type depths uint16
+
+const maxDepth = 15
+
+func (d depths) begin() depths {
+	return d<<1 | d&(1<<maxDepth)
+}
+
+func (d depths) end() depths {
+	return d>>1 | d&1
+}
+
+// transactionFlow распространяет уровни вложенности транзакций по графу потока управления. Исключение может
+// возникнуть в любом операторе блока, поэтому в Исключение попадают уровни перед каждым оператором блока
+type transactionFlow struct {
+	g *ast.CFG
+	// step применяет оператор к уровням перед ним, report - последний проход после вычисления всех уровней
+	step func(stm ast.Statement, d depths, report bool) depths
+	// exit вызывается на последнем проходе для переходов в конец метода с уровнями d
+	exit func(block *ast.BasicBlock, e ast.Edge, d depths)
+	// active уточняет уровни в ветках Если ТранзакцияАктивна() Тогда: true - транзакция есть, false - нет
+	active func(d depths, active bool) depths
+}
+
+// run начинает обход с оператора from блока start с уровнями init
+func (f *transactionFlow) run(start *ast.BasicBlock, from int, init depths) {
+	in := map[*ast.BasicBlock]depths{}
+	var work []*ast.BasicBlock
+	propagate := func(block *ast.BasicBlock, out, before depths, report bool) {
+		for _, e := range block.Succs {
+			d := out
+			if e.Kind == ast.EdgeException {
+				d = before
+			}
+			if v, ok := block.Control.(*ast.IfStatement); ok && block.Kind == ast.BlockCondition && transactionActive(v.Expression) {
+				switch e.Kind {
+				case ast.EdgeTrue:
+					d = f.active(d, true)
+				case ast.EdgeFalse:
+					d = f.active(d, false)
+				}
+			}
+			if d == 0 {
+				continue
+			}
+			if e.To == f.g.Exit {
+				if report {
+					f.exit(block, e, d)
+				}
+				continue
+			}
+			if !report && in[e.To]|d != in[e.To] {
+				in[e.To] |= d
+				work = append(work, e.To)
+			}
+		}
+	}
+	process := func(block *ast.BasicBlock, from int, d depths, report bool) {
+		var before depths
+		for _, stm := range block.Statements[from:] {
+			before |= d
+			d = f.step(stm, d, report)
+		}
+		if from >= len(block.Statements) {
+			before = d
+		}
+		propagate(block, d, before, report)
+	}
+
+	process(start, from, init, false)
+	for len(work) > 0 {
+		block := work[len(work)-1]
+		work = work[:len(work)-1]
+		process(block, 0, in[block], false)
+	}
+
+	process(start, from, init, true)
+	for _, block := range f.g.Blocks {
+		if in[block] != 0 {
+			process(block, 0, in[block], true)
+		}
+	}
+}
+
+type diagnosticKey struct {
+	code   string
+	offset int
+}
+
+type transactionCheck struct {
+	g        *ast.CFG
+	reported map[diagnosticKey]bool // о чем уже сообщено: одни и те же блоки проходятся с разными начальными уровнями
+	result   []Diagnostic
+}
+
+func (t *transactionCheck) report(code string, severity Severity, node ast.Statement, format string, args ...interface{}) {
+	rng := ast.RangeOf(node)
+	key := diagnosticKey{code: code, offset: rng.Start.Offset}
+	if t.reported[key] {
+		return
+	}
+
+	t.reported[key] = true
+	t.result = append(t.result, Diagnostic{Code: code, Severity: severity, Message: fmt.Sprintf(format, args...), Range: rng})
+}
+
+// balance проверяет уровни вложенности от начала метода: фиксация или отмена без транзакции и вложенные транзакции
+func (t *transactionCheck) balance() {
+	f := &transactionFlow{g: t.g, exit: func(*ast.BasicBlock, ast.Edge, depths) {}}
+	f.active = func(d depths, active bool) depths {
+		if !active {
+			return 1
+		}
+		if d&^1 != 0 {
+			return d &^ 1
+		}
+		return d
+	}
+	f.step = func(stm ast.Statement, d depths, report bool) depths {
+		call, op := transactionCall(stm)
+		switch op {
+		case opBegin:
+			if report && d&^1 != 0 {
+				t.report(CodeNestedTransaction, SeverityWarning, call, "%s is called while another transaction may be active", call.Name)
+			}
+			return d.begin()
+		case opCommit, opRollback:
+			if report && d&1 != 0 {
+				t.report(CodeUnbalancedTransaction, SeverityError, call, "%s is called when no transaction may be active", call.Name)
+			}
+			return d.end()
+		}
+		return d
+	}
+
+	f.run(t.g.Entry, 0, 1)
+}
+
+// begin проверяет, что транзакция, начатая оператором i блока, завершается на всех путях до выхода из метода.
+// Уровни считаются относительно нее: 1 - открыта только она, 0 - она завершена и дальше не отслеживается
+func (t *transactionCheck) begin(block *ast.BasicBlock, i int) {
+	begin, _ := transactionCall(block.Statements[i])
+	line := ast.PositionOf(begin).Line
+
+	f := &transactionFlow{g: t.g}
+	f.active = func(d depths, active bool) depths {
+		if !active {
+			return 0
+		}
+		return d
+	}
+	f.step = func(stm ast.Statement, d depths, _ bool) depths {
+		switch _, op := transactionCall(stm); op {
+		case opBegin:
+			return d.begin()
+		case opCommit, opRollback:
+			return d.end() &^ 1
+		}
+		return d
+	}
+	f.exit = func(from *ast.BasicBlock, e ast.Edge, _ depths) {
+		switch e.Kind {
+		case ast.EdgeReturn, ast.EdgeThrow:
+			t.report(CodeUnbalancedTransaction, SeverityError, from.Control,
+				"the method exits while the transaction started at line %d is still active", line)
+		default:
+			t.report(CodeUnbalancedTransaction, SeverityError, begin,
+				"transaction started by %s is not committed or rolled back on all paths", begin.Name)
+		}
+	}
+
+	f.run(block, i+1, 1<<1)
+}
+
+// transactionPattern проверяет расположение вызовов в операторах body: inCatch - body находится в блоке Исключение,
+// inLoop - в теле цикла
+func transactionPattern(body ast.Statements, inCatch, inLoop bool) []Diagnostic {
+	var result []Diagnostic
+	report := func(code string, node ast.Statement, format string, args ...interface{}) {
+		result = append(result, Diagnostic{Code: code, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...), Range: ast.RangeOf(node)})
+	}
+
+	for i, stm := range body {
+		switch call, op := transactionCall(stm); op {
+		case opBegin:
+			if inLoop {
+				report(CodeTransactionInLoop, call, "%s is called inside a loop, consider one transaction around the loop", call.Name)
+			}
+
+			var try ast.TryStatement
+			ok := false
+			if i+1 < len(body) {
+				try, ok = body[i+1].(ast.TryStatement)
+			}
+			if !ok {
+				report(CodeTransactionPattern, call, "%s must be followed by Попытка", call.Name)
+				break
+			}
+			if !hasCall(try.Body, opCommit) {
+				report(CodeTransactionPattern, call, "Попытка after %s does not commit the transaction", call.Name)
+			}
+			if !hasCall(try.Catch, opRollback) {
+				report(CodeTransactionPattern, call, "Исключение after %s does not roll back the transaction", call.Name)
+			}
+		case opRollback:
+			if !inCatch {
+				report(CodeTransactionPattern, call, "%s must be called in the Исключение branch of Попытка", call.Name)
+			}
+		}
+
+		switch v := stm.(type) {
+		case *ast.IfStatement:
+			result = append(result, transactionPattern(v.TrueBlock, inCatch, inLoop)...)
+			for _, item := range v.IfElseBlock {
+				result = append(result, transactionPattern(item.(*ast.IfStatement).TrueBlock, inCatch, inLoop)...)
+			}
+			result = append(result, transactionPattern(v.ElseBlock, inCatch, inLoop)...)
+		case *ast.LoopStatement:
+			result = append(result, transactionPattern(v.Body, inCatch, true)...)
+		case ast.TryStatement:
+			result = append(result, transactionPattern(v.Body, false, inLoop)...)
+			result = append(result, transactionPattern(v.Catch, true, inLoop)...)
+		}
+	}
+
+	return result
+}
+
+// hasCall проверяет, есть ли среди операторов body, включая вложенные, вызов op
+func hasCall(body ast.Statements, op transactionOp) bool {
+	found := false
+	inspect(body, func(stm ast.Statement) bool {
+		if _, o := transactionCall(stm); o == op {
+			found = true
+		}
+		return !found
+	})
+
+	return found
+}
@@ -0,0 +1,83 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactions(t *testing.T) {
+	code := `Процедура Стандарт(Объект)
+	НачатьТранзакцию();
+	Попытка
+		Объект.Записать();
+		ЗафиксироватьТранзакцию();
+	Исключение
+		ОтменитьТранзакцию();
+		ВызватьИсключение;
+	КонецПопытки;
+КонецПроцедуры
+
+Функция РанняяОтмена(Объект)
+	НачатьТранзакцию();
+	Попытка
+		Если Объект = Неопределено Тогда
+			Возврат Ложь;
+		КонецЕсли;
+		Объект.Записать();
+		ЗафиксироватьТранзакцию();
+	Исключение
+		Если ТранзакцияАктивна() Тогда
+			ОтменитьТранзакцию();
+		КонецЕсли;
+		ВызватьИсключение;
+	КонецПопытки;
+	Возврат Истина;
+КонецФункции
+
+Процедура БезПопытки(Объекты)
+	Для Каждого Объект Из Объекты Цикл
+		НачатьТранзакцию();
+		Объект.Записать();
+	КонецЦикла;
+	ЗафиксироватьТранзакцию();
+	ОтменитьТранзакцию();
+КонецПроцедуры
+
+Процедура ФиксацияВИсключении(Объект)
+	НачатьТранзакцию();
+	Попытка
+		Объект.Записать();
+		ЗафиксироватьТранзакцию();
+		Объект.Прочитать();
+	Исключение
+		ЗафиксироватьТранзакцию();
+	КонецПопытки;
+КонецПроцедуры`
+
+	a := ast.NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	var actual []string
+	for _, d := range Transactions(&a.ModuleStatement) {
+		actual = append(actual, fmt.Sprintf("%d %s %s: %s", d.Range.Start.Line, d.Severity, d.Code, d.Message))
+	}
+	assert.Equal(t, []string{
+		// Возврат внутри Попытки до фиксации, ВызватьИсключение после ОтменитьТранзакцию в ветке ТранзакцияАктивна() не сообщается
+		"16 error UnbalancedTransaction: the method exits while the transaction started at line 13 is still active",
+		"31 warning NestedTransaction: НачатьТранзакцию is called while another transaction may be active",
+		"31 warning TransactionInLoop: НачатьТранзакцию is called inside a loop, consider one transaction around the loop",
+		"31 warning TransactionPattern: НачатьТранзакцию must be followed by Попытка",
+		"31 error UnbalancedTransaction: transaction started by НачатьТранзакцию is not committed or rolled back on all paths",
+		"34 error UnbalancedTransaction: ЗафиксироватьТранзакцию is called when no transaction may be active",
+		"35 warning TransactionPattern: ОтменитьТранзакцию must be called in the Исключение branch of Попытка",
+		"35 error UnbalancedTransaction: ОтменитьТранзакцию is called when no transaction may be active",
+		"39 warning TransactionPattern: Исключение after НачатьТранзакцию does not roll back the transaction",
+		// исключение после фиксации попадает в Исключение, где транзакции уже нет
+		"45 error UnbalancedTransaction: ЗафиксироватьТранзакцию is called when no transaction may be active",
+	}, actual)
+}
@@ -0,0 +1,251 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const CodeUnreachableCode = "UnreachableCode"
+
+// DeadReason причина, по которой код недостижим
+type DeadReason int
+
+const (
+	DeadAfterJump   DeadReason = iota // операторы после Возврат, ВызватьИсключение, Прервать, Продолжить или Перейти
+	DeadFalseBranch                   // ветка Если Ложь Тогда или ИначеЕсли Ложь Тогда
+	DeadUnusedLabel                   // метка, на которую нет ни одного перехода
+)
+
+// DeadCode участок недостижимого кода. Statements - операторы участка, для DeadFalseBranch - сама ветка:
+// *ast.IfStatement условия Если или ИначеЕсли
+type DeadCode struct {
+	Reason     DeadReason
+	Range      ast.Range
+	Statements ast.Statements
+}
+
+// FindUnreachable находит недостижимый код в методах и операторах модуля: операторы в том же блоке после
+// Возврат, ВызватьИсключение, Прервать, Продолжить и Перейти (если перед ними нет метки, на которую
+// есть переход), ветки с условием Ложь и метки, на которые никто не переходит
+func FindUnreachable(module *ast.ModuleStatement) []DeadCode {
+	var result []DeadCode
+
+	var body ast.Statements
+	for _, item := range module.Body {
+		if pf, ok := item.(*ast.FunctionOrProcedure); ok {
+			result = append(result, findDead(pf.Body, jumpTargets(pf.Body))...)
+		} else {
+			body = append(body, item)
+		}
+	}
+
+	return append(result, findDead(body, jumpTargets(body))...)
+}
+
+// Unreachable сообщает о недостижимом коде модуля, разобранного из source. Исправление удаляет участок:
+// дерево модуля меняется и печатается заново с настройками conf, но только в измененных местах (ast.Edits)
+func Unreachable(source string, module *ast.ModuleStatement, conf ast.PrintConf) []Diagnostic {
+	var result []Diagnostic
+	for _, dead := range FindUnreachable(module) {
+		d := Diagnostic{Code: CodeUnreachableCode, Severity: SeverityWarning, Range: dead.Range}
+		switch dead.Reason {
+		case DeadAfterJump:
+			d.Message = "unreachable code"
+		case DeadFalseBranch:
+			d.Message = "condition is always false, branch is never executed"
+		case DeadUnusedLabel:
+			d.Message = fmt.Sprintf("label ~%s is never used", dead.Statements[0].(*ast.GoToLabelStatement).Name)
+		}
+
+		if edits, err := removeDead(source, dead, conf); err == nil && len(edits) > 0 {
+			d.Fixes = []Fix{{Message: "remove unreachable code", Edits: edits}}
+		}
+		result = append(result, d)
+	}
+
+	SortDiagnostics(result)
+	return result
+}
+
+// jumpTargets имена меток, на которые есть переходы
+func jumpTargets(body ast.Statements) map[string]bool {
+	result := map[string]bool{}
+	inspect(body, func(stm ast.Statement) bool {
+		if g, ok := stm.(ast.GoToStatement); ok && g.Label != nil {
+			result[ast.NormalizeName(g.Label.Name)] = true
+		}
+		return true
+	})
+
+	return result
+}
+
+func findDead(items ast.Statements, targets map[string]bool) []DeadCode {
+	var result []DeadCode
+	var run ast.Statements
+
+	flush := func() {
+		if len(run) > 0 {
+			result = append(result, DeadCode{Reason: DeadAfterJump, Range: spanOf(run), Statements: run})
+			run = nil
+		}
+	}
+
+	terminated := false
+	for _, item := range items {
+		if label, ok := item.(*ast.GoToLabelStatement); ok {
+			if targets[ast.NormalizeName(label.Name)] {
+				// на метку переходят, код после нее выполняется
+				flush()
+				terminated = false
+				continue
+			}
+			if !terminated {
+				result = append(result, DeadCode{Reason: DeadUnusedLabel, Range: ast.RangeOf(label), Statements: ast.Statements{label}})
+				continue
+			}
+		}
+
+		if terminated {
+			run = append(run, item)
+			continue
+		}
+
+		switch v := item.(type) {
+		case *ast.IfStatement:
+			result = append(result, deadBranch(v, targets)...)
+			for _, elseIf := range v.IfElseBlock {
+				result = append(result, deadBranch(elseIf.(*ast.IfStatement), targets)...)
+			}
+			result = append(result, findDead(v.ElseBlock, targets)...)
+		case *ast.LoopStatement:
+			result = append(result, findDead(v.Body, targets)...)
+		case ast.TryStatement:
+			result = append(result, findDead(v.Body, targets)...)
+			result = append(result, findDead(v.Catch, targets)...)
+		case *ast.ReturnStatement, ast.ThrowStatement, ast.BreakStatement, ast.ContinueStatement, ast.GoToStatement:
+			terminated = true
+		}
+	}
+	flush()
+
+	return result
+}
+
+// deadBranch проверяет ветку Если или ИначеЕсли: при условии Ложь недостижима вся ветка
+func deadBranch(branch *ast.IfStatement, targets map[string]bool) []DeadCode {
+	if value, ok := branch.Expression.(bool); ok && !value {
+		rng := ast.RangeOf(branch)
+		if len(branch.TrueBlock) > 0 && (len(branch.IfElseBlock) > 0 || branch.ElseBlock != nil) {
+			rng = spanOf(branch.TrueBlock)
+		}
+		return []DeadCode{{Reason: DeadFalseBranch, Range: rng, Statements: ast.Statements{branch}}}
+	}
+
+	return findDead(branch.TrueBlock, targets)
+}
+
+func spanOf(items ast.Statements) ast.Range {
+	return ast.Range{Start: ast.RangeOf(items[0]).Start, End: ast.RangeOf(items[len(items)-1]).End}
+}
+
+// removeDead разбирает source заново, удаляет из дерева участок dead и возвращает правки
+func removeDead(source string, dead DeadCode, conf ast.PrintConf) ([]ast.TextEdit, error) {
+	a := ast.NewAST(source)
+	if err := a.Parse(); err != nil {
+		return nil, err
+	}
+
+	// узлы нового дерева сопоставляются с найденными по позиции в исходном коде
+	p := &pruner{reason: dead.Reason, offsets: map[int]bool{}}
+	for _, stm := range dead.Statements {
+		p.offsets[ast.PositionOf(stm).Offset] = true
+	}
+
+	for i, item := range a.ModuleStatement.Body {
+		if pf, ok := item.(*ast.FunctionOrProcedure); ok {
+			pf.Body = p.prune(pf.Body)
+		} else {
+			a.ModuleStatement.Body = append(a.ModuleStatement.Body[:i], p.prune(a.ModuleStatement.Body[i:])...)
+			break
+		}
+	}
+
+	return ast.Edits(source, &a.ModuleStatement, conf)
+}
+
+type pruner struct {
+	reason  DeadReason
+	offsets map[int]bool
+}
+
+func (p *pruner) dead(stm ast.Statement) bool {
+	return p.offsets[ast.PositionOf(stm).Offset]
+}
+
+func (p *pruner) prune(items ast.Statements) ast.Statements {
+	result := make(ast.Statements, 0, len(items))
+	for _, item := range items {
+		if p.reason != DeadFalseBranch && p.dead(item) {
+			continue
+		}
+
+		switch v := item.(type) {
+		case *ast.IfStatement:
+			if p.reason == DeadFalseBranch && p.dead(v) {
+				result = append(result, p.falseIf(v)...)
+				continue
+			}
+
+			v.TrueBlock = p.prune(v.TrueBlock)
+			v.IfElseBlock = p.pruneElseIf(v.IfElseBlock)
+			if v.ElseBlock != nil {
+				v.ElseBlock = p.prune(v.ElseBlock)
+			}
+		case *ast.LoopStatement:
+			v.Body = p.prune(v.Body)
+		case ast.TryStatement:
+			v.Body = p.prune(v.Body)
+			v.Catch = p.prune(v.Catch)
+			item = v
+		}
+
+		result = append(result, item)
+	}
+
+	return result
+}
+
+func (p *pruner) pruneElseIf(items ast.Statements) ast.Statements {
+	result := make(ast.Statements, 0, len(items))
+	for _, item := range items {
+		elseIf := item.(*ast.IfStatement)
+		if p.reason == DeadFalseBranch && p.dead(elseIf) {
+			continue
+		}
+
+		elseIf.TrueBlock = p.prune(elseIf.TrueBlock)
+		result = append(result, elseIf)
+	}
+
+	return result
+}
+
+// falseIf заменяет Если Ложь Тогда: первая ИначеЕсли становится условием, без них остается ветка Иначе
+func (p *pruner) falseIf(stm *ast.IfStatement) ast.Statements {
+	elseIfs := p.pruneElseIf(stm.IfElseBlock)
+	if stm.ElseBlock != nil {
+		stm.ElseBlock = p.prune(stm.ElseBlock)
+	}
+
+	if len(elseIfs) == 0 {
+		return stm.ElseBlock
+	}
+
+	first := elseIfs[0].(*ast.IfStatement)
+	stm.Expression = first.Expression
+	stm.TrueBlock = first.TrueBlock
+	stm.IfElseBlock = elseIfs[1:]
+	return ast.Statements{stm}
+}
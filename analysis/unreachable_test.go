@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnreachable(t *testing.T) {
+	code := `Функция Ф(Список)
+	Для Каждого Эл Из Список Цикл
+		Если Эл Тогда
+			Продолжить;
+			Сообщить("после продолжить");
+		КонецЕсли;
+	КонецЦикла;
+
+	Если Ложь Тогда
+		Сообщить("отладка");
+	ИначеЕсли Список = Неопределено Тогда
+		Возврат 0;
+	Иначе
+		Перейти ~Выход;
+	КонецЕсли;
+	~Лишняя:
+	Возврат 1;
+	Сообщить("после возврата");
+	Если Истина Тогда
+		Сообщить(2);
+	КонецЕсли;
+	~Выход:
+	Возврат 2;
+КонецФункции`
+
+	a := ast.NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	t.Run("find", func(t *testing.T) {
+		dead := FindUnreachable(&a.ModuleStatement)
+		if !assert.Len(t, dead, 4) {
+			return
+		}
+
+		assert.Equal(t, DeadAfterJump, dead[0].Reason)
+		assert.Equal(t, 5, dead[0].Range.Start.Line)
+		assert.Equal(t, DeadFalseBranch, dead[1].Reason)
+		assert.Equal(t, ast.Range{
+			Start: ast.Position{Line: 10, Column: 3, Offset: 273},
+			End:   ast.Position{Line: 10, Column: 22, Offset: 307},
+		}, dead[1].Range)
+		assert.Equal(t, DeadUnusedLabel, dead[2].Reason)
+		assert.Equal(t, DeadAfterJump, dead[3].Reason)
+		assert.Len(t, dead[3].Statements, 2)
+		assert.Equal(t, 18, dead[3].Range.Start.Line)
+		assert.Equal(t, 21, dead[3].Range.End.Line)
+	})
+	t.Run("fixes", func(t *testing.T) {
+		diagnostics := Unreachable(code, &a.ModuleStatement, ast.PrintConf{Margin: 4})
+		if !assert.Len(t, diagnostics, 4) {
+			return
+		}
+		assert.Equal(t, "label ~Лишняя is never used", diagnostics[2].Message)
+
+		var edits []ast.TextEdit
+		for _, d := range diagnostics {
+			if assert.Len(t, d.Fixes, 1) {
+				edits = append(edits, d.Fixes[0].Edits...)
+			}
+		}
+
+		result, err := ast.ApplyEdits(code, edits)
+		assert.NoError(t, err)
+
+		fixed := ast.NewAST(result)
+		if !assert.NoError(t, fixed.Parse()) {
+			return
+		}
+		assert.Empty(t, FindUnreachable(&fixed.ModuleStatement))
+		assert.NotContains(t, result, "отладка")
+		assert.NotContains(t, result, "после")
+		assert.NotContains(t, result, "Лишняя")
+		assert.Contains(t, result, "\tЕсли Список = Неопределено Тогда\n")
+	})
+	t.Run("comments", func(t *testing.T) {
+		fix := func(code string) string {
+			a := ast.NewAST(code)
+			if !assert.NoError(t, a.Parse()) {
+				return ""
+			}
+
+			var edits []ast.TextEdit
+			for _, d := range Unreachable(code, &a.ModuleStatement, ast.PrintConf{Margin: 4}) {
+				if assert.Len(t, d.Fixes, 1) {
+					edits = append(edits, d.Fixes[0].Edits...)
+				}
+			}
+
+			result, err := ast.ApplyEdits(code, edits)
+			assert.NoError(t, err)
+			return result
+		}
+
+		assert.Equal(t, "Процедура П()\n\tВозврат;\n\t// мертвый\nКонецПроцедуры",
+			fix("Процедура П()\n\tВозврат;\n\t// мертвый\n\tСообщить(1); // хвост\nКонецПроцедуры"))
+
+		assert.Equal(t, "Процедура П()\n\tПерейти ~М;\n\t// про метку\n\t~М:\n\tСообщить(2);\nКонецПроцедуры",
+			fix("Процедура П()\n\tПерейти ~М;\n\tСообщить(1);\n\t// про метку\n\t~М:\n\tСообщить(2);\nКонецПроцедуры"))
+
+		assert.Equal(t, "Процедура П()\n\t// до\n\tа = 1; // первый\n\t// между\n\tб = 2; // важно\n\tв = 3;\nКонецПроцедуры",
+			fix("Процедура П()\n\t// до\n\tЕсли Ложь Тогда\n\t\tг = 0;\n\tИначе\n\t\tа = 1; // первый\n\t\t// между\n\t\tб = 2; // важно\n\tКонецЕсли;\n\tв = 3;\nКонецПроцедуры"))
+	})
+}
@@ -0,0 +1,328 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+const (
+	CodeUnusedVariable       = "UnusedVariable"
+	CodeUnusedParameter      = "UnusedParameter"
+	CodeUnusedModuleVariable = "UnusedModuleVariable"
+)
+
+// UnusedConf настройки проверки неиспользуемых имен
+type UnusedConf struct {
+	// EventHandlers дополнительные имена обработчиков событий, у которых сигнатуру задает платформа
+	// и параметры не проверяются. Стандартные обработчики (ПриСозданииНаСервере, ПередЗаписью...) известны и так
+	EventHandlers []string
+	// Undeclared сообщать о локальных переменных без Перем, которым только присваивают значение. В модулях
+	// объектов и форм такие имена - реквизиты, и присваивание им нужно, поэтому по умолчанию они не проверяются.
+	// Подходит для модулей без реквизитов (общие модули), как AssignedConf.Undeclared
+	Undeclared bool
+	// Print настройки печати участков, которые меняют исправления
+	Print ast.PrintConf
+}
+
+// стандартные обработчики событий модулей объектов, менеджеров, форм и приложения
+var eventHandlers = []string{
+	"ПриСозданииНаСервере", "ПриОткрытии", "ПередЗакрытием", "ПриЗакрытии", "ОбработкаОповещения",
+	"ОбработкаВыбора", "ОбработкаАктивизацииОбъекта", "ПриЧтенииНаСервере", "ПередЗаписью", "ПередЗаписьюНаСервере",
+	"ПриЗаписи", "ПриЗаписиНаСервере", "ПослеЗаписи", "ПослеЗаписиНаСервере", "ПередУдалением",
+	"ОбработкаПроверкиЗаполнения", "ОбработкаПроверкиЗаполненияНаСервере", "ОбработкаЗаполнения", "ПриКопировании",
+	"ОбработкаПроведения", "ОбработкаУдаленияПроведения", "ПриУстановкеНовогоНомера", "ПриУстановкеНовогоКода",
+	"ПередЗагрузкойДанныхИзНастроекНаСервере", "ПриЗагрузкеДанныхИзНастроекНаСервере",
+	"ПриСохраненииДанныхВНастройкахНаСервере", "ОбработкаПолученияДанныхВыбора", "ОбработкаПолученияФормы",
+	"ОбработкаПолученияПредставления", "ОбработкаПолученияПолейПредставления", "ОбработкаПолученияПолейПредставленияНаСервере",
+	"ПередНачаломРаботыСистемы", "ПриНачалеРаботыСистемы", "ПередЗавершениемРаботыСистемы",
+	"ПриЗавершенииРаботыСистемы", "ОбработкаВнешнегоСобытия", "ОбработкаКоманды", "ОбработкаНавигационнойСсылки",
+	"ОбработкаЗапросаВнешнегоСобытия", "ОбработкаОшибкиПриВыполненииОтчета", "ПриКомпоновкеРезультата",
+}
+
+// параметры, по которым узнаются обработчики событий элементов формы и команд с произвольными именами
+var eventParams = []string{"Элемент", "Команда", "ПараметрКоманды", "Отказ", "СтандартнаяОбработка", "Источник"}
+
+// Unused находит неиспользуемые имена в модуле, разобранном из source:
+//   - локальные переменные, которым присваивается значение, но которые нигде не читаются (только с conf.Undeclared);
+//   - переменные Перем, которые не читаются;
+//   - параметры, на которые нет ни одной ссылки, кроме параметров обработчиков событий;
+//   - неэкспортные переменные модуля, которые нигде не используются.
+//
+// К каждой диагностике, где это безопасно, прилагается исправление, удаляющее объявление. Присваивания
+// удаляются, только если в правой части нет вызовов, у вызова присваивание убирается, а сам вызов остается.
+// Исправление меняет дерево и печатает с настройками conf.Print только измененные участки (ast.Edits)
+func Unused(source string, module *ast.ModuleStatement, conf UnusedConf) []Diagnostic {
+	res := ast.Resolve(module)
+	handlers := map[string]bool{}
+	for _, name := range append(eventHandlers, conf.EventHandlers...) {
+		handlers[ast.NormalizeName(name)] = true
+	}
+
+	// методы, имена которых встречаются в строках, вызываются платформой: ОписаниеОповещения, ПодключитьОбработчикОжидания
+	inspect(module.Body, func(stm ast.Statement) bool {
+		if s, ok := stm.(string); ok {
+			handlers[ast.NormalizeName(s)] = true
+		}
+		return true
+	})
+
+	u := &unused{source: source, module: module, conf: conf.Print}
+
+	for _, sym := range res.Module.Symbols() {
+		if !sym.Export && len(sym.References) == 0 {
+			u.moduleVariable(sym)
+		}
+	}
+
+	for _, pf := range methods(module) {
+		isHandler := handlers[ast.NormalizeName(pf.Name)] || hasEventParams(pf)
+		for _, sym := range res.ScopeOf(pf).Symbols() {
+			switch sym.Kind {
+			case ast.SymbolParam:
+				if !isHandler && len(sym.References) == 0 {
+					u.parameter(sym, pf)
+				}
+			case ast.SymbolVar:
+				u.variable(sym)
+			case ast.SymbolLocal:
+				if conf.Undeclared {
+					u.variable(sym)
+				}
+			}
+		}
+	}
+	for _, sym := range res.Body.Symbols() {
+		if sym.Kind != ast.SymbolLocal || conf.Undeclared {
+			u.variable(sym)
+		}
+	}
+
+	SortDiagnostics(u.diagnostics)
+	return u.diagnostics
+}
+
+type unused struct {
+	source      string
+	module      *ast.ModuleStatement
+	conf        ast.PrintConf
+	calls       map[string][]ast.MethodStatement // вызовы методов модуля по имени, заполняются при первом обращении
+	unsafe      map[string]bool                  // методы, вызовы которых исправить нельзя: через точку или в строке Выполнить
+	diagnostics []Diagnostic
+}
+
+func (u *unused) report(code, message string, rng ast.Range, fix *Fix) {
+	d := Diagnostic{Code: code, Severity: SeverityWarning, Message: message, Range: rng}
+	if fix != nil {
+		d.Fixes = []Fix{*fix}
+	}
+
+	u.diagnostics = append(u.diagnostics, d)
+}
+
+func (u *unused) variable(sym *ast.Symbol) {
+	var writes []*ast.Reference
+	for _, ref := range sym.References {
+		if !ref.Write {
+			return
+		}
+		if ref.Loop {
+			// переменную цикла из заголовка не убрать
+			return
+		}
+		writes = append(writes, ref)
+	}
+
+	var fix *Fix
+	if edits, ok := u.fix(func(module *ast.ModuleStatement) bool {
+		return (sym.Kind != ast.SymbolVar || deleteVar(module, sym.Decl)) && deleteAssignments(module, writes)
+	}); ok {
+		fix = &Fix{Message: fmt.Sprintf("remove variable %q", sym.Name), Edits: edits}
+	}
+
+	message := fmt.Sprintf("variable %q is assigned but never used", sym.Name)
+	if len(writes) == 0 {
+		message = fmt.Sprintf("variable %q is declared but never used", sym.Name)
+	}
+	u.report(CodeUnusedVariable, message, sym.Decl, fix)
+}
+
+func (u *unused) moduleVariable(sym *ast.Symbol) {
+	var fix *Fix
+	if edits, ok := u.fix(func(module *ast.ModuleStatement) bool {
+		for name, g := range module.GlobalVariables {
+			if ast.RangeOf(g.Var) == sym.Decl {
+				delete(module.GlobalVariables, name)
+				return true
+			}
+		}
+		return false
+	}); ok {
+		fix = &Fix{Message: fmt.Sprintf("remove variable %q", sym.Name), Edits: edits}
+	}
+
+	u.report(CodeUnusedModuleVariable, fmt.Sprintf("module variable %q is never used", sym.Name), sym.Decl, fix)
+}
+
+// parameter сообщает о неиспользуемом параметре. Исправление удаляет параметр и соответствующий аргумент
+// во всех вызовах метода в модуле. Вызовы экспортного метода могут быть в других модулях, для него
+// исправления нет, как и для метода, вызовы которого нельзя исправить все
+func (u *unused) parameter(sym *ast.Symbol, pf *ast.FunctionOrProcedure) {
+	var fix *Fix
+	if edits, ok := u.removeParam(sym, pf); ok {
+		fix = &Fix{Message: fmt.Sprintf("remove parameter %q", sym.Name), Edits: edits}
+	}
+
+	u.report(CodeUnusedParameter, fmt.Sprintf("parameter %q is never used", sym.Name), sym.Decl, fix)
+}
+
+func (u *unused) removeParam(sym *ast.Symbol, pf *ast.FunctionOrProcedure) ([]ast.TextEdit, bool) {
+	index := -1
+	for i, p := range pf.Params {
+		if ast.RangeOf(p) == sym.Decl {
+			index = i
+		}
+	}
+	if pf.Export || index < 0 {
+		return nil, false
+	}
+
+	u.collectCalls()
+	name := ast.NormalizeName(pf.Name)
+	if u.unsafe[name] {
+		return nil, false
+	}
+
+	calls := map[int]bool{}
+	for _, call := range u.calls[name] {
+		calls[call.Pos.Offset] = true
+	}
+
+	return u.fix(func(module *ast.ModuleStatement) bool {
+		for _, m := range methods(module) {
+			if m.Pos == pf.Pos {
+				m.Params = append(m.Params[:index], m.Params[index+1:]...)
+			}
+		}
+
+		module.Body = rewriteList(module.Body, func(stm ast.Statement) ast.Statement {
+			// аргумент, если он передан, удаляется из всех вызовов
+			if call, ok := stm.(ast.MethodStatement); ok && calls[call.Pos.Offset] && index < len(call.Param.Statements) {
+				call.Param.Statements = append(call.Param.Statements[:index:index], call.Param.Statements[index+1:]...)
+				return call
+			}
+			return stm
+		})
+		return true
+	})
+}
+
+// collectCalls находит вызовы методов модуля по имени
+func (u *unused) collectCalls() {
+	if u.calls != nil {
+		return
+	}
+
+	u.calls, u.unsafe = map[string][]ast.MethodStatement{}, map[string]bool{}
+	members := map[int]bool{}
+	var walk func(stm ast.Statement, embedded bool)
+	walk = func(stm ast.Statement, embedded bool) {
+		inspect(stm, func(item ast.Statement) bool {
+			switch v := item.(type) {
+			case ast.CallChainStatement:
+				if m, ok := v.Unit.(ast.MethodStatement); ok {
+					members[m.Pos.Offset] = true
+				}
+			case ast.MethodStatement:
+				name := ast.NormalizeName(v.Name)
+				if embedded || members[v.Pos.Offset] || v.Pos == (ast.Position{}) {
+					u.unsafe[name] = true
+				} else {
+					u.calls[name] = append(u.calls[name], v)
+				}
+
+				walk(v.Param, embedded)
+				// позиции кода в строке указывают внутрь литерала, правки в нем ненадежны
+				walk(v.Embedded, true)
+				return false
+			}
+			return true
+		})
+	}
+	walk(u.module.Body, false)
+}
+
+// fix разбирает source заново, меняет дерево функцией change и возвращает правки, которые печатают только
+// измененные места (ast.Edits). Если change вернула false, исправления нет
+func (u *unused) fix(change func(module *ast.ModuleStatement) bool) ([]ast.TextEdit, bool) {
+	a := ast.NewAST(u.source)
+	if err := a.Parse(); err != nil || !change(&a.ModuleStatement) {
+		return nil, false
+	}
+
+	edits, err := ast.Edits(u.source, &a.ModuleStatement, u.conf)
+	return edits, err == nil && len(edits) > 0
+}
+
+// deleteVar удаляет переменную Перем метода, объявленную в decl
+func deleteVar(module *ast.ModuleStatement, decl ast.Range) bool {
+	for _, pf := range methods(module) {
+		for name, v := range pf.ExplicitVariables {
+			if ast.RangeOf(v) == decl {
+				delete(pf.ExplicitVariables, name)
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// deleteAssignments удаляет присваивания writes. Если в правой части один вызов, вместо присваивания
+// остается вызов, а присваивание с вызовами внутри выражения удалить нельзя
+func deleteAssignments(module *ast.ModuleStatement, writes []*ast.Reference) bool {
+	offsets := map[int]bool{}
+	for _, ref := range writes {
+		offsets[ref.Range.Start.Offset] = true
+	}
+
+	deleted, ok := 0, true
+	module.Body = rewriteList(module.Body, func(stm ast.Statement) ast.Statement {
+		items, isList := stm.(ast.Statements)
+		if !isList {
+			return stm
+		}
+
+		result := make(ast.Statements, 0, len(items))
+		for _, item := range items {
+			a, isAssignment := item.(ast.AssignmentStatement)
+			if !isAssignment || !offsets[ast.PositionOf(a.Var).Offset] {
+				result = append(result, item)
+				continue
+			}
+
+			deleted++
+			switch {
+			case len(a.Expr.Statements) == 1 && isCall(a.Expr.Statements[0]):
+				result = append(result, a.Expr.Statements[0])
+			case hasCalls(a.Expr):
+				ok = false
+			}
+		}
+		return result
+	})
+
+	return ok && deleted == len(writes)
+}
+
+func hasEventParams(pf *ast.FunctionOrProcedure) bool {
+	for _, p := range pf.Params {
+		for _, name := range eventParams {
+			if ast.NormalizeName(p.Name) == ast.NormalizeName(name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,192 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnused(t *testing.T) {
+	code := `Перем Лишняя, Нужная;
+Перем Публичная Экспорт;
+
+Функция Посчитать(Знач Список, Лимит = ",", Флаг)
+	Перем Итог, Забытая;
+	Итог = 0;
+	Временная = 1;
+	Результат = ВычислитьСумму(Список);
+	Для Каждого Элемент Из Список Цикл
+		Итог = Итог + Нужная;
+	КонецЦикла;
+	Возврат Итог;
+КонецФункции
+
+Процедура ПередЗаписью(Отказ, Режим)
+КонецПроцедуры
+
+Процедура ПриИзмененииПоля(Элемент)
+КонецПроцедуры
+
+Процедура ОбработкаОтвета(Результат, Параметры) Экспорт
+	Оповещение = Новый ОписаниеОповещения("ОбработкаОтвета", ЭтотОбъект);
+КонецПроцедуры`
+
+	a := ast.NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	diagnostics := Unused(code, &a.ModuleStatement, UnusedConf{Undeclared: true})
+	fixed := map[string]string{}
+	var messages []string
+	for _, d := range diagnostics {
+		messages = append(messages, d.Code+": "+d.Message)
+		if len(d.Fixes) == 1 {
+			result, err := ast.ApplyEdits(code, d.Fixes[0].Edits)
+			assert.NoError(t, err)
+			fixed[d.Message] = result
+		}
+	}
+
+	assert.Equal(t, []string{
+		`UnusedModuleVariable: module variable "Лишняя" is never used`,
+		`UnusedParameter: parameter "Лимит" is never used`,
+		`UnusedParameter: parameter "Флаг" is never used`,
+		`UnusedVariable: variable "Забытая" is declared but never used`,
+		`UnusedVariable: variable "Временная" is assigned but never used`,
+		`UnusedVariable: variable "Результат" is assigned but never used`,
+		`UnusedVariable: variable "Оповещение" is assigned but never used`,
+	}, messages)
+	assert.Equal(t, ast.Range{
+		Start: ast.Position{Line: 1, Column: 7, Offset: 11},
+		End:   ast.Position{Line: 1, Column: 13, Offset: 23},
+	}, diagnostics[0].Range)
+
+	t.Run("fixes", func(t *testing.T) {
+		assert.Contains(t, fixed[`module variable "Лишняя" is never used`], "Перем Нужная;\nПерем Публичная")
+		assert.Contains(t, fixed[`parameter "Лимит" is never used`], "Функция Посчитать(Знач Список, Флаг)")
+		assert.Contains(t, fixed[`parameter "Флаг" is never used`], `Функция Посчитать(Знач Список, Лимит = ",")`)
+		assert.Contains(t, fixed[`variable "Забытая" is declared but never used`], "\tПерем Итог;\n\tИтог = 0;")
+		assert.Contains(t, fixed[`variable "Временная" is assigned but never used`], "\tИтог = 0;\n\tРезультат =")
+		assert.Contains(t, fixed[`variable "Результат" is assigned but never used`], "\tВычислитьСумму(Список);\n")
+
+		// конструктор побочных эффектов не имеет, присваивание удаляется целиком
+		assert.Contains(t, fixed[`variable "Оповещение" is assigned but never used`], "Экспорт\nКонецПроцедуры")
+	})
+	t.Run("object module attributes", func(t *testing.T) {
+		code := `Процедура ОбработкаЗаполнения(ДанныеЗаполнения, ТекстЗаполнения, СтандартнаяОбработка)
+	Перем Лишняя;
+	Дата = ТекущаяДата();
+	Модифицированность = Истина;
+	Комментарий = "x";
+КонецПроцедуры`
+		a := ast.NewAST(code)
+		if !assert.NoError(t, a.Parse()) {
+			return
+		}
+
+		// без Перем это реквизиты объекта, присваивание им нужно
+		diagnostics := Unused(code, &a.ModuleStatement, UnusedConf{})
+		if assert.Len(t, diagnostics, 1) {
+			assert.Equal(t, `variable "Лишняя" is declared but never used`, diagnostics[0].Message)
+		}
+		assert.Len(t, Unused(code, &a.ModuleStatement, UnusedConf{Undeclared: true}), 4)
+	})
+	t.Run("single declaration", func(t *testing.T) {
+		code := "&НаКлиенте\nПерем Кэш;\n\nПроцедура П(Параметр, Контекст)\n\tПерем Лишняя;\n\tСообщить(Параметр);\nКонецПроцедуры"
+		a := ast.NewAST(code)
+		if !assert.NoError(t, a.Parse()) {
+			return
+		}
+
+		diagnostics := Unused(code, &a.ModuleStatement, UnusedConf{EventHandlers: []string{"П"}})
+		if !assert.Len(t, diagnostics, 2) {
+			return
+		}
+
+		var edits []ast.TextEdit
+		for _, d := range diagnostics {
+			edits = append(edits, d.Fixes[0].Edits...)
+		}
+		result, err := ast.ApplyEdits(code, edits)
+		assert.NoError(t, err)
+		assert.Equal(t, "\nПроцедура П(Параметр, Контекст)\n\tСообщить(Параметр);\nКонецПроцедуры", result)
+	})
+	t.Run("call sites", func(t *testing.T) {
+		code := `Процедура П(а, б, в = 0)
+	Сообщить(а + в);
+КонецПроцедуры
+
+Процедура Экспортная(а, б) Экспорт
+	Сообщить(а);
+КонецПроцедуры
+
+Процедура Вызовы()
+	П(1, 2);
+	П(Строка(1, 2), "б, ""в""", 3);
+	П(1);
+	Экспортная(1, 2);
+КонецПроцедуры`
+
+		a := ast.NewAST(code)
+		if !assert.NoError(t, a.Parse()) {
+			return
+		}
+
+		diagnostics := Unused(code, &a.ModuleStatement, UnusedConf{})
+		if !assert.Len(t, diagnostics, 2) {
+			return
+		}
+
+		// аргумент удаляется вместе с параметром, иначе модуль перестанет компилироваться
+		assert.Equal(t, `parameter "б" is never used`, diagnostics[0].Message)
+		if assert.Len(t, diagnostics[0].Fixes, 1) {
+			result, err := ast.ApplyEdits(code, diagnostics[0].Fixes[0].Edits)
+			assert.NoError(t, err)
+			assert.Contains(t, result, "Процедура П(а, в = 0)")
+			assert.Contains(t, result, "\tП(1);\n\tП(Строка(1, 2), 3);\n\tП(1);\n")
+		}
+
+		// вызовы экспортного метода могут быть в других модулях
+		assert.Equal(t, `parameter "б" is never used`, diagnostics[1].Message)
+		assert.Equal(t, 5, diagnostics[1].Range.Start.Line)
+		assert.Empty(t, diagnostics[1].Fixes)
+	})
+	t.Run("comments", func(t *testing.T) {
+		code := "Процедура П(а, б)\n\tСообщить(а);\nКонецПроцедуры\n\nПроцедура Вызов()\n\t// перед\n\tП(П(1, 2), 3); // вызов\n" +
+			"\tЛишняя = 1; // хвост\n\t// после\n\tХ = Ф(1, // один\n\t\t2);\nКонецПроцедуры"
+		a := ast.NewAST(code)
+		if !assert.NoError(t, a.Parse()) {
+			return
+		}
+
+		// исправления меняют дерево и печатают только измененные места, комментарии остаются
+		fixed := map[string]string{}
+		for _, d := range Unused(code, &a.ModuleStatement, UnusedConf{Undeclared: true}) {
+			if assert.Len(t, d.Fixes, 1, d.Message) {
+				result, err := ast.ApplyEdits(code, d.Fixes[0].Edits)
+				assert.NoError(t, err)
+				fixed[d.Message] = result
+			}
+		}
+
+		assert.Contains(t, fixed[`parameter "б" is never used`], "\t// перед\n\tП(П(1)); // вызов\n")
+		assert.Contains(t, fixed[`variable "Лишняя" is assigned but never used`], "// вызов\n\t// после\n")
+		assert.Contains(t, fixed[`variable "Х" is assigned but never used`], "// после\n\tФ(1, // один\n\t\t2);\n")
+	})
+	t.Run("calls that cannot be fixed", func(t *testing.T) {
+		for _, call := range []string{`ЭтотОбъект.П(1, 2)`, `Выполнить("П(1, 2)")`} {
+			code := "Процедура П(а, б)\n\tСообщить(а);\nКонецПроцедуры\n\nПроцедура Вызов()\n\t" + call + ";\nКонецПроцедуры"
+			a := ast.NewAST(code)
+			if !assert.NoError(t, a.Parse()) {
+				return
+			}
+
+			diagnostics := Unused(code, &a.ModuleStatement, UnusedConf{})
+			if assert.Len(t, diagnostics, 1, call) {
+				assert.Empty(t, diagnostics[0].Fixes, call)
+			}
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package analysis
+
+import (
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+// inspect обходит узел и все вложенные в него операторы и выражения в порядке исходного кода.
+// Если f возвращает false, вложенные узлы не обходятся
+func inspect(stm ast.Statement, f func(ast.Statement) bool) {
+	if stm == nil || !f(stm) {
+		return
+	}
+
+	switch v := stm.(type) {
+	case ast.Statements:
+		for _, item := range v {
+			inspect(item, f)
+		}
+	case *ast.FunctionOrProcedure:
+		for _, p := range v.Params {
+			inspect(p, f)
+		}
+		inspect(v.Body, f)
+	case ast.ParamStatement:
+		inspect(v.Default, f)
+	case ast.AssignmentStatement:
+		inspect(v.Var, f)
+		inspect(v.Expr, f)
+	case ast.ExprStatements:
+		inspect(v.Statements, f)
+	case *ast.ExpStatement:
+		inspect(v.Left, f)
+		inspect(v.Right, f)
+	case *ast.IfStatement:
+		inspect(v.Expression, f)
+		inspect(v.TrueBlock, f)
+		for _, item := range v.IfElseBlock {
+			inspect(item, f)
+		}
+		inspect(v.ElseBlock, f)
+	case ast.TryStatement:
+		inspect(v.Body, f)
+		inspect(v.Catch, f)
+	case ast.ThrowStatement:
+		inspect(v.Param, f)
+	case *ast.ReturnStatement:
+		inspect(v.Param, f)
+	case ast.NewObjectStatement:
+		inspect(v.Param, f)
+	case ast.CallChainStatement:
+		inspect(v.Call, f)
+		inspect(v.Unit, f)
+	case ast.MethodStatement:
+		inspect(v.Param, f)
+		inspect(v.Embedded, f)
+	case *ast.LoopStatement:
+		if _, ok := v.For.(string); !ok {
+			inspect(v.For, f)
+		}
+		inspect(v.In, f)
+		inspect(v.To, f)
+		inspect(v.WhileExpr, f)
+		inspect(v.Body, f)
+	case ast.TernaryStatement:
+		inspect(v.Expression, f)
+		inspect(v.TrueBlock, f)
+		inspect(v.ElseBlock, f)
+	case ast.ItemStatement:
+		inspect(v.Object, f)
+		inspect(v.Item, f)
+	}
+}
+
+// rewrite обходит узел как inspect и заменяет каждый узел результатом f, вложенные узлы раньше своего родителя.
+// Списки операторов (тело метода, ветки, цикла, Попытки) тоже передаются в f и могут поменять длину, в списке
+// аргументов f получает только сами аргументы. Списки и узлы-указатели меняются на месте, поэтому переписывать
+// можно только дерево, разобранное заново для исправления
+func rewrite(stm ast.Statement, f func(ast.Statement) ast.Statement) ast.Statement {
+	switch v := stm.(type) {
+	case ast.Statements:
+		for i := range v {
+			v[i] = rewrite(v[i], f)
+		}
+	case *ast.FunctionOrProcedure:
+		for i, p := range v.Params {
+			p.Default = rewrite(p.Default, f)
+			v.Params[i] = p
+		}
+		v.Body = rewriteList(v.Body, f)
+	case ast.AssignmentStatement:
+		v.Var = rewrite(v.Var, f)
+		v.Expr = rewriteExpr(v.Expr, f)
+		stm = v
+	case ast.ExprStatements:
+		stm = rewriteExpr(v, f)
+	case *ast.ExpStatement:
+		v.Left = rewrite(v.Left, f)
+		v.Right = rewrite(v.Right, f)
+	case *ast.IfStatement:
+		v.Expression = rewrite(v.Expression, f)
+		v.TrueBlock = rewriteList(v.TrueBlock, f)
+		v.IfElseBlock = rewriteList(v.IfElseBlock, f)
+		v.ElseBlock = rewriteList(v.ElseBlock, f)
+	case ast.TryStatement:
+		v.Body = rewriteList(v.Body, f)
+		v.Catch = rewriteList(v.Catch, f)
+		stm = v
+	case ast.ThrowStatement:
+		v.Param = rewrite(v.Param, f)
+		stm = v
+	case *ast.ReturnStatement:
+		v.Param = rewrite(v.Param, f)
+	case ast.NewObjectStatement:
+		v.Param = rewriteExpr(v.Param, f)
+		stm = v
+	case ast.CallChainStatement:
+		v.Call = rewrite(v.Call, f)
+		v.Unit = rewrite(v.Unit, f)
+		stm = v
+	case ast.MethodStatement:
+		v.Param = rewriteExpr(v.Param, f)
+		v.Embedded = rewriteList(v.Embedded, f)
+		stm = v
+	case *ast.LoopStatement:
+		v.For = rewrite(v.For, f)
+		v.In = rewrite(v.In, f)
+		v.To = rewrite(v.To, f)
+		v.WhileExpr = rewrite(v.WhileExpr, f)
+		v.Body = rewriteList(v.Body, f)
+	case ast.TernaryStatement:
+		v.Expression = rewrite(v.Expression, f)
+		v.TrueBlock = rewrite(v.TrueBlock, f)
+		v.ElseBlock = rewrite(v.ElseBlock, f)
+		stm = v
+	case ast.ItemStatement:
+		v.Object = rewrite(v.Object, f)
+		v.Item = rewrite(v.Item, f)
+		stm = v
+	}
+
+	if stm == nil {
+		return nil
+	}
+	return f(stm)
+}
+
+func rewriteList(items ast.Statements, f func(ast.Statement) ast.Statement) ast.Statements {
+	if items == nil {
+		return nil
+	}
+
+	return rewrite(items, f).(ast.Statements)
+}
+
+// rewriteExpr переписывает аргументы или части выражения, сам список в f не передается
+func rewriteExpr(expr ast.ExprStatements, f func(ast.Statement) ast.Statement) ast.ExprStatements {
+	for i := range expr.Statements {
+		expr.Statements[i] = rewrite(expr.Statements[i], f)
+	}
+
+	return expr
+}
+
+// methods возвращает процедуры и функции модуля
+func methods(module *ast.ModuleStatement) []*ast.FunctionOrProcedure {
+	var result []*ast.FunctionOrProcedure
+	for _, item := range module.Body {
+		if pf, ok := item.(*ast.FunctionOrProcedure); ok {
+			result = append(result, pf)
+		}
+	}
+
+	return result
+}
+
+// hasCalls проверяет, есть ли в выражении вызовы методов, которые могут иметь побочные эффекты
+func hasCalls(stm ast.Statement) bool {
+	found := false
+	inspect(stm, func(item ast.Statement) bool {
+		if _, ok := item.(ast.MethodStatement); ok {
+			found = true
+		}
+		return !found
+	})
+
+	return found
+}
+
+// isCall проверяет, что выражение - вызов метода: Метод() или Объект.Метод()
+func isCall(stm ast.Statement) bool {
+	switch v := stm.(type) {
+	case ast.MethodStatement:
+		return true
+	case ast.CallChainStatement:
+		return isCall(v.Unit)
+	default:
+		return false
+	}
+}
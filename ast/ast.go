@@ -4,7 +4,6 @@ package ast
 //go:generate goyacc  .\grammar.y
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
 	"reflect"
@@ -21,6 +20,15 @@ type AstNode struct {
 	isTry        atomic.Int32
 	isFunction   bool
 	mode         int // StmtStart или Expr
+
+	// концы двух последних прочитанных токенов, из них берется конец разобранного правила
+	lastEnd, prevEnd Position
+
+	warnings []*ParseError // ошибки разбора строк Выполнить и Вычислить
+
+	// ошибки разбора строк Вычислить попадают в warnings после разбора модуля, если в нем нет своего метода Вычислить
+	evalWarnings []*ParseError
+	embedded     bool // код строки Выполнить или Вычислить, методы модуля решает разбор модуля
 }
 
 const EOF = -1 // end of file
@@ -40,14 +48,18 @@ func (ast *AstNode) Parse() error {
 	}
 
 	yyParse(ast)
+	if !ast.embedded {
+		ast.resolveEval()
+	}
 	if ast.err != nil {
 		errors.Wrap(ast.err, "parse error")
 	}
 	return ast.err
 }
 
+// JSON сериализует модуль в формате ToJSON
 func (ast *AstNode) JSON() ([]byte, error) {
-	return json.Marshal(&ast.ModuleStatement)
+	return ToJSON(&ast.ModuleStatement)
 }
 
 func (ast *AstNode) Lex(lval *yySymType) int {
@@ -65,18 +77,36 @@ func (ast *AstNode) Lex(lval *yySymType) int {
 	}
 
 	ast.currentToken = lval.token
+	ast.prevEnd, ast.lastEnd = ast.lastEnd, lval.token.currentPosition()
+	lval.pos = lval.token.position
 	return token
 }
 
+func (ast *AstNode) addComment(comment Comment) {
+	ast.ModuleStatement.Comments = append(ast.ModuleStatement.Comments, comment)
+}
+
 func (ast *AstNode) SrsCode() string {
 	return ast.code
 }
 
+// ParseError синтаксическая ошибка разбора. Literal - токен, на котором остановился разбор
+type ParseError struct {
+	Message string
+	Line    int
+	Column  int
+	Literal string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s. line: %d, column: %d (unexpected literal: %q)", e.Message, e.Line, e.Column, e.Literal)
+}
+
 func (ast *AstNode) Error(s string) {
 	pos := ast.currentToken.GetPosition()
 	pos.Column -= len([]rune(ast.currentToken.literal)) + 1
 
-	ast.err = fmt.Errorf("%s. line: %d, column: %d (unexpected literal: %q)", s, pos.Line, pos.Column, ast.currentToken.literal)
+	ast.err = &ParseError{Message: s, Line: pos.Line, Column: pos.Column, Literal: ast.currentToken.literal}
 }
 
 func checkLoopOperator(token Token, yylex yyLexer) {
@@ -129,7 +159,7 @@ func setTryFlag(flag bool, yylex yyLexer) {
 	}
 }
 
-func createFunctionOrProcedure(Type StatementType, directive Statement, name string, params []ParamStatement, export Statement, variables map[string]VarStatement, body Statements) *FunctionOrProcedure {
+func createFunctionOrProcedure(Type StatementType, pos, end Position, directive Statement, name string, params []ParamStatement, export Statement, variables map[string]VarStatement, body Statements) *FunctionOrProcedure {
 	result := &FunctionOrProcedure{
 		Type:              Type,
 		Name:              name,
@@ -137,6 +167,8 @@ func createFunctionOrProcedure(Type StatementType, directive Statement, name str
 		Export:            export != nil && !reflect.ValueOf(export).IsNil(),
 		Params:            params,
 		ExplicitVariables: variables,
+		Pos:               pos,
+		End:               end,
 	}
 
 	if d, ok := directive.([]*DirectiveStatement); ok && d != nil {
@@ -151,12 +183,25 @@ func appendVarStatements(existingVariables map[string]VarStatement, newVariables
 		if _, ok := existingVariables[v.literal]; ok {
 			return map[string]VarStatement{}, fmt.Errorf("%w: with the specified name %q", errVariableAlreadyDefined, v.literal)
 		} else {
-			existingVariables[v.literal] = VarStatement{Name: v.literal}
+			existingVariables[v.literal] = VarStatement{Name: v.literal, Pos: v.position, End: v.end()}
 		}
 	}
 	return existingVariables, nil
 }
 
+// nodeEnd возвращает конец последнего токена, вошедшего в разобранное правило.
+// lookahead - токен, который парсер прочитал наперед (yyrcvr.char, отрицательный если не читал), в правило он не входит
+func nodeEnd(yylex yyLexer, lookahead int) Position {
+	if ast, ok := yylex.(*AstNode); ok {
+		if lookahead >= 0 {
+			return ast.prevEnd
+		}
+		return ast.lastEnd
+	}
+
+	return Position{}
+}
+
 func unaryMinus(iv interface{}) interface{} {
 	switch v := iv.(type) {
 	case int:
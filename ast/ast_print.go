@@ -1,9 +1,16 @@
 package ast
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
 )
 
 type PrintConf struct {
@@ -11,13 +18,50 @@ type PrintConf struct {
 	Margin  int
 	OneLine bool
 
-	// автоматически расставить скобки в выражениях
-	//LispStyle bool
+	// LispStyle заключать в скобки каждое вложенное выражение, как было раньше.
+	// По умолчанию скобки ставятся только там, где этого требуют приоритет или ассоциативность операций
+	LispStyle bool
+
+	// ScriptVariant вариант встроенного языка (русский или английский) для ключевых слов
+	// и имен глобального контекста
+	ScriptVariant ScriptVariant
+}
+
+// SourceMapping связывает позицию в напечатанном коде с позицией узла в исходном коде
+type SourceMapping struct {
+	Generated Position
+	Original  Position
+}
+
+// SourceMap карта соответствия напечатанного кода исходному, записи отсортированы по позиции в напечатанном коде
+type SourceMap struct {
+	Mappings []SourceMapping
 }
 
 type astPrint struct {
-	ast  *AstNode
-	conf PrintConf
+	module       *ModuleStatement
+	node         Statement // узел, который печатается без модуля
+	conf         PrintConf
+	localMethods map[string]bool
+	resolution   *Resolution
+	out          *printWriter
+}
+
+// printWriter пишет напечатанный код и, если нужна карта исходного кода, считает текущую позицию
+type printWriter struct {
+	w         io.Writer
+	err       error
+	pos       Position
+	sourceMap *SourceMap
+}
+
+func newAstPrint(w io.Writer, conf PrintConf, module *ModuleStatement, withSourceMap bool) *astPrint {
+	out := &printWriter{w: w, pos: Position{Line: 1, Column: 1}}
+	if withSourceMap {
+		out.sourceMap = &SourceMap{}
+	}
+
+	return &astPrint{conf: conf, module: module, out: out}
 }
 
 func (ast *AstNode) Print(conf PrintConf) string {
@@ -25,8 +69,10 @@ func (ast *AstNode) Print(conf PrintConf) string {
 		return ""
 	}
 
-	p := &astPrint{conf: conf, ast: ast}
-	return p.print()
+	builder := &strings.Builder{}
+	newAstPrint(builder, conf, &ast.ModuleStatement, false).print()
+
+	return builder.String()
 }
 
 func (ast *AstNode) PrintStatement(stat Statement) string {
@@ -42,416 +88,714 @@ func (ast *AstNode) PrintStatementWithConf(stat Statement, conf PrintConf) strin
 		return ""
 	}
 
-	p := &astPrint{conf: conf}
+	builder := &strings.Builder{}
+	p := newAstPrint(builder, conf, nil, false)
+	p.node = stat
 
 	if pf, ok := stat.(*FunctionOrProcedure); ok {
-		return p.printFunctionOrProcedure(pf)
+		p.printFunctionOrProcedure(pf)
+	} else {
+		p.printBodyItem(stat, 0)
 	}
 
-	return p.printBodyItem(stat, 0)
+	return builder.String()
+}
+
+// Fprint печатает узел в w не собирая результат в памяти.
+// node может быть *AstNode, *ModuleStatement, *FunctionOrProcedure или любым оператором модуля
+func Fprint(w io.Writer, node Statement, conf PrintConf) error {
+	_, err := fprint(w, node, conf, false)
+	return err
 }
 
-func (p *astPrint) print() string {
-	if len(p.ast.ModuleStatement.Body) == 0 {
-		return ""
+// FprintWithSourceMap то же что Fprint, дополнительно возвращает карту соответствия напечатанного кода исходному.
+// По карте ошибки найденные в напечатанном коде можно отнести к узлам исходного модуля
+func FprintWithSourceMap(w io.Writer, node Statement, conf PrintConf) (*SourceMap, error) {
+	return fprint(w, node, conf, true)
+}
+
+func fprint(w io.Writer, node Statement, conf PrintConf, withSourceMap bool) (*SourceMap, error) {
+	buf := bufio.NewWriter(w)
+	p := newAstPrint(buf, conf, nil, withSourceMap)
+	p.node = node
+
+	switch v := node.(type) {
+	case nil:
+	case *AstNode:
+		p.module = &v.ModuleStatement
+		p.print()
+	case *ModuleStatement:
+		p.module = v
+		p.print()
+	case *FunctionOrProcedure:
+		p.printFunctionOrProcedure(v)
+	default:
+		p.printBodyItem(v, 0)
 	}
 
-	builder := &strings.Builder{}
+	if p.out.err != nil {
+		return nil, errors.Wrap(p.out.err, "print error")
+	}
+	if err := buf.Flush(); err != nil {
+		return nil, errors.Wrap(err, "print error")
+	}
+
+	return p.out.sourceMap, nil
+}
+
+// Original возвращает позицию в исходном коде узла, печать которого включает указанную позицию напечатанного кода
+func (m *SourceMap) Original(line, column int) (Position, bool) {
+	if m == nil {
+		return Position{}, false
+	}
+
+	i := sort.Search(len(m.Mappings), func(i int) bool {
+		g := m.Mappings[i].Generated
+		return g.Line > line || (g.Line == line && g.Column > column)
+	})
+	if i == 0 {
+		return Position{}, false
+	}
+
+	return m.Mappings[i-1].Original, true
+}
+
+func (w *printWriter) WriteString(s string) {
+	if w.err != nil {
+		return
+	}
+
+	if _, err := io.WriteString(w.w, s); err != nil {
+		w.err = err
+		return
+	}
+
+	// позиция нужна только для карты, без нее не тратим время на подсчет символов
+	if w.sourceMap == nil {
+		return
+	}
+
+	w.pos.Offset += len(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		w.pos.Line += strings.Count(s, "\n")
+		w.pos.Column = utf8.RuneCountInString(s[i+1:]) + 1
+	} else {
+		w.pos.Column += utf8.RuneCountInString(s)
+	}
+}
+
+func (p *astPrint) write(str ...string) {
+	for _, s := range str {
+		p.out.WriteString(s)
+	}
+}
+
+// mark запоминает, что с текущей позиции печатается узел из исходной позиции original
+func (p *astPrint) mark(original Position) {
+	sourceMap := p.out.sourceMap
+	if sourceMap == nil || original.Line == 0 {
+		return
+	}
+
+	// несколько узлов могут начинаться в одном месте (выражение и его левый операнд), оставляем внешний
+	if n := len(sourceMap.Mappings); n > 0 && sourceMap.Mappings[n-1].Generated == p.out.pos {
+		return
+	}
+
+	sourceMap.Mappings = append(sourceMap.Mappings, SourceMapping{Generated: p.out.pos, Original: original})
+}
+
+func (p *astPrint) print() {
+	if len(p.module.Body) == 0 {
+		return
+	}
 
-	for _, v := range p.ast.ModuleStatement.GlobalVariables {
-		builder.WriteString(p.printGlobalVariables(v))
-		builder.WriteString(p.newLine(1))
+	for _, v := range p.module.GlobalVariables {
+		p.printGlobalVariables(v)
+		p.newLine(1)
 	}
 
-	for _, node := range p.ast.ModuleStatement.Body {
+	for _, node := range p.module.Body {
 		if pf, ok := node.(*FunctionOrProcedure); ok {
-			builder.WriteString(p.printFunctionOrProcedure(pf))
-			builder.WriteString(p.newLine(3))
+			p.printFunctionOrProcedure(pf)
+			p.newLine(3)
 		} else {
-			builder.WriteString(p.newLine(1))
-			builder.WriteString(p.printBodyItem(node, 0))
+			p.newLine(1)
+			p.printBodyItem(node, 0)
 		}
 	}
-
-	return builder.String()
 }
 
-func (p *astPrint) printGlobalVariables(variables GlobalVariables) string {
-	builder := strings.Builder{}
+func (p *astPrint) printGlobalVariables(variables GlobalVariables) {
+	p.printDirective(variables.Directive)
+	p.write(p.kw("Перем "))
+	p.mark(variables.Var.Pos)
+	p.write(variables.Var.Name)
 
-	export := ""
 	if variables.Export {
-		export = " Экспорт "
+		p.write(p.kw(" Экспорт "))
 	}
 
-	builder.WriteString(printDirective(variables.Directive))
-	builder.WriteString("Перем ")
-	builder.WriteString(variables.Var.Name)
-	builder.WriteString(export)
-	builder.WriteString(";")
-
-	return builder.String()
+	p.write(";")
 }
 
-func (p *astPrint) printFunctionOrProcedure(pf *FunctionOrProcedure) (result string) {
-	builder := &strings.Builder{}
-	defer func() { result = builder.String() }()
-
-	declaration := ""
+func (p *astPrint) printFunctionOrProcedure(pf *FunctionOrProcedure) {
+	declaration, end := "", ""
 	if pf.Type == PFTypeFunction {
-		declaration = "Функция"
-		defer func() { builder.WriteString("КонецФункции ") }()
+		declaration, end = p.kw("Функция"), p.kw("КонецФункции ")
 	} else if pf.Type == PFTypeProcedure {
-		declaration = "Процедура"
-		defer func() { builder.WriteString("КонецПроцедуры ") }()
+		declaration, end = p.kw("Процедура"), p.kw("КонецПроцедуры ")
 	}
 
-	var params []string
-	// buildParam := strings.Builder{}
-	for _, param := range pf.Params {
-		val, def := "", ""
-		if param.IsValue {
-			val = "Знач "
+	for _, d := range pf.Directives {
+		p.printDirective(d)
+	}
+
+	depth := 1
+
+	p.mark(pf.Pos)
+	p.write(declaration, " ", pf.Name, "(")
+	for i, param := range pf.Params {
+		if i > 0 {
+			p.write(", ")
 		}
 
-		if asText := p.printVarStatement(param.Default); asText != "" {
-			def = " = " + asText
+		if param.IsValue {
+			p.write(p.kw("Знач "))
 		}
+		p.mark(param.Pos)
+		p.write(param.Name)
 
-		params = append(params, val+param.Name+def)
+		// метки и прочие узлы, у которых нет текстового значения, не печатаем
+		if _, ok := param.Default.(*GoToLabelStatement); param.Default != nil && !ok {
+			p.write(" = ")
+			p.printVarStatement(param.Default)
+		}
 	}
+	p.write(")", " ")
 
-	export := ""
 	if pf.Export {
-		export = "Экспорт "
+		p.write(p.kw("Экспорт "))
 	}
 
-	for _, d := range pf.Directives {
-		builder.WriteString(printDirective(d))
+	p.newLine(1)
+	p.printExplicitVariables(pf.ExplicitVariables, depth)
+	p.printBody(pf.Body, depth)
+	p.write(end)
+}
+
+func (p *astPrint) printExplicitVariables(variables map[string]VarStatement, depth int) {
+	if len(variables) == 0 {
+		return
 	}
 
-	depth := 1
+	// порядок объявления в карте не сохраняется, сортируем что бы вывод был детерминированным
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p.write(strings.Repeat(" ", p.conf.Margin*depth), p.kw("Перем "))
+	for i, name := range names {
+		if i > 0 {
+			p.write(", ")
+		}
 
-	builder.WriteString(declaration)
-	builder.WriteString(" ")
-	builder.WriteString(pf.Name)
-	builder.WriteString("(")
-	builder.WriteString(strings.Join(params, ", "))
-	builder.WriteString(")")
-	builder.WriteString(" ")
-	builder.WriteString(export)
-	builder.WriteString(p.newLine(1))
-	builder.WriteString(p.printBody(pf.Body, depth))
+		p.mark(variables[name].Pos)
+		p.write(name)
+	}
 
-	return
+	p.write(";")
+	p.newLine(1)
 }
 
-func printDirective(directive *DirectiveStatement) string {
+func (p *astPrint) printDirective(directive *DirectiveStatement) {
 	if directive == nil {
-		return ""
+		return
 	}
 
+	p.mark(directive.Pos)
+	p.write(directiveNames.translate(directive.Name, p.conf.ScriptVariant))
 	if directive.Src != "" {
-		return directive.Name + "(" + directive.Src + ")" + "\n"
+		p.write("(", directive.Src, ")")
 	}
 
-	return directive.Name + "\n"
+	p.write("\n")
 }
 
-func (p *astPrint) printVarStatement(v Statement) string {
+func (p *astPrint) printVarStatement(v Statement) {
+	p.mark(PositionOf(v))
+
 	switch val := v.(type) {
-	case float64, float32:
-		return fmt.Sprintf("%.0f", val)
+	case float64:
+		p.write(strconv.FormatFloat(val, 'f', -1, 64))
+	case float32:
+		p.write(strconv.FormatFloat(float64(val), 'f', -1, 32))
 	case int, int64, int32:
-		return fmt.Sprintf("%d", val)
+		p.write(fmt.Sprintf("%d", val))
 	case string:
-		return fmt.Sprintf("\"%s\"", val)
+		p.write("\"", val, "\"")
 	case bool:
-		return IF[string](val, "Истина", "Ложь")
+		p.write(IF[string](val, p.kw("Истина"), p.kw("Ложь")))
 	case time.Time:
-		return fmt.Sprintf(`'%s'`, val.Format("20060102150405"))
+		p.write("'", val.Format("20060102150405"), "'")
 	case CallChainStatement:
-		not := IF[string](val.not, "Не ", "")
-		return not + p.printCallChainStatement(val)
+		p.write(IF[string](val.not, p.kw("Не "), ""), IF[string](val.unaryMinus, "-", ""))
+		p.printCallChainStatement(val)
 	case UndefinedStatement:
-		return "Неопределено"
+		p.write(p.kw("Неопределено"))
 	case MethodStatement:
-		not := IF[string](val.not, "Не ", "")
-		return not + val.Name + "(" + p.printParams(val.Param.Statements) + ")"
+		p.write(IF[string](val.not, p.kw("Не "), ""), p.functionName(val.Name), "(")
+		p.printParams(val.Param.Statements)
+		p.write(")")
 	case VarStatement:
-		return val.Name
+		p.write(val.Name)
 	case ItemStatement:
-		return p.printVarStatement(val.Object) + "[" + p.printExpression(val.Item, 0) + "]"
+		p.printVarStatement(val.Object)
+		p.write("[")
+		p.printExpression(val.Item, 0)
+		p.write("]")
 	case TernaryStatement:
-		return fmt.Sprintf("?(%s, %s, %s)", p.printExpression(val.Expression, 0), p.printExpression(val.TrueBlock, 0), p.printExpression(val.ElseBlock, 0))
+		p.write("?(")
+		p.printExpression(val.Expression, 0)
+		p.write(", ")
+		p.printExpression(val.TrueBlock, 0)
+		p.write(", ")
+		p.printExpression(val.ElseBlock, 0)
+		p.write(")")
 	case NewObjectStatement:
-		return fmt.Sprintf("Новый %s(%s)", val.Constructor, p.printParams(val.Param.Statements))
+		p.write(p.kw("Новый "), p.constructorName(val.Constructor))
+		if val.Param.Statements != nil {
+			p.write("(")
+			p.printParams(val.Param.Statements)
+			p.write(")")
+		}
 	case AssignmentStatement:
-		return fmt.Sprintf("%s = %s", p.printVarStatement(val.Var), p.printExpression(val.Expr, 0))
+		p.printVarStatement(val.Var)
+		p.write(" = ")
+		p.printParams(val.Expr.Statements)
 	case ExpStatement, ExprStatements, *ExpStatement, *ExprStatements:
-		return p.printExpression(val, 0)
-	default:
-		return ""
+		p.printExpression(val, 0)
 	}
 }
 
-func (p *astPrint) printParams(Params Statements) string {
-	params := make([]string, len(Params), len(Params))
-	for i, parm := range Params {
-		params[i] = p.printVarStatement(parm)
-	}
+func (p *astPrint) printParams(params Statements) {
+	for i, param := range params {
+		if i > 0 {
+			p.write(", ")
+		}
 
-	return strings.Join(params, ", ")
+		p.printExpression(param, 0)
+	}
 }
 
-func (p *astPrint) printBody(items Statements, depth int) string {
-	builder := &strings.Builder{}
-
+func (p *astPrint) printBody(items Statements, depth int) {
 	for _, item := range items {
-		builder.WriteString(p.printBodyItem(item, depth))
+		p.printBodyItem(item, depth)
 	}
-
-	return builder.String()
 }
 
-func (p *astPrint) printBodyItem(item Statement, depth int) string {
-	builder := &strings.Builder{}
+func (p *astPrint) printBodyItem(item Statement, depth int) {
+	p.write(strings.Repeat(" ", p.conf.Margin*depth))
+	p.printStatement(item, depth)
 
-	spaces := strings.Repeat(" ", p.conf.Margin*depth)
-	builder.WriteString(spaces)
+	switch item.(type) {
+	case *GoToLabelStatement:
+		// после метки идет двоеточие и оператор на той же строке
+		p.write(":")
+	default:
+		p.write(";")
+		p.newLine(1)
+	}
+}
+
+// printStatement печатает оператор без отступа перед ним и разделителя после
+func (p *astPrint) printStatement(item Statement, depth int) {
+	p.mark(PositionOf(item))
 
 	switch v := item.(type) {
 	case *IfStatement:
-		builder.WriteString(p.printIfStatement(v, depth))
+		p.printIfStatement(v, depth)
 	case *ExpStatement:
-		builder.WriteString(p.printExpression(v, 0))
+		p.printExpression(v, 0)
 	case *LoopStatement:
-		builder.WriteString(p.printLoopStatement(v, depth))
+		p.printLoopStatement(v, depth)
 	case BreakStatement:
-		builder.WriteString("Прервать")
+		p.write(p.kw("Прервать"))
 	case ContinueStatement:
-		builder.WriteString("Продолжить")
+		p.write(p.kw("Продолжить"))
 	case CallChainStatement:
-		builder.WriteString(p.printCallChainStatement(v))
+		p.printCallChainStatement(v)
 	case TryStatement:
-		builder.WriteString(p.printTryStatement(v, depth))
+		p.printTryStatement(v, depth)
 	case ThrowStatement:
-		builder.WriteString("ВызватьИсключение")
+		p.write(p.kw("ВызватьИсключение"))
 		if v.Param != nil {
 			if param, ok := v.Param.(ExprStatements); ok {
-				builder.WriteString("(" + p.printParams(param.Statements) + ")")
+				p.write("(")
+				p.printParams(param.Statements)
+				p.write(")")
 			} else {
-				builder.WriteString("(" + p.printParams(Statements{v.Param}) + ")")
+				p.write(" ")
+				p.printExpression(v.Param, 0)
 			}
 		}
 	case *ReturnStatement:
-		builder.WriteString("Возврат")
+		p.write(p.kw("Возврат"))
 		if v.Param != nil {
-			builder.WriteString(" ")
-			builder.WriteString(p.printExpression(v.Param, 0))
+			p.write(" ")
+			p.printExpression(v.Param, 0)
 		}
-	case GoToStatement, *GoToLabelStatement:
-		builder.WriteString(p.printGoTo(v, depth))
-		return builder.String()
+	case *GoToLabelStatement:
+		p.write("~", v.Name)
+	case GoToStatement:
+		p.write(p.kw("Перейти "), "~", v.Label.Name)
 	default:
-		builder.WriteString(p.printVarStatement(v))
+		p.printVarStatement(v)
 	}
-
-	builder.WriteString(";")
-	builder.WriteString(p.newLine(1))
-
-	return builder.String()
 }
 
-func (p *astPrint) printIfStatement(expr *IfStatement, depth int) string {
-	builder := &strings.Builder{}
-
+func (p *astPrint) printIfStatement(expr *IfStatement, depth int) {
 	spaces := strings.Repeat(" ", p.conf.Margin*depth)
-	builder.WriteString("Если ")
-	builder.WriteString(p.printExpression(expr.Expression, 0))
-	builder.WriteString(" Тогда ")
-	builder.WriteString(p.newLine(1))
-	builder.WriteString(p.printBody(expr.TrueBlock, depth+1))
+	p.write(p.kw("Если "))
+	p.printExpression(expr.Expression, 0)
+	p.write(p.kw(" Тогда "))
+	p.newLine(1)
+	p.printBody(expr.TrueBlock, depth+1)
 
 	for _, item := range expr.IfElseBlock {
-		builder.WriteString(spaces)
-		builder.WriteString("ИначеЕсли ")
-		builder.WriteString(p.printExpression(item.(*IfStatement).Expression, 0))
-		builder.WriteString(" Тогда ")
-		builder.WriteString(p.newLine(1))
-		builder.WriteString(p.printBody(item.(*IfStatement).TrueBlock, depth+1))
+		elseIf := item.(*IfStatement)
+
+		p.write(spaces)
+		p.mark(elseIf.Pos)
+		p.write(p.kw("ИначеЕсли "))
+		p.printExpression(elseIf.Expression, 0)
+		p.write(p.kw(" Тогда "))
+		p.newLine(1)
+		p.printBody(elseIf.TrueBlock, depth+1)
 	}
 
 	if expr.ElseBlock != nil {
-		builder.WriteString(spaces)
-		builder.WriteString("Иначе ")
-		builder.WriteString(p.newLine(1))
-		builder.WriteString(p.printBody(expr.ElseBlock, depth+1))
+		p.write(spaces, p.kw("Иначе "))
+		p.newLine(1)
+		p.printBody(expr.ElseBlock, depth+1)
 	}
 
-	builder.WriteString(spaces)
-	builder.WriteString("КонецЕсли")
-	return builder.String()
+	p.write(spaces, p.kw("КонецЕсли"))
 }
 
-func (p *astPrint) printLoopStatement(loop *LoopStatement, depth int) string {
-	builder := &strings.Builder{}
-
-	spaces := strings.Repeat(" ", p.conf.Margin*depth)
+func (p *astPrint) printLoopStatement(loop *LoopStatement, depth int) {
 	if loop.WhileExpr != nil {
-		builder.WriteString("Пока ")
-		builder.WriteString(p.printExpression(loop.WhileExpr, 0))
-		builder.WriteString(" Цикл ")
+		p.write(p.kw("Пока "))
+		p.printExpression(loop.WhileExpr, 0)
+		p.write(p.kw(" Цикл "))
 	} else {
-		builder.WriteString("Для ")
+		p.write(p.kw("Для "))
 	}
 
 	if loop.In != nil {
-		builder.WriteString("Каждого ")
-		builder.WriteString(loop.For.(string))
-		builder.WriteString(" Из ")
-		builder.WriteString(p.printExpression(loop.In, 0))
-		builder.WriteString(" Цикл ")
+		p.write(p.kw("Каждого "), loop.For.(string), p.kw(" Из "))
+		p.printExpression(loop.In, 0)
+		p.write(p.kw(" Цикл "))
 	}
 	if loop.To != nil {
-		builder.WriteString(p.printExpression(loop.For, 0))
-		builder.WriteString(" По ")
-		builder.WriteString(p.printExpression(loop.To, 0))
-		builder.WriteString(" Цикл ")
+		p.printExpression(loop.For, 0)
+		p.write(p.kw(" По "))
+		p.printExpression(loop.To, 0)
+		p.write(p.kw(" Цикл "))
 	}
 
-	builder.WriteString(p.newLine(1))
-	builder.WriteString(p.printBody(loop.Body, depth+1))
-	builder.WriteString(spaces)
-	builder.WriteString("КонецЦикла")
-
-	return builder.String()
+	p.newLine(1)
+	p.printBody(loop.Body, depth+1)
+	p.write(strings.Repeat(" ", p.conf.Margin*depth), p.kw("КонецЦикла"))
 }
 
-func (p *astPrint) printExpression(expr Statement, level int) string {
-	builder := &strings.Builder{}
+func (p *astPrint) printExpression(expr Statement, level int) {
+	if !p.conf.LispStyle {
+		p.printMinimalExpression(expr, precLowest)
+		return
+	}
 
 	switch v := expr.(type) {
 	case ExprStatements:
-		if v.not {
-			builder.WriteString("Не ")
-			builder.WriteString("(")
+		if v.not || v.unaryMinus {
+			p.write(IF[string](v.not, p.kw("Не "), ""), IF[string](v.unaryMinus, "-", ""), "(")
 		}
 
 		for _, s := range v.Statements {
-			builder.WriteString(p.printExpression(s, IF(level == 0, 0, level+1)))
+			p.printExpression(s, IF(level == 0, 0, level+1))
 		}
 
-		if v.not {
-			builder.WriteString(")")
+		if v.not || v.unaryMinus {
+			p.write(")")
 		}
 	case *ExpStatement:
+		p.mark(v.Pos)
 		if level > 0 {
-			builder.WriteString("(")
+			p.write("(")
 		}
 
 		if v.not {
-			builder.WriteString("Не ")
+			p.write(p.kw("Не "))
 		}
 		if v.unaryMinus {
-			builder.WriteString("-")
+			p.write("-")
 		}
 
 		if v.unaryMinus || v.not {
-			builder.WriteString("(")
+			p.write("(")
 		}
 
-		builder.WriteString(p.printExpression(v.Left, level+1))
-		builder.WriteString(" ")
-		builder.WriteString(v.Operation.String())
-		builder.WriteString(" ")
-		builder.WriteString(p.printExpression(v.Right, level+1))
+		p.printExpression(v.Left, level+1)
+		p.write(" ", p.kw(v.Operation.String()), " ")
+		p.printExpression(v.Right, level+1)
 
 		if v.unaryMinus || v.not {
-			builder.WriteString(")")
+			p.write(")")
 		}
 
 		if level > 0 {
-			builder.WriteString(")")
+			p.write(")")
 		}
 	case VarStatement:
+		p.mark(v.Pos)
 		if v.not {
-			builder.WriteString("Не ")
+			p.write(p.kw("Не "))
 		}
 		if v.unaryMinus {
-			builder.WriteString("-")
+			p.write("-")
 		}
-		builder.WriteString(p.printVarStatement(v))
+		p.printVarStatement(v)
 	default:
-		builder.WriteString(p.printVarStatement(v))
+		p.printVarStatement(v)
 	}
-
-	return builder.String()
 }
 
-func (p *astPrint) printCallChainStatement(call Statement) string {
+func (p *astPrint) printCallChainStatement(call Statement) {
 	switch v := call.(type) {
 	case CallChainStatement:
 		if v.Call != nil {
-			return p.printCallChainStatement(v.Call) + "." + p.printVarStatement(v.Unit)
+			p.printCallChainStatement(v.Call)
+			p.write(".")
+			p.printMember(v.Unit)
+		}
+	case VarStatement:
+		// начало цепочки может быть свойством глобального контекста (Справочники.Валюты)
+		p.mark(v.Pos)
+		name := globalProperties.translate(v.Name, p.conf.ScriptVariant)
+		if name != v.Name && !p.globalProperty(v) {
+			name = v.Name
 		}
-	case VarStatement, ItemStatement, MethodStatement:
-		return p.printVarStatement(call)
+		p.write(name)
+	case ItemStatement, MethodStatement:
+		p.printVarStatement(call)
+	}
+}
+
+// globalProperty проверяет, что имя в начале цепочки - свойство глобального контекста, а не переменная
+// или параметр с таким же именем
+func (p *astPrint) globalProperty(v VarStatement) bool {
+	if v.Pos == (Position{}) {
+		// узел создан программно, сопоставить его с объявлением нельзя
+		return true
+	}
+
+	if p.resolution == nil {
+		module := p.module
+		if module == nil {
+			module = &ModuleStatement{Body: Statements{p.node}}
+		}
+		p.resolution = Resolve(module)
 	}
 
-	return ""
+	sym := p.resolution.SymbolOf(v)
+	return sym != nil && sym.Kind == SymbolGlobal
 }
 
-func (p *astPrint) printTryStatement(try TryStatement, depth int) string {
-	builder := &strings.Builder{}
+// printMember печатает обращение после точки, имена членов объектов не переводятся
+func (p *astPrint) printMember(unit Statement) {
+	switch v := unit.(type) {
+	case MethodStatement:
+		p.mark(v.Pos)
+		p.write(v.Name, "(")
+		p.printParams(v.Param.Statements)
+		p.write(")")
+	case VarStatement:
+		p.mark(v.Pos)
+		p.write(v.Name)
+	case ItemStatement:
+		p.printMember(v.Object)
+		p.write("[")
+		p.printExpression(v.Item, 0)
+		p.write("]")
+	default:
+		p.printVarStatement(unit)
+	}
+}
+
+// kw возвращает ключевое слово в выбранном варианте языка, пробелы вокруг слова сохраняются
+func (p *astPrint) kw(word string) string {
+	if p.conf.ScriptVariant != ScriptVariantEnglish {
+		return word
+	}
 
+	trimmed := strings.TrimSpace(word)
+	if en, ok := englishKeywords[trimmed]; ok {
+		return strings.Replace(word, trimmed, en, 1)
+	}
+
+	return word
+}
+
+// functionName переводит имя функции глобального контекста, методы объявленные в модуле не трогаем
+func (p *astPrint) functionName(name string) string {
+	if p.module != nil {
+		if p.localMethods == nil {
+			p.localMethods = map[string]bool{}
+			for _, item := range p.module.Body {
+				if pf, ok := item.(*FunctionOrProcedure); ok {
+					p.localMethods[fastToLower(pf.Name)] = true
+				}
+			}
+		}
+
+		if p.localMethods[fastToLower(name)] {
+			return name
+		}
+	}
+
+	return globalFunctions.translate(name, p.conf.ScriptVariant)
+}
+
+func (p *astPrint) constructorName(name string) string {
+	return globalTypes.translate(name, p.conf.ScriptVariant)
+}
+
+func (p *astPrint) printTryStatement(try TryStatement, depth int) {
 	spaces := strings.Repeat(" ", p.conf.Margin*depth)
-	builder.WriteString("Попытка")
-	builder.WriteString(p.newLine(1))
+	p.write(p.kw("Попытка "))
+	p.newLine(1)
 
 	if try.Body != nil {
-		builder.WriteString(p.printBody(try.Body, depth+1))
+		p.printBody(try.Body, depth+1)
 	}
 
-	builder.WriteString(spaces)
-	builder.WriteString("Исключение")
-	builder.WriteString(p.newLine(1))
+	p.write(spaces, p.kw("Исключение "))
+	p.newLine(1)
 
 	if try.Catch != nil {
-		builder.WriteString(p.printBody(try.Catch, depth+1))
+		p.printBody(try.Catch, depth+1)
 	}
 
-	builder.WriteString(spaces)
-	builder.WriteString("КонецПопытки")
-	return builder.String()
+	p.write(spaces, p.kw("КонецПопытки"))
 }
 
-func (p *astPrint) printGoTo(gotoStat Statement, depth int) string {
-	builder := strings.Builder{}
+func (p *astPrint) newLine(count int) {
+	if p.conf.OneLine {
+		return
+	}
 
-	// spaces := strings.Repeat(" ", p.conf.Margin*depth)
+	p.write(strings.Repeat("\n", count))
+}
 
-	switch v := gotoStat.(type) {
-	case *GoToLabelStatement:
-		// builder.WriteString(spaces)
-		builder.WriteString("~")
-		builder.WriteString(v.Name)
-		builder.WriteString(":")
-	case GoToStatement:
-		// builder.WriteString(spaces)
-		builder.WriteString("Перейти ")
-		builder.WriteString("~")
-		builder.WriteString(v.Label.Name)
-		builder.WriteString(";")
+// printMinimalExpression печатает выражение расставляя скобки только там, где без них дерево разобралось бы иначе.
+// follow - приоритет операции, которая будет напечатана сразу после выражения (precLowest если такой нет)
+func (p *astPrint) printMinimalExpression(expr Statement, follow int) {
+	switch v := expr.(type) {
+	case ExprStatements:
+		// скобки из исходного кода сохраняем, иначе при повторном разборе пропадет узел ExprStatements
+		p.write(IF[string](v.not, p.kw("Не "), ""), IF[string](v.unaryMinus, "-", ""), "(")
+		for i, s := range v.Statements {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.printMinimalExpression(s, precLowest)
+		}
+		p.write(")")
+	case *ExpStatement:
+		p.mark(v.Pos)
+		switch {
+		case v.unaryMinus:
+			p.write(IF[string](v.not, p.kw("Не "), ""), "-(")
+			p.printBinary(v, precLowest)
+			p.write(")")
+		case v.not && v.Operation.precedence() > precNot:
+			p.write(p.kw("Не "))
+			p.printBinary(v, follow)
+		case v.not:
+			p.write(p.kw("Не "), "(")
+			p.printBinary(v, precLowest)
+			p.write(")")
+		default:
+			p.printBinary(v, follow)
+		}
+	case VarStatement:
+		p.mark(v.Pos)
+		p.write(IF[string](v.not, p.kw("Не "), ""), IF[string](v.unaryMinus, "-", ""), v.Name)
+	default:
+		p.printVarStatement(v)
 	}
+}
 
-	return builder.String()
+func (p *astPrint) printBinary(expr *ExpStatement, follow int) {
+	prec := expr.Operation.precedence()
+
+	// все операции левоассоциативные, поэтому правому операнду с тем же приоритетом нужны скобки
+	p.printOperand(expr.Left, prec, prec)
+	p.write(" ", p.kw(expr.Operation.String()), " ")
+	p.printOperand(expr.Right, prec+1, follow)
 }
 
-func (p *astPrint) newLine(count int) string {
-	if p.conf.OneLine {
-		return ""
+func (p *astPrint) printOperand(expr Statement, minPrec, follow int) {
+	prec := expressionPrecedence(expr)
+
+	wrap := prec < minPrec
+	if prec == precNot || prec == precUnary {
+		// у префиксной операции левая граница всегда однозначна, но она захватывает
+		// все последующие операции с более высоким приоритетом
+		wrap = follow > prec
 	}
 
-	return strings.Repeat("\n", count)
+	if wrap {
+		p.write("(")
+		p.printMinimalExpression(expr, precLowest)
+		p.write(")")
+		return
+	}
+
+	p.printMinimalExpression(expr, follow)
+}
+
+func expressionPrecedence(expr Statement) int {
+	switch v := expr.(type) {
+	case *ExpStatement:
+		if v.not {
+			return precNot
+		} else if v.unaryMinus {
+			return precUnary
+		}
+		return v.Operation.precedence()
+	case ExprStatements:
+		if v.not {
+			return precNot
+		}
+		return IF[int](v.unaryMinus, precUnary, precAtom)
+	case VarStatement:
+		if v.not {
+			return precNot
+		}
+		return IF[int](v.unaryMinus, precUnary, precAtom)
+	case CallChainStatement:
+		if v.not {
+			return precNot
+		}
+		return IF[int](v.unaryMinus, precUnary, precAtom)
+	case MethodStatement:
+		return IF[int](v.not, precNot, precAtom)
+	case float64:
+		return IF[int](v < 0, precUnary, precAtom)
+	default:
+		return precAtom
+	}
 }
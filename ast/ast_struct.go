@@ -47,6 +47,8 @@ type Statements []Statement
 type AssignmentStatement struct {
 	Var  Statement
 	Expr ExprStatements
+	Pos  Position `json:"-"`
+	End  Position `json:"-"`
 }
 
 type ExprStatements struct {
@@ -64,16 +66,28 @@ type ModuleStatement struct {
 	Name            string
 	GlobalVariables map[string]GlobalVariables `json:"GlobalVariables,omitempty"`
 	Body            Statements
+	Comments        []Comment `json:"Comments,omitempty"` // комментарии модуля в порядке следования
+}
+
+// Comment однострочный комментарий, Text включает начальные //
+type Comment struct {
+	Text string
+	Pos  Position `json:"-"`
+	End  Position `json:"-"`
 }
 
 type VarStatement struct {
 	Name string
 	addStatementField
+	Pos Position `json:"-"`
+	End Position `json:"-"`
 }
 
 type DirectiveStatement struct {
 	Name string
-	Src  string // для директив расширений которые переопределяют исходную функцию
+	Src  string   // для директив расширений которые переопределяют исходную функцию
+	Pos  Position `json:"-"`
+	End  Position `json:"-"`
 }
 
 type FunctionOrProcedure struct {
@@ -84,12 +98,16 @@ type FunctionOrProcedure struct {
 	Params            []ParamStatement
 	Type              StatementType
 	Export            bool
+	Pos               Position `json:"-"`
+	End               Position `json:"-"`
 }
 
 type ParamStatement struct {
 	Default Statement `json:"Default,omitempty"`
 	Name    string
-	IsValue bool `json:"IsValue,omitempty"`
+	IsValue bool     `json:"IsValue,omitempty"`
+	Pos     Position `json:"-"`
+	End     Position `json:"-"`
 }
 
 type addStatementField struct {
@@ -103,6 +121,8 @@ type ExpStatement struct {
 	Right     interface{}
 	Operation OperationType
 	addStatementField
+	Pos Position `json:"-"`
+	End Position `json:"-"`
 }
 
 // type IfElseStatement struct {
@@ -115,44 +135,65 @@ type IfStatement struct {
 	TrueBlock   Statements
 	IfElseBlock Statements
 	ElseBlock   Statements
+	Pos         Position `json:"-"`
+	End         Position `json:"-"`
 }
 
 type TryStatement struct {
 	Body  Statements
 	Catch Statements
+	Pos   Position `json:"-"`
+	End   Position `json:"-"`
 }
 
 type ThrowStatement struct {
 	Param Statement
+	Pos   Position `json:"-"`
+	End   Position `json:"-"`
 }
 
 type UndefinedStatement struct{}
 
 type ReturnStatement struct {
 	Param Statement
+	Pos   Position `json:"-"`
+	End   Position `json:"-"`
 }
 
 type NewObjectStatement struct {
 	Constructor string
 	Param       ExprStatements
+	Pos         Position `json:"-"`
+	End         Position `json:"-"`
 }
 
 type CallChainStatement struct {
 	Unit Statement
 	Call Statement
 	addStatementField
+	Pos Position `json:"-"`
+	End Position `json:"-"`
 }
 
 type MethodStatement struct {
 	Name  string
 	Param ExprStatements
+	// Embedded код из константной строки Выполнить (операторы) или Вычислить (одно выражение).
+	// Позиции узлов указывают внутрь строкового литерала
+	Embedded Statements `json:"Embedded,omitempty"`
 	addStatementField
+	Pos Position `json:"-"`
+	End Position `json:"-"`
 }
 
 type BreakStatement struct {
+	Pos Position `json:"-"`
+	End Position `json:"-"`
 }
 
 type ContinueStatement struct {
+	Pos Position `json:"-"`
+	End Position `json:"-"`
 }
 
 type LoopStatement struct {
@@ -161,30 +202,42 @@ type LoopStatement struct {
 	In        Statement `json:"In,omitempty"`
 	WhileExpr Statement `json:"WhileExpr,omitempty"`
 	Body      Statements
+	Pos       Position `json:"-"`
+	End       Position `json:"-"`
 }
 
 type TernaryStatement struct {
 	Expression Statement
 	TrueBlock  Statement
 	ElseBlock  Statement
+	Pos        Position `json:"-"`
+	End        Position `json:"-"`
 }
 
 type ItemStatement struct {
 	Item   Statement
 	Object Statement
+	Pos    Position `json:"-"`
+	End    Position `json:"-"`
 }
 
 type GoToStatement struct {
 	Label *GoToLabelStatement
+	Pos   Position `json:"-"`
+	End   Position `json:"-"`
 }
 
 type GoToLabelStatement struct {
 	Name string
+	Pos  Position `json:"-"`
+	End  Position `json:"-"`
 }
 
 func (p *ParamStatement) Fill(valueParam *Token, identifier Token) *ParamStatement {
 	p.IsValue = valueParam != nil
 	p.Name = identifier.literal
+	p.Pos = identifier.position
+	p.End = identifier.end()
 	return p
 }
 
@@ -213,6 +266,11 @@ func (e ExprStatements) Not() interface{} {
 	return e
 }
 
+func (e ExprStatements) UnaryMinus() interface{} {
+	e.unaryMinus = true
+	return e
+}
+
 func (e VarStatement) UnaryMinus() interface{} {
 	e.unaryMinus = true
 	return e
@@ -244,6 +302,11 @@ func (n MethodStatement) Not() interface{} {
 	return n
 }
 
+// IsNot вернет true для вызова с отрицанием: Не ТранзакцияАктивна()
+func (n MethodStatement) IsNot() bool {
+	return n.not
+}
+
 func (n NewObjectStatement) Params() ExprStatements {
 	return n.Param
 }
@@ -285,6 +348,49 @@ func (o OperationType) String() string {
 	}
 }
 
+// приоритеты операций, порядок повторяет объявления %left/%right в grammar.y
+const (
+	precLowest = iota
+	precOr
+	precAnd
+	precNe
+	precLe
+	precGe
+	precNot
+	precEq
+	precCompare
+	precAdd
+	precMul
+	precUnary
+	precAtom
+)
+
+// precedence возвращает приоритет бинарной операции в том виде, в котором его разбирает грамматика
+func (o OperationType) precedence() int {
+	switch o {
+	case OpOr:
+		return precOr
+	case OpAnd:
+		return precAnd
+	case OpNe:
+		return precNe
+	case OpLe:
+		return precLe
+	case OpGe:
+		return precGe
+	case OpEq:
+		return precEq
+	case OpGt, OpLt:
+		return precCompare
+	case OpPlus, OpMinus:
+		return precAdd
+	case OpMul, OpDiv, OpMod:
+		return precMul
+	default:
+		return precAtom
+	}
+}
+
 func (m *ModuleStatement) Walk(callBack fCallBack) {
 	StatementWalk(m.Body, m.Body, callBack)
 }
@@ -353,6 +459,7 @@ func walkHelper(parent *FunctionOrProcedure, parentStm Statement, statements Sta
 			parent = v
 		case MethodStatement:
 			walkHelper(parent, v, v.Param.Statements, callBack)
+			walkHelper(parent, v, v.Embedded, callBack)
 		//case CallChainStatement:
 		//	walkHelper(parent, Statements{v.Unit}, callBack)
 		case *ExpStatement:
@@ -369,3 +476,65 @@ func walkHelper(parent *FunctionOrProcedure, parentStm Statement, statements Sta
 		callBack(parent, &parentStm, &statements[i])
 	}
 }
+
+// Range участок исходного кода, End указывает на позицию сразу за последним символом
+type Range struct {
+	Start Position
+	End   Position
+}
+
+// PositionOf возвращает позицию узла в исходном коде. Для литералов и узлов созданных программно позиция пустая
+func PositionOf(stm Statement) Position {
+	return RangeOf(stm).Start
+}
+
+// RangeOf возвращает участок исходного кода, из которого разобран узел. Директивы процедуры входят в ее участок
+func RangeOf(stm Statement) Range {
+	switch v := stm.(type) {
+	case AssignmentStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case VarStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case *DirectiveStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case *FunctionOrProcedure:
+		if len(v.Directives) > 0 && v.Directives[0] != nil {
+			return Range{Start: v.Directives[0].Pos, End: v.End}
+		}
+		return Range{Start: v.Pos, End: v.End}
+	case ParamStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case *ExpStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case *IfStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case TryStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case ThrowStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case *ReturnStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case NewObjectStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case CallChainStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case MethodStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case BreakStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case ContinueStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case *LoopStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case TernaryStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case ItemStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case GoToStatement:
+		return Range{Start: v.Pos, End: v.End}
+	case *GoToLabelStatement:
+		return Range{Start: v.Pos, End: v.End}
+	default:
+		return Range{}
+	}
+}
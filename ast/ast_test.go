@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -22,6 +23,9 @@ func TestParse(t *testing.T) {
 		assert.Contains(t, a.PrintStatement(a.ModuleStatement.Body[0]), "Процедура")
 
 		p := a.Print(PrintConf{OneLine: true})
+		assert.Equal(t, "Процедура dsds() d = 864 / 63 + 607 - 177 * 906 * 27 > 737 * 429 + 84 - 270;КонецПроцедуры", strings.TrimSpace(p))
+
+		p = a.Print(PrintConf{OneLine: true, LispStyle: true})
 		assert.Equal(t, "Процедура dsds() d = (((864 / 63) + 607) - ((177 * 906) * 27)) > (((737 * 429) + 84) - 270);КонецПроцедуры", strings.TrimSpace(p))
 	}
 }
@@ -148,7 +152,7 @@ func TestParseString(t *testing.T) {
 		err := a.Parse()
 		if assert.NoError(t, err) && assert.NotNil(t, a.ModuleStatement.Body) {
 			p := a.Print(PrintConf{OneLine: true})
-			assert.Equal(t, "a = (\"rererer\" + \"rererer\") + \"rererer\";", strings.TrimSpace(p))
+			assert.Equal(t, "a = \"rererer\" + \"rererer\" + \"rererer\";", strings.TrimSpace(p))
 		}
 	})
 	t.Run("test5", func(t *testing.T) {
@@ -381,6 +385,82 @@ func TestExecute(t *testing.T) {
 		err := a.Parse()
 		assert.EqualError(t, err, "syntax error. line: 3, column: 16 (unexpected literal: \"Алгоритм\")")
 	})
+	t.Run("Eval-3", func(t *testing.T) {
+		code := `Процедура А(Алгоритм)
+	в = Вычислить("1 + " + Алгоритм);
+	Объект.Вычислить(1, 2);
+КонецПроцедуры`
+
+		a := NewAST(code)
+		if assert.NoError(t, a.Parse()) {
+			pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+			eval := pf.Body[0].(AssignmentStatement).Expr.Statements[0].(MethodStatement)
+			assert.Equal(t, "Вычислить", eval.Name)
+			assert.Len(t, eval.Param.Statements, 1)
+			assert.Equal(t, Range{Start: Position{Line: 2, Column: 6, Offset: 46}, End: Position{Line: 2, Column: 34, Offset: 91}}, RangeOf(eval))
+
+			assert.Equal(t, "Procedure А(Алгоритм) \n    в = Eval(\"1 + \" + Алгоритм);\n    Объект.Вычислить(1, 2);\nEndProcedure",
+				strings.TrimSpace(a.Print(PrintConf{Margin: 4, ScriptVariant: ScriptVariantEnglish})))
+		}
+	})
+	t.Run("Eval-identifier", func(t *testing.T) {
+		code := `Функция Вычислить(Выражение)
+	Возврат Выражение;
+КонецФункции
+
+Значение = Вычислить;
+Значение = Вычислить(1, 2);
+Значение = Объект.Вычислить("1 + 1");
+Значение = Вычислить("1 + 1");`
+
+		a := NewAST(code)
+		if assert.NoError(t, a.Parse()) {
+			assert.Equal(t, "Вычислить", a.ModuleStatement.Body[0].(*FunctionOrProcedure).Name)
+			assert.Equal(t, "Вычислить", a.ModuleStatement.Body[1].(AssignmentStatement).Expr.Statements[0].(VarStatement).Name)
+
+			call := a.ModuleStatement.Body[2].(AssignmentStatement).Expr.Statements[0].(MethodStatement)
+			assert.Len(t, call.Param.Statements, 2)
+			assert.Nil(t, call.Embedded)
+
+			chain := a.ModuleStatement.Body[3].(AssignmentStatement).Expr.Statements[0].(CallChainStatement)
+			assert.Nil(t, chain.Unit.(MethodStatement).Embedded)
+
+			// в модуле свой метод Вычислить, строка - его параметр, а не код
+			eval := a.ModuleStatement.Body[4].(AssignmentStatement).Expr.Statements[0].(MethodStatement)
+			assert.Nil(t, eval.Embedded)
+		}
+	})
+	t.Run("Eval-module-method", func(t *testing.T) {
+		code := `Процедура П()
+	Значение = Вычислить("1 +");
+	Выполнить("Значение = Вычислить(""2"")");
+КонецПроцедуры
+
+Функция Вычислить(Выражение)
+	Возврат Выражение;
+КонецФункции`
+
+		a := NewAST(code)
+		if assert.NoError(t, a.Parse()) {
+			body := a.ModuleStatement.Body[0].(*FunctionOrProcedure).Body
+			assert.Nil(t, body[0].(AssignmentStatement).Expr.Statements[0].(MethodStatement).Embedded)
+
+			// код строки Выполнить остается, но Вычислить в нем тоже вызывает метод модуля
+			execute := body[1].(MethodStatement).Embedded
+			if assert.Len(t, execute, 1) {
+				assert.Nil(t, execute[0].(AssignmentStatement).Expr.Statements[0].(MethodStatement).Embedded)
+			}
+			assert.Empty(t, a.Warnings())
+		}
+
+		// без своего метода ошибка в строке Вычислить попадает в предупреждения
+		a = NewAST(strings.Replace(code, "Функция Вычислить", "Функция Посчитать", 1))
+		if assert.NoError(t, a.Parse()) {
+			if assert.Len(t, a.Warnings(), 1) {
+				assert.Equal(t, 2, a.Warnings()[0].Line)
+			}
+		}
+	})
 }
 
 func TestParseIF(t *testing.T) {
@@ -685,10 +765,26 @@ func TestParseIF(t *testing.T) {
 		a := NewAST(code)
 		err := a.Parse()
 		if assert.NoError(t, err) {
-			p := a.Print(PrintConf{OneLine: true})
+			p := a.Print(PrintConf{OneLine: true, LispStyle: true})
 			assert.Equal(t, "Функция Команда1НаСервере() Если Не (ШаблонТекстаОшибки = \"\") Тогда КонецЕсли;КонецФункции", strings.TrimSpace(p))
 		}
 	})
+	t.Run("elseif order", func(t *testing.T) {
+		code := `Если а Тогда
+				ИначеЕсли б Тогда
+				ИначеЕсли в Тогда
+				КонецЕсли;`
+
+		a := NewAST(code)
+		if assert.NoError(t, a.Parse()) {
+			var names []string
+			for _, item := range a.ModuleStatement.Body[0].(*IfStatement).IfElseBlock {
+				names = append(names, item.(*IfStatement).Expression.(VarStatement).Name)
+			}
+			assert.Equal(t, []string{"б", "в"}, names)
+			assert.Equal(t, "Если а Тогда ИначеЕсли б Тогда ИначеЕсли в Тогда КонецЕсли;", strings.TrimSpace(a.Print(PrintConf{OneLine: true})))
+		}
+	})
 }
 
 func TestParseLoop(t *testing.T) {
@@ -996,7 +1092,7 @@ func TestTryCatch(t *testing.T) {
 		err := a.Parse()
 		if assert.NoError(t, err) {
 			json, _ := a.JSON()
-			assert.Contains(t, string(json), `{"Param":"fff"}`)
+			assert.Contains(t, string(json), `{"type":"ThrowStatement","Param":"fff",`)
 		}
 	})
 	t.Run("pass", func(t *testing.T) {
@@ -1287,6 +1383,19 @@ func TestParseFunctionProcedure(t *testing.T) {
 			err := a.Parse()
 			assert.NoError(t, err)
 		})
+		t.Run("print variables", func(t *testing.T) {
+			code := `Процедура П()
+						Перем б;
+						Перем а;
+
+						а = б;
+					КонецПроцедуры`
+
+			a := NewAST(code)
+			if assert.NoError(t, a.Parse()) {
+				assert.Equal(t, "Процедура П() Перем а, б;а = б;КонецПроцедуры", strings.TrimSpace(a.Print(PrintConf{OneLine: true})))
+			}
+		})
 		t.Run("export", func(t *testing.T) {
 			code := `Функция ПодключитьВнешнююОбработку(Ссылка) Экспорт
 
@@ -1523,6 +1632,21 @@ func TestParseBaseExpression(t *testing.T) {
 		err := a.Parse()
 		assert.NoError(t, err)
 	})
+	t.Run("float", func(t *testing.T) {
+		code := `Процедура П() ds = 2.5; uu = 0.125 * 3; КонецПроцедуры`
+
+		a := NewAST(code)
+		if assert.NoError(t, a.Parse()) {
+			assert.Equal(t, "Процедура П() ds = 2.5;uu = 0.125 * 3;КонецПроцедуры", strings.TrimSpace(a.Print(PrintConf{OneLine: true})))
+		}
+	})
+	t.Run("not call", func(t *testing.T) {
+		a := NewAST(`а = Не ТранзакцияАктивна(); б = ТранзакцияАктивна();`)
+		if assert.NoError(t, a.Parse()) {
+			assert.True(t, a.ModuleStatement.Body[0].(AssignmentStatement).Expr.Statements[0].(MethodStatement).IsNot())
+			assert.False(t, a.ModuleStatement.Body[1].(AssignmentStatement).Expr.Statements[0].(MethodStatement).IsNot())
+		}
+	})
 	t.Run("pass", func(t *testing.T) {
 		code := `Процедура ПодключитьВнешнююОбработку(Ссылка) ds = 222; uu = 9; КонецПроцедуры`
 
@@ -1863,7 +1987,7 @@ func TestExpPriority(t *testing.T) {
 		a := NewAST(code)
 		err := a.Parse()
 		if assert.NoError(t, err) {
-			p := a.Print(PrintConf{Margin: 4})
+			p := a.Print(PrintConf{Margin: 4, LispStyle: true})
 			//fmt.Println(p)
 			assert.Equal(t, "А=((d=2)=d)ИЛИ(в=3);Если((1=1)=2)=3ТогдаПриКомпоновкеРезультата();КонецЕсли;", normalize(p))
 		}
@@ -1879,7 +2003,7 @@ func TestExpPriority(t *testing.T) {
 		err := a.Parse()
 
 		if assert.NoError(t, err) {
-			p := a.Print(PrintConf{Margin: 4})
+			p := a.Print(PrintConf{Margin: 4, LispStyle: true})
 			assert.Equal(t, "ПроцедураОткрытьНавигационнуюСсылку(НавигационнаяСсылка,ЗначОповещение=Неопределено)ЭкспортЕсли(((в=1)=5)ИНеавав)ИЛИаааТогдав=(((1=5)=1)ИНеавав)ИЛИааа;КонецЕсли;КонецПроцедуры", normalize(p))
 		}
 	})
@@ -1893,7 +2017,7 @@ func TestExpPriority(t *testing.T) {
 		err := a.Parse()
 
 		if assert.NoError(t, err) {
-			p := a.Print(PrintConf{OneLine: true})
+			p := a.Print(PrintConf{OneLine: true, LispStyle: true})
 			assert.Equal(t, "Процедура f() тест.куку.ууу = (((1 = 5) = 1) И Не авав) ИЛИ ааа;тест[333] = (((1 = 5) = 1) = 4) = fd;КонецПроцедуры", strings.TrimSpace(p))
 		}
 	})
@@ -1907,12 +2031,246 @@ func TestExpPriority(t *testing.T) {
 		err := a.Parse()
 
 		if assert.NoError(t, err) {
-			p := a.Print(PrintConf{OneLine: true})
+			p := a.Print(PrintConf{OneLine: true, LispStyle: true})
 			assert.Equal(t, "Процедура f() ds = r / (КонВремя - НачВремя);fd = Формат(r / (КонВремя - НачВремя), \"ЧН=; ЧГ=\");КонецПроцедуры", strings.TrimSpace(p))
 		}
 	})
 }
 
+func TestMinimalParentheses(t *testing.T) {
+	v := func(name string) VarStatement { return VarStatement{Name: name} }
+	exp := func(op OperationType, left, right Statement) *ExpStatement {
+		return &ExpStatement{Operation: op, Left: left, Right: right}
+	}
+
+	cases := []struct {
+		expr     Statement
+		expected string
+	}{
+		{exp(OpPlus, v("a"), exp(OpMul, v("b"), v("c"))), "a + b * c"},
+		{exp(OpMul, exp(OpPlus, v("a"), v("b")), v("c")), "(a + b) * c"},
+		{exp(OpMinus, exp(OpMinus, v("a"), v("b")), v("c")), "a - b - c"},
+		{exp(OpMinus, v("a"), exp(OpMinus, v("b"), v("c"))), "a - (b - c)"},
+		{exp(OpOr, exp(OpAnd, v("a"), v("b")), v("c")), "a И b ИЛИ c"},
+		{exp(OpAnd, v("a"), exp(OpOr, v("b"), v("c"))), "a И (b ИЛИ c)"},
+		{exp(OpAnd, v("a").Not(), v("b")), "Не a И b"},
+		{exp(OpEq, v("a").Not(), v("b")), "(Не a) = b"},
+		{exp(OpEq, exp(OpEq, v("a"), v("b").Not()), v("c")), "a = (Не b) = c"},
+		{exp(OpEq, v("a"), v("b").Not()), "a = Не b"},
+		{exp(OpEq, v("a"), exp(OpEq, v("b"), v("c")).Not()), "a = Не b = c"},
+		{exp(OpAnd, v("a"), exp(OpOr, v("b"), v("c")).Not()), "a И Не (b ИЛИ c)"},
+		{exp(OpMul, v("a").UnaryMinus(), v("b")), "-a * b"},
+		{exp(OpMinus, v("a"), -1.5), "a - -1.5"},
+	}
+
+	printExpression := func(conf PrintConf, expr Statement) string {
+		builder := &strings.Builder{}
+		newAstPrint(builder, conf, nil, false).printExpression(expr, 0)
+		return builder.String()
+	}
+
+	for _, c := range cases {
+		text := printExpression(PrintConf{}, c.expr)
+		assert.Equal(t, c.expected, text)
+
+		// напечатанное выражение должно разбираться в то же дерево (с точностью до узлов скобок)
+		a := NewAST("x = " + text)
+		if assert.NoError(t, a.Parse()) {
+			assert.Equal(t, c.expr, withoutPositions(withoutParentheses(a.ModuleStatement.Body[0].(AssignmentStatement).Expr.Statements[0])), text)
+		}
+	}
+
+	t.Run("LispStyle", func(t *testing.T) {
+		assert.Equal(t, "a + (b * c)", printExpression(PrintConf{LispStyle: true}, exp(OpPlus, v("a"), exp(OpMul, v("b"), v("c")))))
+	})
+	t.Run("round trip", func(t *testing.T) {
+		fileData, err := os.ReadFile("testdata")
+		assert.NoError(t, err)
+
+		a := NewAST(string(fileData))
+		if !assert.NoError(t, a.Parse()) {
+			return
+		}
+
+		// комментарии при печати не выводятся
+		a.ModuleStatement.Comments = nil
+
+		b := NewAST(a.Print(PrintConf{Margin: 4}))
+		if assert.NoError(t, b.Parse()) {
+			assert.Equal(t, withoutPositions(a.ModuleStatement), withoutPositions(b.ModuleStatement))
+		}
+	})
+	t.Run("source parentheses", func(t *testing.T) {
+		code := `Если (КодСимвола < 1040) ИЛИ Не ((Не Учитывать И ЭтоРазделитель(КодСимвола))) Тогда
+					а = -(б - в) * 2.5 + Не г;
+				КонецЕсли`
+
+		a := NewAST(code)
+		if assert.NoError(t, a.Parse()) {
+			p := a.Print(PrintConf{OneLine: true})
+			assert.Equal(t, "Если (КодСимвола < 1040) ИЛИ Не ((Не Учитывать И ЭтоРазделитель(КодСимвола))) Тогда а = -(б - в) * 2.5 + Не г;КонецЕсли;", strings.TrimSpace(p))
+
+			b := NewAST(p)
+			if assert.NoError(t, b.Parse()) {
+				assert.Equal(t, p, b.Print(PrintConf{OneLine: true}))
+			}
+		}
+	})
+}
+
+func TestScriptVariant(t *testing.T) {
+	code := `&НаСервере
+			Функция Проверить(Знач Текст, Параметры = Неопределено) Экспорт
+				Перем Результат;
+
+				Результат = Новый Структура("Текст", Текст);
+				Для Каждого Элемент Из Справочники.Валюты.Выбрать() Цикл
+					Если Не ЗначениеЗаполнено(Элемент) И СтрНайти(Текст, "а") > 0 Тогда
+						Продолжить;
+					ИначеЕсли Элемент.Найти(Текст) = Неопределено Тогда
+						Прервать;
+					КонецЕсли;
+				КонецЦикла;
+
+				Попытка
+					Сообщить(Локальная(Текст));
+				Исключение
+					ВызватьИсключение;
+				КонецПопытки;
+
+				Возврат Истина;
+			КонецФункции
+
+			Функция Локальная(Текст)
+				Возврат Текст;
+			КонецФункции`
+
+	a := NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	english := a.Print(PrintConf{OneLine: true, ScriptVariant: ScriptVariantEnglish})
+	assert.Equal(t, "&AtServer\nFunction Проверить(Val Текст, Параметры = Undefined) Export Var Результат;"+
+		"Результат = New Structure(\"Текст\", Текст);"+
+		"For Each Элемент In Catalogs.Валюты.Выбрать() Do "+
+		"If Not ValueIsFilled(Элемент) And StrFind(Текст, \"а\") > 0 Then Continue;"+
+		"ElsIf Элемент.Найти(Текст) = Undefined Then Break;EndIf;EndDo;"+
+		"Try Message(Локальная(Текст));Except Raise;EndTry;"+
+		"Return True;EndFunction Function Локальная(Текст) Return Текст;EndFunction", strings.TrimSpace(english))
+
+	t.Run("one line parses again", func(t *testing.T) {
+		b := NewAST(english)
+		if assert.NoError(t, b.Parse()) {
+			assert.Equal(t, a.Print(PrintConf{OneLine: true}), b.Print(PrintConf{OneLine: true}))
+		}
+	})
+
+	t.Run("back to russian", func(t *testing.T) {
+		b := NewAST(a.Print(PrintConf{Margin: 4, ScriptVariant: ScriptVariantEnglish}))
+		if assert.NoError(t, b.Parse()) {
+			assert.Equal(t, a.ModuleStatement.GlobalVariables, b.ModuleStatement.GlobalVariables)
+			assert.Equal(t, a.Print(PrintConf{Margin: 4}), b.Print(PrintConf{Margin: 4}))
+		}
+	})
+	t.Run("local variable named like global property", func(t *testing.T) {
+		code := `Процедура П(Справочники)
+					Метаданные = Справочники.Получить();
+					Сообщить(Метаданные.Имя + Документы.Имя);
+				КонецПроцедуры`
+
+		b := NewAST(code)
+		if assert.NoError(t, b.Parse()) {
+			p := b.Print(PrintConf{OneLine: true, ScriptVariant: ScriptVariantEnglish})
+			assert.Equal(t, "Procedure П(Справочники) Метаданные = Справочники.Получить();Message(Метаданные.Имя + Documents.Имя);EndProcedure", strings.TrimSpace(p))
+		}
+	})
+	t.Run("english source", func(t *testing.T) {
+		code := `&AtClient
+				Procedure Test(Val Param) Export
+					While Param <> Undefined Do
+						Message(Format(Param, "ND=10"));
+						Param = New Array;
+					EndDo;
+				EndProcedure`
+
+		b := NewAST(code)
+		if assert.NoError(t, b.Parse()) {
+			p := b.Print(PrintConf{OneLine: true})
+			assert.Equal(t, "&НаКлиенте\nПроцедура Test(Знач Param) Экспорт Пока Param <> Неопределено Цикл Сообщить(Формат(Param, \"ND=10\"));Param = Новый Массив;КонецЦикла;КонецПроцедуры", strings.TrimSpace(p))
+		}
+	})
+}
+
+func TestFprint(t *testing.T) {
+	t.Run("same as Print", func(t *testing.T) {
+		fileData, err := os.ReadFile("testdata")
+		assert.NoError(t, err)
+
+		a := NewAST(string(fileData))
+		if !assert.NoError(t, a.Parse()) {
+			return
+		}
+
+		for _, conf := range []PrintConf{{Margin: 4}, {OneLine: true}, {LispStyle: true}, {ScriptVariant: ScriptVariantEnglish}} {
+			builder := &strings.Builder{}
+			assert.NoError(t, Fprint(builder, a, conf))
+			assert.Equal(t, a.Print(conf), builder.String())
+		}
+
+		pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+		builder := &strings.Builder{}
+		assert.NoError(t, Fprint(builder, pf, PrintConf{Margin: 4}))
+		assert.Equal(t, a.PrintStatement(pf), builder.String())
+	})
+	t.Run("writer error", func(t *testing.T) {
+		a := NewAST(`Сообщить("тест")`)
+		if assert.NoError(t, a.Parse()) {
+			err := Fprint(failWriter{}, &a.ModuleStatement, PrintConf{})
+			assert.EqualError(t, err, "print error: write failed")
+		}
+	})
+	t.Run("source map", func(t *testing.T) {
+		code := `Процедура Тест(Знач Параметр)
+	Если Параметр.Количество() > 0 Тогда Сообщить("Ок"); КонецЕсли;
+	Результат =   Вычислить(Параметр) + 1;
+КонецПроцедуры`
+
+		a := NewAST(code)
+		if !assert.NoError(t, a.Parse()) {
+			return
+		}
+
+		builder := &strings.Builder{}
+		sourceMap, err := FprintWithSourceMap(builder, a, PrintConf{Margin: 2})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		printed := strings.Split(builder.String(), "\n")
+		assert.Equal(t, "    Сообщить(\"Ок\");", printed[2])
+
+		original, ok := sourceMap.Original(3, 5)
+		assert.True(t, ok)
+		assert.Equal(t, Position{Line: 2, Column: 39, Offset: 120}, original)
+		assert.True(t, strings.HasPrefix(code[original.Offset:], "Сообщить"))
+
+		// позиция внутри напечатанного выражения относится к ближайшему узлу левее
+		original, ok = sourceMap.Original(5, 27)
+		assert.True(t, ok)
+		assert.True(t, strings.HasPrefix(code[original.Offset:], "Параметр) + 1"))
+
+		_, ok = sourceMap.Original(0, 1)
+		assert.False(t, ok)
+
+		// каждая запись указывает на один и тот же текст в напечатанном и исходном коде
+		for _, m := range sourceMap.Mappings {
+			word := []rune(builder.String()[m.Generated.Offset:])[:3]
+			assert.True(t, strings.HasPrefix(code[m.Original.Offset:], string(word)), string(word))
+		}
+	})
+}
+
 func Test_Directive(t *testing.T) {
 	code := `
 	&НаКлиенте
@@ -1987,6 +2345,100 @@ func BenchmarkString(b *testing.B) {
 	//})
 }
 
+// withoutParentheses убирает из выражения узлы скобок, перенося "Не" на вложенное выражение
+func withoutParentheses(stm Statement) Statement {
+	switch v := stm.(type) {
+	case ExprStatements:
+		if len(v.Statements) != 1 {
+			return v
+		}
+
+		inner := withoutParentheses(v.Statements[0])
+		if v.not {
+			return not(inner)
+		}
+		return inner
+	case *ExpStatement:
+		v.Left = withoutParentheses(v.Left)
+		v.Right = withoutParentheses(v.Right)
+		return v
+	default:
+		return v
+	}
+}
+
+// withoutPositions возвращает копию дерева с обнуленными позициями, что бы сравнивать деревья разобранные из разного текста
+func withoutPositions(stm interface{}) interface{} {
+	if stm == nil {
+		return nil
+	}
+
+	return clearPositions(reflect.ValueOf(stm)).Interface()
+}
+
+func clearPositions(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.New(v.Type().Elem())
+		result.Elem().Set(clearPositions(v.Elem()))
+		return result
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.New(v.Type()).Elem()
+		result.Set(clearPositions(v.Elem()))
+		return result
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(Position{}) {
+			return reflect.Zero(v.Type())
+		}
+
+		// неэкспортируемые поля копируются вместе со структурой, позиций в них нет
+		result := reflect.New(v.Type()).Elem()
+		result.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if result.Field(i).CanSet() {
+				result.Field(i).Set(clearPositions(v.Field(i)))
+			}
+		}
+		return result
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(clearPositions(v.Index(i)))
+		}
+		return result
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			result.SetMapIndex(iter.Key(), clearPositions(iter.Value()))
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
 func test(_ string)    {}
 func testPt(_ *string) {}
 
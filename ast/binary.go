@@ -0,0 +1,522 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BinaryVersion версия двоичного формата, меняется при любом изменении структуры узлов
+const BinaryVersion = 2
+
+var binaryMagic = []byte("BSLAST")
+
+// коды значений на месте Statement, узлы кодируются как binaryNode + индекс в serializedNodes
+const (
+	binaryNil byte = iota
+	binaryString
+	binaryNumber
+	binaryTrue
+	binaryFalse
+	binaryDate
+	binaryNode
+)
+
+// ограничение вложенности при чтении, чтобы испорченные данные не переполнили стек
+const binaryMaxDepth = 10000
+
+// binaryField поле узла, flags - встроенная структура с флагами унарных операций
+type binaryField struct {
+	index int
+	name  string
+	flags bool
+}
+
+var (
+	binaryNodes     []jsonNode
+	binaryNodeCodes = map[reflect.Type]int{}
+	binaryFields    = map[reflect.Type][]binaryField{} // поля узлов разбираются один раз, reflect.Type.Field медленный
+)
+
+func init() {
+	for i, n := range serializedNodes {
+		t := reflect.Indirect(reflect.ValueOf(n)).Type()
+		binaryNodes = append(binaryNodes, jsonNode{typ: t, pointer: reflect.TypeOf(n).Kind() == reflect.Ptr})
+		binaryNodeCodes[t] = i
+
+		fields := []binaryField{}
+		for j := 0; j < t.NumField(); j++ {
+			field := t.Field(j)
+			if field.IsExported() || field.Type == reflect.TypeOf(addStatementField{}) {
+				fields = append(fields, binaryField{index: j, name: field.Name, flags: !field.IsExported()})
+			}
+		}
+		binaryFields[t] = fields
+	}
+}
+
+// MarshalBinary кодирует модуль в компактный двоичный формат версии BinaryVersion вместе с позициями и комментариями.
+// Формат предназначен для кеширования разобранных модулей, читается он заметно быстрее повторного разбора
+func (m *ModuleStatement) MarshalBinary() ([]byte, error) {
+	e := &binaryEncoder{strings: map[string]int{}}
+	e.buf.Write(binaryMagic)
+	e.uint(BinaryVersion)
+
+	if err := e.value(reflect.ValueOf(m).Elem()); err != nil {
+		return nil, errors.Wrap(err, "binary encode error")
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalBinary восстанавливает модуль из данных MarshalBinary
+func (m *ModuleStatement) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, binaryMagic) {
+		return fmt.Errorf("binary decode error: incorrect header")
+	}
+
+	d := &binaryDecoder{data: data[len(binaryMagic):]}
+	if version, err := d.uint(); err != nil {
+		return errors.Wrap(err, "binary decode error")
+	} else if version != BinaryVersion {
+		return fmt.Errorf("unsupported binary version %d", version)
+	}
+
+	module := ModuleStatement{}
+	if err := d.value(reflect.ValueOf(&module).Elem(), 0); err != nil {
+		return errors.Wrap(err, "binary decode error")
+	}
+	if len(d.data) > 0 {
+		return fmt.Errorf("binary decode error: %d extra bytes", len(d.data))
+	}
+
+	*m = module
+	return nil
+}
+
+type binaryEncoder struct {
+	buf     bytes.Buffer
+	strings map[string]int // номера уже записанных строк, повторно строка пишется номером
+}
+
+func (e *binaryEncoder) uint(v uint64) {
+	e.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (e *binaryEncoder) int(v int64) {
+	e.buf.Write(binary.AppendVarint(nil, v))
+}
+
+// length пишет длину списка, 0 зарезервирован под nil
+func (e *binaryEncoder) length(v reflect.Value) bool {
+	if v.IsNil() {
+		e.uint(0)
+		return false
+	}
+
+	e.uint(uint64(v.Len()) + 1)
+	return true
+}
+
+func (e *binaryEncoder) string(s string) {
+	if i, ok := e.strings[s]; ok {
+		e.uint(uint64(i) + 1)
+		return
+	}
+
+	e.strings[s] = len(e.strings)
+	e.uint(0)
+	e.uint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *binaryEncoder) statement(v reflect.Value) error {
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			e.uint(uint64(binaryNil))
+			return nil
+		}
+		return e.statement(v.Elem())
+	}
+
+	switch value := v.Interface().(type) {
+	case string:
+		e.uint(uint64(binaryString))
+		e.string(value)
+	case float64:
+		e.uint(uint64(binaryNumber))
+		e.buf.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(value)))
+	case bool:
+		if value {
+			e.uint(uint64(binaryTrue))
+		} else {
+			e.uint(uint64(binaryFalse))
+		}
+	case time.Time:
+		e.uint(uint64(binaryDate))
+		e.int(value.Unix())
+	default:
+		code, ok := binaryNodeCodes[v.Type()]
+		if !ok {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+
+		e.uint(uint64(binaryNode) + uint64(code))
+		return e.fields(v)
+	}
+
+	return nil
+}
+
+func (e *binaryEncoder) value(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		return e.statement(v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.buf.WriteByte(0)
+			return nil
+		}
+		e.buf.WriteByte(1)
+		return e.value(v.Elem())
+	case reflect.Slice:
+		if e.length(v) {
+			for i := 0; i < v.Len(); i++ {
+				if err := e.value(v.Index(i)); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Map:
+		if e.length(v) {
+			keys := make([]string, 0, v.Len())
+			for _, k := range v.MapKeys() {
+				keys = append(keys, k.String())
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				e.string(k)
+				if err := e.value(v.MapIndex(reflect.ValueOf(k))); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Struct:
+		if v.Type() == positionType {
+			p := v.Interface().(Position)
+			e.uint(uint64(p.Line))
+			e.uint(uint64(p.Column))
+			e.uint(uint64(p.Offset))
+			return nil
+		}
+		return e.fields(v)
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf.WriteByte(1)
+		} else {
+			e.buf.WriteByte(0)
+		}
+	case reflect.Int:
+		e.int(v.Int())
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func (e *binaryEncoder) fields(v reflect.Value) error {
+	fields, ok := binaryFields[v.Type()]
+	if !ok {
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	for _, field := range fields {
+		if field.flags {
+			c := reflect.New(v.Type())
+			c.Elem().Set(v)
+			e.buf.WriteByte(c.Interface().(statementFlags).flags().bits())
+			continue
+		}
+
+		if err := e.value(v.Field(field.index)); err != nil {
+			return errors.Wrap(err, v.Type().Name()+"."+field.name)
+		}
+	}
+
+	return nil
+}
+
+type binaryDecoder struct {
+	data    []byte
+	strings []string
+}
+
+var errUnexpectedEnd = fmt.Errorf("unexpected end of data")
+
+func (d *binaryDecoder) byte() (byte, error) {
+	if len(d.data) == 0 {
+		return 0, errUnexpectedEnd
+	}
+
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b, nil
+}
+
+func (d *binaryDecoder) uint() (uint64, error) {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, fmt.Errorf("incorrect number")
+	}
+
+	d.data = d.data[n:]
+	return v, nil
+}
+
+func (d *binaryDecoder) int() (int64, error) {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		return 0, fmt.Errorf("incorrect number")
+	}
+
+	d.data = d.data[n:]
+	return v, nil
+}
+
+// length читает длину списка, каждый элемент занимает хотя бы байт, так что длина не может быть больше остатка данных
+func (d *binaryDecoder) length() (int, bool, error) {
+	n, err := d.uint()
+	if err != nil || n == 0 {
+		return 0, false, err
+	}
+	if n-1 > uint64(len(d.data)) {
+		return 0, false, errUnexpectedEnd
+	}
+
+	return int(n - 1), true, nil
+}
+
+func (d *binaryDecoder) string() (string, error) {
+	i, err := d.uint()
+	if err != nil {
+		return "", err
+	}
+	if i > 0 {
+		if i > uint64(len(d.strings)) {
+			return "", fmt.Errorf("incorrect string reference %d", i)
+		}
+		return d.strings[i-1], nil
+	}
+
+	n, err := d.uint()
+	if err != nil {
+		return "", err
+	}
+	if n > uint64(len(d.data)) {
+		return "", errUnexpectedEnd
+	}
+
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	d.strings = append(d.strings, s)
+	return s, nil
+}
+
+func (d *binaryDecoder) statement(depth int) (Statement, error) {
+	code, err := d.uint()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case code == uint64(binaryNil):
+		return nil, nil
+	case code == uint64(binaryString):
+		return d.string()
+	case code == uint64(binaryNumber):
+		if len(d.data) < 8 {
+			return nil, errUnexpectedEnd
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.data))
+		d.data = d.data[8:]
+		return v, nil
+	case code == uint64(binaryTrue):
+		return true, nil
+	case code == uint64(binaryFalse):
+		return false, nil
+	case code == uint64(binaryDate):
+		sec, err := d.int()
+		if err != nil {
+			return nil, err
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	case code-uint64(binaryNode) < uint64(len(binaryNodes)):
+		node := binaryNodes[code-uint64(binaryNode)]
+
+		p := reflect.New(node.typ)
+		if err := d.fields(p.Elem(), depth); err != nil {
+			return nil, err
+		}
+		if node.pointer {
+			return p.Interface(), nil
+		}
+		return p.Elem().Interface(), nil
+	default:
+		return nil, fmt.Errorf("unknown node code %d", code)
+	}
+}
+
+func (d *binaryDecoder) value(v reflect.Value, depth int) error {
+	if depth > binaryMaxDepth {
+		return fmt.Errorf("nesting is too deep")
+	}
+	depth++
+
+	switch v.Kind() {
+	case reflect.Interface:
+		stm, err := d.statement(depth)
+		if err != nil {
+			return err
+		}
+		if stm == nil {
+			return nil
+		}
+
+		// поля Statement и interface{} присваиваем без reflect.Value.Set, так заметно быстрее
+		switch p := v.Addr().Interface().(type) {
+		case *Statement:
+			*p = stm
+		case *interface{}:
+			*p = stm
+		default:
+			v.Set(reflect.ValueOf(stm))
+		}
+	case reflect.Ptr:
+		b, err := d.byte()
+		if err != nil || b == 0 {
+			return err
+		}
+
+		p := reflect.New(v.Type().Elem())
+		if err := d.value(p.Elem(), depth); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Slice:
+		n, ok, err := d.length()
+		if err != nil || !ok {
+			return err
+		}
+
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			if err := d.value(s.Index(i), depth); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		n, ok, err := d.length()
+		if err != nil || !ok {
+			return err
+		}
+
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for i := 0; i < n; i++ {
+			k, err := d.string()
+			if err != nil {
+				return err
+			}
+
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(value, depth); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k), value)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		if v.Type() == positionType {
+			p := v.Addr().Interface().(*Position)
+			for _, field := range []*int{&p.Line, &p.Column, &p.Offset} {
+				i, err := d.uint()
+				if err != nil {
+					return err
+				}
+				*field = int(i)
+			}
+			return nil
+		}
+		return d.fields(v, depth)
+	case reflect.String:
+		s, err := d.string()
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := d.byte()
+		if err != nil {
+			return err
+		}
+		v.SetBool(b != 0)
+	case reflect.Int:
+		i, err := d.int()
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func (d *binaryDecoder) fields(v reflect.Value, depth int) error {
+	fields, ok := binaryFields[v.Type()]
+	if !ok {
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	for _, field := range fields {
+		if field.flags {
+			b, err := d.byte()
+			if err != nil {
+				return err
+			}
+			v.Addr().Interface().(statementFlags).flags().setBits(b)
+			continue
+		}
+
+		if err := d.value(v.Field(field.index), depth); err != nil {
+			return errors.Wrap(err, v.Type().Name()+"."+field.name)
+		}
+	}
+
+	return nil
+}
+
+func (a *addStatementField) bits() (b byte) {
+	for i, set := range []bool{a.unaryMinus, a.unaryPlus, a.not} {
+		if set {
+			b |= 1 << i
+		}
+	}
+
+	return b
+}
+
+func (a *addStatementField) setBits(b byte) {
+	a.unaryMinus, a.unaryPlus, a.not = b&1 != 0, b&2 != 0, b&4 != 0
+}
@@ -0,0 +1,92 @@
+package ast
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		fileData, err := os.ReadFile("testdata")
+		assert.NoError(t, err)
+
+		a := NewAST(string(fileData))
+		assert.NoError(t, a.Parse())
+
+		data, err := a.ModuleStatement.MarshalBinary()
+		assert.NoError(t, err)
+
+		module := ModuleStatement{}
+		assert.NoError(t, module.UnmarshalBinary(data))
+		assert.Equal(t, a.ModuleStatement, module)
+
+		jsonData, err := a.JSON()
+		assert.NoError(t, err)
+		assert.Less(t, len(data), len(jsonData)/4)
+	})
+	t.Run("comments and flags", func(t *testing.T) {
+		code := `// первый комментарий
+				Процедура Проба()
+					а = -б + Не в; // второй комментарий
+					д = '20240102';
+				КонецПроцедуры`
+
+		a := NewAST(code)
+		assert.NoError(t, a.Parse())
+		if assert.Len(t, a.ModuleStatement.Comments, 2) {
+			assert.Equal(t, Comment{
+				Text: "// второй комментарий",
+				Pos:  Position{Line: 3, Column: 21, Offset: 100},
+				End:  Position{Line: 3, Column: 42, Offset: 138},
+			}, a.ModuleStatement.Comments[1])
+		}
+
+		data, err := a.ModuleStatement.MarshalBinary()
+		assert.NoError(t, err)
+
+		module := ModuleStatement{}
+		assert.NoError(t, module.UnmarshalBinary(data))
+		assert.Equal(t, a.ModuleStatement, module)
+	})
+	t.Run("errors", func(t *testing.T) {
+		module := ModuleStatement{}
+		assert.EqualError(t, module.UnmarshalBinary([]byte("{}")), "binary decode error: incorrect header")
+		assert.EqualError(t, module.UnmarshalBinary(append(binaryMagic, 3)), "unsupported binary version 3")
+
+		data, err := (&ModuleStatement{Body: Statements{VarStatement{Name: "а"}}}).MarshalBinary()
+		assert.NoError(t, err)
+		assert.Error(t, module.UnmarshalBinary(data[:len(data)-1]))
+		assert.EqualError(t, module.UnmarshalBinary(append(data, 0)), "binary decode error: 1 extra bytes")
+	})
+}
+
+func BenchmarkUnmarshalBinary(b *testing.B) {
+	fileData, err := os.ReadFile("testdata")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	a := NewAST(string(fileData))
+	if err := a.Parse(); err != nil {
+		b.Fatal(err)
+	}
+
+	data, err := a.ModuleStatement.MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("parse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewAST(string(fileData)).Parse()
+		}
+	})
+	b.Run("binary", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			module := ModuleStatement{}
+			module.UnmarshalBinary(data)
+		}
+	})
+}
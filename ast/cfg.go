@@ -0,0 +1,460 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EdgeKind вид перехода между блоками графа потока управления
+type EdgeKind int
+
+const (
+	EdgeNormal    EdgeKind = iota // переход к следующему оператору
+	EdgeTrue                      // условие выполнено, цикл выполняет следующую итерацию
+	EdgeFalse                     // условие не выполнено, цикл завершен
+	EdgeBack                      // возврат к заголовку цикла в конце тела или по Продолжить
+	EdgeBreak                     // выход из цикла по Прервать
+	EdgeReturn                    // Возврат
+	EdgeThrow                     // ВызватьИсключение
+	EdgeException                 // исключение в операторе внутри Попытки
+	EdgeGoTo                      // Перейти ~Метка
+)
+
+func (k EdgeKind) String() string {
+	switch k {
+	case EdgeNormal:
+		return ""
+	case EdgeTrue:
+		return "true"
+	case EdgeFalse:
+		return "false"
+	case EdgeBack:
+		return "back"
+	case EdgeBreak:
+		return "break"
+	case EdgeReturn:
+		return "return"
+	case EdgeThrow:
+		return "throw"
+	case EdgeException:
+		return "exception"
+	case EdgeGoTo:
+		return "goto"
+	default:
+		return ""
+	}
+}
+
+// BlockKind роль блока в графе
+type BlockKind int
+
+const (
+	BlockEntry     BlockKind = iota // начало метода
+	BlockExit                       // выход из метода, в него ведут Возврат, ВызватьИсключение и конец тела
+	BlockPlain                      // последовательность операторов
+	BlockCondition                  // условие Если или ИначеЕсли
+	BlockLoop                       // заголовок цикла
+	BlockCatch                      // начало блока Исключение
+	BlockLabel                      // оператор с меткой ~Метка:
+)
+
+func (k BlockKind) String() string {
+	switch k {
+	case BlockEntry:
+		return "entry"
+	case BlockExit:
+		return "exit"
+	case BlockPlain:
+		return "block"
+	case BlockCondition:
+		return "condition"
+	case BlockLoop:
+		return "loop"
+	case BlockCatch:
+		return "catch"
+	case BlockLabel:
+		return "label"
+	default:
+		return ""
+	}
+}
+
+// Edge переход в блок To
+type Edge struct {
+	To   *BasicBlock
+	Kind EdgeKind
+}
+
+// BasicBlock линейный участок метода. Statements - простые операторы и вычисляемые в блоке выражения:
+// условие Если, условие Пока, коллекция Для Каждого, граница Для, а в блоке BlockLabel первым идет сама
+// метка. Control - оператор, которым блок
+// заканчивается (*IfStatement, *LoopStatement, *ReturnStatement, ThrowStatement...), или nil
+type BasicBlock struct {
+	Index      int
+	Kind       BlockKind
+	Statements Statements
+	Control    Statement
+	Succs      []Edge
+	Preds      []*BasicBlock
+}
+
+// CFG граф потока управления метода. Blocks[0] - Entry, Blocks[1] - Exit. Блоки без предшественников,
+// кроме Entry, содержат недостижимый код
+type CFG struct {
+	Method *FunctionOrProcedure
+	Blocks []*BasicBlock
+	Entry  *BasicBlock
+	Exit   *BasicBlock
+}
+
+// BuildCFG строит граф потока управления метода. Переходы строятся для Если/ИначеЕсли/Иначе, всех видов циклов,
+// Прервать, Продолжить, Возврат, ВызватьИсключение и Перейти. Внутри Попытки исключение может возникнуть
+// в любом операторе, поэтому из каждого блока тела Попытки есть переход в блок Исключение
+func BuildCFG(method *FunctionOrProcedure) *CFG {
+	g := &CFG{Method: method}
+	b := &cfgBuilder{cfg: g, labels: map[string]*BasicBlock{}}
+	g.Entry = b.newBlock(BlockEntry)
+	g.Exit = b.newBlock(BlockExit)
+
+	b.current = b.newBlock(BlockPlain)
+	b.edge(g.Entry, b.current, EdgeNormal)
+	b.block(method.Body)
+	if b.current != nil {
+		b.edge(b.current, g.Exit, EdgeNormal)
+	}
+
+	// метки, которых нет в теле метода: парсер такие переходы не пропускает, но граф может строиться
+	// и для модуля, собранного программно
+	names := make([]string, 0, len(b.labels))
+	for name, block := range b.labels {
+		if block.Index < 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.place(b.labels[name])
+	}
+
+	return g
+}
+
+// Reachable возвращает блоки, в которые можно попасть из Entry
+func (g *CFG) Reachable() map[*BasicBlock]bool {
+	result := map[*BasicBlock]bool{}
+	stack := []*BasicBlock{g.Entry}
+	for len(stack) > 0 {
+		block := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if result[block] {
+			continue
+		}
+
+		result[block] = true
+		for _, e := range block.Succs {
+			stack = append(stack, e.To)
+		}
+	}
+
+	return result
+}
+
+// DOT возвращает граф в формате Graphviz: dot -Tsvg
+func (g *CFG) DOT() string {
+	builder := &strings.Builder{}
+	name := ""
+	if g.Method != nil {
+		name = g.Method.Name
+	}
+
+	fmt.Fprintf(builder, "digraph %q {\n", name)
+	builder.WriteString("\tnode [shape=box fontname=\"monospace\"];\n")
+	for _, block := range g.Blocks {
+		lines := []string{fmt.Sprintf("B%d %s", block.Index, block.Kind)}
+		for _, stm := range block.Statements {
+			lines = append(lines, statementText(stm))
+		}
+		if block.Control != nil && block.Kind != BlockLoop && block.Kind != BlockCondition {
+			lines = append(lines, statementText(block.Control))
+		}
+
+		shape := ""
+		switch block.Kind {
+		case BlockEntry, BlockExit:
+			shape = " shape=ellipse"
+		case BlockCondition, BlockLoop:
+			shape = " shape=diamond"
+		}
+		fmt.Fprintf(builder, "\tB%d [label=%s%s];\n", block.Index, dotString(lines), shape)
+	}
+	for _, block := range g.Blocks {
+		for _, e := range block.Succs {
+			attrs := ""
+			if e.Kind != EdgeNormal {
+				attrs = fmt.Sprintf(" [label=%q]", e.Kind.String())
+			}
+			if e.Kind == EdgeException || e.Kind == EdgeThrow {
+				attrs = fmt.Sprintf(" [label=%q style=dashed]", e.Kind.String())
+			}
+			fmt.Fprintf(builder, "\tB%d -> B%d%s;\n", block.Index, e.To.Index, attrs)
+		}
+	}
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+// statementText печатает оператор или выражение в одну строку для подписи блока
+func statementText(stm Statement) string {
+	builder := &strings.Builder{}
+	newAstPrint(builder, PrintConf{}, nil, false).printStatement(stm, 0)
+
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+func dotString(lines []string) string {
+	var quoted []string
+	for _, line := range lines {
+		line = strings.ReplaceAll(line, `\`, `\\`)
+		line = strings.ReplaceAll(line, `"`, `\"`)
+		quoted = append(quoted, line)
+	}
+
+	return `"` + strings.Join(quoted, `\l`) + `\l"`
+}
+
+type cfgLoop struct {
+	header *BasicBlock
+	after  *BasicBlock
+}
+
+type cfgBuilder struct {
+	cfg     *CFG
+	current *BasicBlock // nil после Возврат, Прервать и т.п.: следующий оператор недостижим
+	loops   []cfgLoop
+	catches []*BasicBlock // блоки Исключение объемлющих Попыток
+	labels  map[string]*BasicBlock
+}
+
+func (b *cfgBuilder) newBlock(kind BlockKind) *BasicBlock {
+	return b.place(&BasicBlock{Kind: kind})
+}
+
+// place добавляет в граф блок, созданный заранее: в блок после цикла переходы по Прервать
+// добавляются раньше, чем он займет свое место в порядке текста
+func (b *cfgBuilder) place(block *BasicBlock) *BasicBlock {
+	block.Index = len(b.cfg.Blocks)
+	b.cfg.Blocks = append(b.cfg.Blocks, block)
+	if len(b.catches) > 0 && block.Kind != BlockEntry && block.Kind != BlockExit {
+		b.edge(block, b.catches[len(b.catches)-1], EdgeException)
+	}
+
+	return block
+}
+
+func (b *cfgBuilder) edge(from, to *BasicBlock, kind EdgeKind) {
+	from.Succs = append(from.Succs, Edge{To: to, Kind: kind})
+	to.Preds = append(to.Preds, from)
+}
+
+// next возвращает блок, в который добавляется очередной оператор. Если текущий блок закончен переходом,
+// создается блок без предшественников для недостижимого кода
+func (b *cfgBuilder) next() *BasicBlock {
+	if b.current == nil {
+		b.current = b.newBlock(BlockPlain)
+	}
+
+	return b.current
+}
+
+// jump завершает текущий блок переходом в to
+func (b *cfgBuilder) jump(control Statement, to *BasicBlock, kind EdgeKind) {
+	block := b.next()
+	block.Control = control
+	b.edge(block, to, kind)
+	b.current = nil
+}
+
+// enter начинает новый блок kind, в который переходит текущий
+func (b *cfgBuilder) enter(kind BlockKind) *BasicBlock {
+	block := b.newBlock(kind)
+	if b.current != nil {
+		b.edge(b.current, block, EdgeNormal)
+	}
+	b.current = block
+
+	return block
+}
+
+func (b *cfgBuilder) block(items Statements) {
+	for _, item := range items {
+		b.statement(item)
+	}
+}
+
+func (b *cfgBuilder) statement(stm Statement) {
+	switch v := stm.(type) {
+	case *IfStatement:
+		b.ifStatement(v)
+	case *LoopStatement:
+		b.loop(v)
+	case TryStatement:
+		b.try(v)
+	case *ReturnStatement:
+		b.jump(v, b.cfg.Exit, EdgeReturn)
+	case ThrowStatement:
+		to := b.cfg.Exit
+		if len(b.catches) > 0 {
+			to = b.catches[len(b.catches)-1]
+		}
+		b.jump(v, to, EdgeThrow)
+	case BreakStatement:
+		if len(b.loops) > 0 {
+			b.jump(v, b.loops[len(b.loops)-1].after, EdgeBreak)
+		}
+	case ContinueStatement:
+		if len(b.loops) > 0 {
+			b.jump(v, b.loops[len(b.loops)-1].header, EdgeBack)
+		}
+	case GoToStatement:
+		if v.Label != nil {
+			b.jump(v, b.label(v.Label.Name), EdgeGoTo)
+		}
+	case *GoToLabelStatement:
+		block := b.label(v.Name)
+		if block.Index < 0 {
+			b.place(block)
+		}
+		if b.current != nil {
+			b.edge(b.current, block, EdgeNormal)
+		}
+		block.Statements = append(block.Statements, v)
+		b.current = block
+	default:
+		block := b.next()
+		block.Statements = append(block.Statements, stm)
+	}
+}
+
+func (b *cfgBuilder) label(name string) *BasicBlock {
+	key := NormalizeName(name)
+	if block, ok := b.labels[key]; ok {
+		return block
+	}
+
+	// на метку может вести переход выше по тексту, место в графе блок займет, когда дойдем до метки
+	block := &BasicBlock{Kind: BlockLabel, Index: -1}
+	b.labels[key] = block
+	return block
+}
+
+func (b *cfgBuilder) ifStatement(stm *IfStatement) {
+	var exits []*BasicBlock
+
+	cond := b.enter(BlockCondition)
+	cond.Statements = Statements{stm.Expression}
+	cond.Control = stm
+
+	branch := func(from *BasicBlock, body Statements) {
+		b.current = b.newBlock(BlockPlain)
+		b.edge(from, b.current, EdgeTrue)
+		b.block(body)
+		if b.current != nil {
+			exits = append(exits, b.current)
+		}
+	}
+
+	branch(cond, stm.TrueBlock)
+	for _, item := range stm.IfElseBlock {
+		elseIf := item.(*IfStatement)
+		next := b.newBlock(BlockCondition)
+		next.Statements = Statements{elseIf.Expression}
+		next.Control = elseIf
+		b.edge(cond, next, EdgeFalse)
+
+		cond = next
+		branch(cond, elseIf.TrueBlock)
+	}
+
+	if stm.ElseBlock != nil {
+		b.current = b.newBlock(BlockPlain)
+		b.edge(cond, b.current, EdgeFalse)
+		b.block(stm.ElseBlock)
+		if b.current != nil {
+			exits = append(exits, b.current)
+		}
+	} else {
+		exits = append(exits, nil)
+	}
+
+	after := b.newBlock(BlockPlain)
+	for _, exit := range exits {
+		if exit == nil {
+			b.edge(cond, after, EdgeFalse)
+		} else {
+			b.edge(exit, after, EdgeNormal)
+		}
+	}
+	b.current = after
+}
+
+func (b *cfgBuilder) loop(stm *LoopStatement) {
+	switch {
+	case stm.WhileExpr != nil:
+	case stm.In != nil:
+	default:
+		// начальное значение счетчика присваивается один раз перед циклом
+		block := b.next()
+		block.Statements = append(block.Statements, stm.For)
+	}
+
+	header := b.enter(BlockLoop)
+	header.Control = stm
+	switch {
+	case stm.WhileExpr != nil:
+		header.Statements = Statements{stm.WhileExpr}
+	case stm.In != nil:
+		header.Statements = Statements{stm.In}
+	default:
+		header.Statements = Statements{stm.To}
+	}
+
+	after := &BasicBlock{Kind: BlockPlain}
+	b.loops = append(b.loops, cfgLoop{header: header, after: after})
+
+	b.current = b.newBlock(BlockPlain)
+	b.edge(header, b.current, EdgeTrue)
+	b.block(stm.Body)
+	if b.current != nil {
+		b.edge(b.current, header, EdgeBack)
+	}
+	b.loops = b.loops[:len(b.loops)-1]
+
+	b.place(after)
+	b.edge(header, after, EdgeFalse)
+	b.current = after
+}
+
+func (b *cfgBuilder) try(stm TryStatement) {
+	catch := &BasicBlock{Kind: BlockCatch}
+
+	b.catches = append(b.catches, catch)
+	b.enter(BlockPlain)
+	b.block(stm.Body)
+	bodyExit := b.current
+	b.catches = b.catches[:len(b.catches)-1]
+
+	// исключение в блоке Исключение уходит в объемлющую Попытку
+	b.current = b.place(catch)
+	b.block(stm.Catch)
+	catchExit := b.current
+
+	after := b.newBlock(BlockPlain)
+	for _, exit := range []*BasicBlock{bodyExit, catchExit} {
+		if exit != nil {
+			b.edge(exit, after, EdgeNormal)
+		}
+	}
+	b.current = after
+}
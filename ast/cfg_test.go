@@ -0,0 +1,139 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCFG(t *testing.T) {
+	build := func(t *testing.T, code string) *CFG {
+		a := NewAST(code)
+		if !assert.NoError(t, a.Parse()) {
+			t.FailNow()
+		}
+		return BuildCFG(a.ModuleStatement.Body[0].(*FunctionOrProcedure))
+	}
+
+	// edges описывает переходы графа строками "B2 -> B3 true"
+	edges := func(g *CFG) []string {
+		var result []string
+		for _, block := range g.Blocks {
+			for _, e := range block.Succs {
+				result = append(result, strings.TrimSpace(fmt.Sprintf("B%d -> B%d %s", block.Index, e.To.Index, e.Kind)))
+			}
+		}
+		return result
+	}
+
+	t.Run("if", func(t *testing.T) {
+		g := build(t, `Функция Ф(а)
+	Если а = 1 Тогда
+		Возврат 1;
+	ИначеЕсли а = 2 Тогда
+		б = 2;
+	Иначе
+		ВызватьИсключение "ошибка";
+	КонецЕсли;
+	Возврат б;
+КонецФункции`)
+
+		assert.Equal(t, []string{
+			"B0 -> B2",
+			"B2 -> B3",
+			"B3 -> B4 true",
+			"B3 -> B5 false",
+			"B4 -> B1 return",
+			"B5 -> B6 true",
+			"B5 -> B7 false",
+			"B6 -> B8",
+			"B7 -> B1 throw",
+			"B8 -> B1 return",
+		}, edges(g))
+		assert.Equal(t, BlockCondition, g.Blocks[5].Kind)
+		assert.Len(t, g.Reachable(), len(g.Blocks))
+	})
+	t.Run("loops", func(t *testing.T) {
+		g := build(t, `Процедура П(Список)
+	Для Каждого Эл Из Список Цикл
+		Если Эл Тогда
+			Продолжить;
+		КонецЕсли;
+		Пока Истина Цикл
+			Прервать;
+			Сообщить(1);
+		КонецЦикла;
+	КонецЦикла;
+	Для Инд = 1 По 3 Цикл
+	КонецЦикла;
+КонецПроцедуры`)
+
+		assert.Equal(t, []string{
+			"B0 -> B2",
+			"B2 -> B3",
+			"B3 -> B4 true",
+			"B3 -> B12 false",
+			"B4 -> B5",
+			"B5 -> B6 true",
+			"B5 -> B7 false",
+			"B6 -> B3 back",
+			"B7 -> B8",
+			"B8 -> B9 true",
+			"B8 -> B11 false",
+			"B9 -> B11 break",
+			"B10 -> B8 back",
+			"B11 -> B3 back",
+			"B12 -> B13",
+			"B13 -> B14 true",
+			"B13 -> B15 false",
+			"B14 -> B13 back",
+			"B15 -> B1",
+		}, edges(g))
+
+		// после Прервать блок без предшественников
+		reachable := g.Reachable()
+		assert.False(t, reachable[g.Blocks[10]])
+		assert.Equal(t, `Сообщить(1)`, statementText(g.Blocks[10].Statements[0]))
+		assert.IsType(t, &ExpStatement{}, g.Blocks[12].Statements[0])
+	})
+	t.Run("try and goto", func(t *testing.T) {
+		g := build(t, `Процедура П()
+	Перейти ~Конец;
+	Попытка
+		а = 1;
+		Если а Тогда
+			ВызватьИсключение "ошибка";
+		КонецЕсли;
+	Исключение
+		Сообщить(а);
+	КонецПопытки;
+	~Конец:
+	Возврат;
+КонецПроцедуры`)
+
+		assert.Equal(t, []string{
+			"B0 -> B2",
+			"B2 -> B9 goto",
+			"B3 -> B7 exception",
+			"B3 -> B4",
+			"B4 -> B7 exception",
+			"B4 -> B5 true",
+			"B4 -> B6 false",
+			"B5 -> B7 exception",
+			"B5 -> B7 throw",
+			"B6 -> B7 exception",
+			"B6 -> B8",
+			"B7 -> B8",
+			"B8 -> B9",
+			"B9 -> B1 return",
+		}, edges(g))
+
+		dot := g.DOT()
+		assert.True(t, strings.HasPrefix(dot, "digraph \"П\" {\n"))
+		assert.Contains(t, dot, `B7 [label="B7 catch\lСообщить(а)\l"];`)
+		assert.Contains(t, dot, `B9 [label="B9 label\l~Конец\lВозврат\l"];`)
+		assert.Contains(t, dot, `B5 -> B7 [label="throw" style=dashed];`)
+	})
+}
@@ -0,0 +1,1108 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// CSTToken токен конкретного синтаксического дерева
+type CSTToken struct {
+	Type int // тип токена из грамматики (token_identifier, If, ';'...)
+
+	// Text текст токена в точности как в исходном коде, с регистром букв и переносами строковых литералов
+	Text string
+
+	// Leading пробелы, комментарии и инструкции препроцессора (#Область...) перед токеном
+	Leading string
+	Pos     Position
+}
+
+// ConcreteTree конкретное синтаксическое дерево модуля: токены вместе со всем, что лежит между ними, и AST разобранный из них.
+// Module можно менять, при печати заново формируются только участки измененных узлов
+type ConcreteTree struct {
+	Module   *ModuleStatement
+	Tokens   []CSTToken
+	Trailing string // все что идет после последнего токена
+
+	source   string
+	original ModuleStatement
+}
+
+// место узла в дереве, от него зависит как печатать узел при замене
+type cstSlot int
+
+const (
+	slotStatement  cstSlot = iota
+	slotExpression         // аргумент, условие, операнд
+	slotMember             // обращение после точки
+	slotChain              // левая часть цепочки вызовов
+)
+
+type cstDiff struct {
+	source   string
+	conf     PrintConf
+	module   *ModuleStatement
+	original *ModuleStatement
+	edits    []TextEdit
+	tokens   []CSTToken       // токены исходного кода, читаются только для изменений объявлений Перем и списков в скобках
+	lists    map[int]cstPlace // место каждого оператора исходного дерева по смещению, строится при первом переносе
+}
+
+// cstPlace список операторов исходного дерева и индекс оператора в нем
+type cstPlace struct {
+	list  Statements
+	index int
+}
+
+// ErrNotLocal изменение дерева нельзя напечатать, не перепечатав модуль целиком
+var ErrNotLocal = fmt.Errorf("changes cannot be printed without reprinting the whole module")
+
+// sourceCode исходный код для лексера, когда токены читаются без разбора
+type sourceCode string
+
+func (s sourceCode) SrsCode() string {
+	return string(s)
+}
+
+// CST строит конкретное синтаксическое дерево поверх модуля. Изменения, сделанные в ModuleStatement
+// после вызова, попадут в ConcreteTree.Print
+func (ast *AstNode) CST() (*ConcreteTree, error) {
+	tokens, trailing, err := scanTokens(ast.code)
+	if err != nil {
+		return nil, errors.Wrap(err, "get token error")
+	}
+
+	// с исходным деревом сравниваются изменения. Модуль мог уже поменяться, поэтому разбираем код заново
+	original := NewAST(ast.code)
+	if err := original.Parse(); err != nil {
+		return nil, err
+	}
+
+	return &ConcreteTree{
+		Module:   &ast.ModuleStatement,
+		Tokens:   tokens,
+		Trailing: trailing,
+		source:   ast.code,
+		original: original.ModuleStatement,
+	}, nil
+}
+
+func scanTokens(code string) ([]CSTToken, string, error) {
+	var (
+		tok    Token
+		tokens []CSTToken
+		end    int
+	)
+
+	for {
+		tokenType, err := tok.Next(sourceCode(code))
+		if err != nil {
+			return nil, "", err
+		}
+		if tokenType == EOF {
+			break
+		}
+
+		start := tok.position.Offset
+		tokens = append(tokens, CSTToken{
+			Type:    tokenType,
+			Text:    code[start:tok.offset],
+			Leading: code[end:start],
+			Pos:     tok.position,
+		})
+		end = tok.offset
+	}
+
+	return tokens, code[end:], nil
+}
+
+// String собирает текст из токенов, он всегда совпадает с исходным кодом до байта
+func (c *ConcreteTree) String() string {
+	builder := strings.Builder{}
+	builder.Grow(len(c.source))
+
+	for _, t := range c.Tokens {
+		builder.WriteString(t.Leading)
+		builder.WriteString(t.Text)
+	}
+	builder.WriteString(c.Trailing)
+
+	return builder.String()
+}
+
+// TokensOf возвращает токены, из которых разобран узел
+func (c *ConcreteTree) TokensOf(stm Statement) []CSTToken {
+	r := RangeOf(stm)
+	if r.End.Line == 0 {
+		return nil
+	}
+
+	from := sort.Search(len(c.Tokens), func(i int) bool { return c.Tokens[i].Pos.Offset >= r.Start.Offset })
+	to := sort.Search(len(c.Tokens), func(i int) bool { return c.Tokens[i].Pos.Offset >= r.End.Offset })
+
+	return c.Tokens[from:to]
+}
+
+// Print печатает модуль. Пока Module не менялся, результат совпадает с исходным кодом до байта.
+// Измененные узлы печатаются с настройками conf, остальной текст, включая комментарии и пробелы, остается как был.
+// Если изменение нельзя напечатать по месту, возвращается ErrNotLocal, модуль целиком печатает AstNode.Print
+func (c *ConcreteTree) Print(conf PrintConf) (string, error) {
+	edits, err := c.edits(conf)
+	if err != nil {
+		return "", err
+	}
+
+	builder := strings.Builder{}
+	builder.Grow(len(c.source))
+
+	last := 0
+	for _, e := range edits {
+		builder.WriteString(c.source[last:e.Range.Start.Offset])
+		builder.WriteString(e.NewText)
+		last = e.Range.End.Offset
+	}
+	builder.WriteString(c.source[last:])
+
+	return builder.String(), nil
+}
+
+func (c *ConcreteTree) edits(conf PrintConf) ([]TextEdit, error) {
+	d := &cstDiff{source: c.source, conf: conf, module: c.Module, original: &c.original, tokens: c.Tokens}
+
+	if !d.variables(d.globalVariables(c.original.GlobalVariables), d.globalVariables(c.Module.GlobalVariables), d.moduleVariablesAt) {
+		return nil, ErrNotLocal
+	}
+	if !d.moduleBody(c.original.Body, c.Module.Body) {
+		return nil, ErrNotLocal
+	}
+
+	sort.SliceStable(d.edits, func(i, j int) bool { return d.edits[i].Range.Start.Offset < d.edits[j].Range.Start.Offset })
+	return d.edits, nil
+}
+
+// moduleBody сопоставляет операторы модуля. В пустой вложенный список операторы вставляет родитель, печатая
+// себя заново, а у модуля родителя нет, поэтому в пустой модуль операторы дописываются в конец кода
+func (d *cstDiff) moduleBody(orig, mod Statements) bool {
+	if len(orig) == 0 && len(mod) > 0 {
+		at := offsetPosition(d.source, len(d.source))
+		text := d.printList(mod, "")
+		if _, ok := mod[len(mod)-1].(*FunctionOrProcedure); !ok {
+			text += ";"
+		}
+		switch {
+		case strings.TrimSpace(d.source) == "":
+		case strings.HasSuffix(d.source, "\n"):
+			text = "\n" + text
+		default:
+			text = "\n\n" + text
+		}
+		d.replace(Range{Start: at, End: at}, text)
+		return true
+	}
+
+	return d.body(orig, mod)
+}
+
+// body сопоставляет списки операторов. Одинаковые операторы оставляем как есть, остальные сравниваем
+// попарно, а если на месте одних операторов стоит другое их количество, заменяем участок целиком
+func (d *cstDiff) body(orig, mod Statements) bool {
+	if len(orig) == 0 && len(mod) == 0 {
+		return true
+	}
+	if len(orig) == 0 {
+		return false
+	}
+	if len(mod) == 0 {
+		// удалить все операторы можно по месту, а вставить в пустой список - только напечатав родителя
+		for _, item := range orig {
+			if RangeOf(item).End.Line == 0 {
+				return false
+			}
+		}
+		d.delete(orig)
+		return true
+	}
+
+	// совпадающие начало и конец отбрасываем сразу, что бы не строить таблицу сопоставления для всего модуля
+	prefix := 0
+	for prefix < len(orig) && prefix < len(mod) && reflect.DeepEqual(orig[prefix], mod[prefix]) {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(orig)-prefix && suffix < len(mod)-prefix && reflect.DeepEqual(orig[len(orig)-1-suffix], mod[len(mod)-1-suffix]) {
+		suffix++
+	}
+
+	matches := align(orig[prefix:len(orig)-suffix], mod[prefix:len(mod)-suffix])
+	matches = append(matches, [2]int{len(orig) - suffix - prefix, len(mod) - suffix - prefix})
+
+	from, modFrom := prefix, prefix
+	for _, match := range matches {
+		if !d.gap(orig, from, prefix+match[0], mod[modFrom:prefix+match[1]]) {
+			return false
+		}
+		from, modFrom = prefix+match[0]+1, prefix+match[1]+1
+	}
+
+	return true
+}
+
+// gap заменяет операторы orig[from:to], которым не нашлось одинаковых в измененном списке, на операторы mod
+func (d *cstDiff) gap(orig Statements, from, to int, mod Statements) bool {
+	o := orig[from:to]
+	if len(o) > 0 && d.moved(o, mod) {
+		return true
+	}
+	if len(o) == len(mod) {
+		for i := range o {
+			if !d.node(o[i], mod[i], slotStatement, precLowest) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, item := range orig {
+		if RangeOf(item).End.Line == 0 {
+			return false
+		}
+	}
+
+	// после метки стоит двоеточие, а не точка с запятой, и в участок метки оно не входит. Удалить метку вместе
+	// со следующим за ней оператором можно, а печать и вставку рядом с меткой оставляем родителю
+	deleteBefore := len(mod) == 0 && to < len(orig)
+	for _, item := range mod {
+		if isLabel(item) {
+			return false
+		}
+	}
+	if len(o) > 0 && isLabel(o[len(o)-1]) && !deleteBefore {
+		return false
+	}
+	if from > 0 && isLabel(orig[from-1]) && !deleteBefore && !(len(o) > 0 && len(mod) > 0) {
+		return false
+	}
+
+	indent := lineIndent(d.source, RangeOf(orig[0]).Start.Offset)
+
+	switch {
+	case len(o) > 0 && len(mod) > 0:
+		d.replace(Range{Start: RangeOf(o[0]).Start, End: RangeOf(o[len(o)-1]).End}, d.printList(mod, indent))
+	case len(o) == 0 && from > 0:
+		at := RangeOf(orig[from-1]).End
+		d.replace(Range{Start: at, End: at}, separator(orig[from-1], indent)+d.printList(mod, indent))
+	case len(o) == 0:
+		at := RangeOf(orig[0]).Start
+		d.replace(Range{Start: at, End: at}, d.printList(mod, indent)+separator(mod[len(mod)-1], indent))
+	default:
+		d.delete(o)
+	}
+
+	return true
+}
+
+// delete удаляет операторы. Комментарии между ними на отдельных строках остаются
+func (d *cstDiff) delete(items Statements) {
+	from := 0
+	for i := 1; i <= len(items); i++ {
+		if i < len(items) && !strings.Contains(d.source[RangeOf(items[i-1]).End.Offset:RangeOf(items[i]).Start.Offset], "//") {
+			continue
+		}
+
+		d.replace(d.deletion(items[from:i]), "")
+		from = i
+	}
+}
+
+// deletion участок удаляемых подряд операторов вместе с их точкой с запятой (двоеточием метки) и комментарием
+// в конце строки. Комментарии перед операторами и после них относятся к соседям и остаются. Если операторы
+// занимают строки целиком, строки удаляются вместе с отступом и переводом строки
+func (d *cstDiff) deletion(items Statements) Range {
+	start, end := RangeOf(items[0]).Start.Offset, d.separatorEnd(items[len(items)-1])
+	end = skipBlanks(d.source, end)
+	if strings.HasPrefix(d.source[end:], "//") {
+		end += strings.IndexByte(d.source[end:]+"\n", '\n')
+	}
+	if rest := strings.TrimPrefix(d.source[end:], "\r"); rest != "" && rest[0] != '\n' {
+		// за удаляемыми операторами на той же строке есть другой оператор, он займет их место
+		return Range{Start: offsetPosition(d.source, start), End: offsetPosition(d.source, end)}
+	}
+
+	lineStart := strings.LastIndexByte(d.source[:start], '\n') + 1
+	switch {
+	case strings.TrimLeft(d.source[lineStart:start], " \t") != "":
+		// перед удаляемыми операторами на строке есть другой оператор, перевод строки остается ему
+		start = len(strings.TrimRight(d.source[:start], " \t"))
+	case end == len(d.source) && lineStart > 0:
+		// последняя строка файла без перевода строки: удаляем перевод строки перед ней
+		start = len(strings.TrimSuffix(d.source[:lineStart-1], "\r"))
+	default:
+		end = min(len(d.source), end+strings.IndexByte(d.source[end:]+"\n", '\n')+1)
+		start = lineStart
+	}
+
+	return Range{Start: offsetPosition(d.source, start), End: offsetPosition(d.source, end)}
+}
+
+// separatorEnd конец оператора вместе с точкой с запятой после него (двоеточием после метки), если она есть
+func (d *cstDiff) separatorEnd(item Statement) int {
+	end := RangeOf(item).End.Offset
+	if i := skipBlanks(d.source, end); i < len(d.source) && (d.source[i] == ';' || (d.source[i] == ':' && isLabel(item))) {
+		return i + 1
+	}
+
+	return end
+}
+
+// moved заменяет операторы o текстом операторов mod, если это операторы исходного дерева без изменений,
+// стоявшие в одном списке подряд (например, ветка Иначе, которая заменяет Если Ложь). Текст переносится
+// вместе с комментариями между операторами и в конце строки последнего из них, меняется только отступ
+func (d *cstDiff) moved(o, mod Statements) bool {
+	if len(mod) == 0 || PositionOf(mod[0]).Line == 0 {
+		return false
+	}
+
+	if d.lists == nil {
+		d.lists = map[int]cstPlace{}
+		var visit func(list Statements)
+		visit = func(list Statements) {
+			for i, item := range list {
+				d.lists[PositionOf(item).Offset] = cstPlace{list: list, index: i}
+				statementLists(item, visit)
+			}
+		}
+		visit(d.original.Body)
+	}
+
+	place, ok := d.lists[PositionOf(mod[0]).Offset]
+	if !ok || place.index+len(mod) > len(place.list) {
+		return false
+	}
+	for i, item := range mod {
+		if !reflect.DeepEqual(item, place.list[place.index+i]) {
+			return false
+		}
+	}
+
+	first, last := RangeOf(mod[0]), RangeOf(mod[len(mod)-1])
+	end := d.separatorEnd(mod[len(mod)-1])
+	text := d.source[first.Start.Offset:end]
+	if end == last.End.Offset && !isLabel(mod[len(mod)-1]) {
+		text += ";"
+	}
+	if comment := skipBlanks(d.source, end); strings.HasPrefix(d.source[comment:], "//") {
+		text += d.source[end : comment+strings.IndexAny(d.source[comment:]+"\n", "\r\n")]
+	}
+
+	from, to := lineIndent(d.source, first.Start.Offset), lineIndent(d.source, RangeOf(o[0]).Start.Offset)
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if rest, ok := strings.CutPrefix(lines[i], from); ok {
+			lines[i] = to + rest
+		}
+	}
+
+	d.replace(Range{Start: RangeOf(o[0]).Start, End: offsetPosition(d.source, d.separatorEnd(o[len(o)-1]))}, strings.Join(lines, "\n"))
+	return true
+}
+
+// statementLists вызывает f для списков операторов, вложенных в оператор
+func statementLists(stm Statement, f func(Statements)) {
+	switch v := stm.(type) {
+	case *FunctionOrProcedure:
+		f(v.Body)
+	case *IfStatement:
+		f(v.TrueBlock)
+		for _, item := range v.IfElseBlock {
+			statementLists(item, f)
+		}
+		f(v.ElseBlock)
+	case *LoopStatement:
+		f(v.Body)
+	case TryStatement:
+		f(v.Body)
+		f(v.Catch)
+	}
+}
+
+// skipBlanks пропускает пробелы и табуляции начиная с offset
+func skipBlanks(code string, offset int) int {
+	for offset < len(code) && (code[offset] == ' ' || code[offset] == '\t') {
+		offset++
+	}
+
+	return offset
+}
+
+// align возвращает пары индексов одинаковых операторов (наибольшая общая подпоследовательность)
+func align(orig, mod Statements) [][2]int {
+	equal := make([][]bool, len(orig))
+	lcs := make([][]int, len(orig)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mod)+1)
+	}
+
+	for i := len(orig) - 1; i >= 0; i-- {
+		equal[i] = make([]bool, len(mod))
+		for j := len(mod) - 1; j >= 0; j-- {
+			if equal[i][j] = reflect.DeepEqual(orig[i], mod[j]); equal[i][j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var result [][2]int
+	for i, j := 0, 0; i < len(orig) && j < len(mod); {
+		switch {
+		case equal[i][j]:
+			result = append(result, [2]int{i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return result
+}
+
+// node сравнивает узел исходного дерева с узлом измененного. false означает, что заменить узел по месту нельзя
+// (у него нет участка в исходном коде) и заменять должен родитель
+func (d *cstDiff) node(orig, mod Statement, slot cstSlot, minPrec int) bool {
+	if reflect.DeepEqual(orig, mod) {
+		return true
+	}
+
+	n := len(d.edits)
+	if d.children(orig, mod, slot) {
+		return true
+	}
+	d.edits = d.edits[:n]
+
+	r := RangeOf(orig)
+	if r.End.Line == 0 || prefixed(orig) {
+		return false
+	}
+
+	d.replace(r, d.print(mod, slot, minPrec, lineIndent(d.source, r.Start.Offset)))
+	return true
+}
+
+// children сравнивает вложенные узлы, если сам узел поменялся только в них
+func (d *cstDiff) children(orig, mod Statement, slot cstSlot) bool {
+	switch o := orig.(type) {
+	case *FunctionOrProcedure:
+		m, ok := mod.(*FunctionOrProcedure)
+		return ok && o.Name == m.Name && o.Type == m.Type && o.Export == m.Export &&
+			reflect.DeepEqual(o.Directives, m.Directives) &&
+			d.params(o, m) &&
+			d.variables(d.explicitVariables(o.ExplicitVariables), d.explicitVariables(m.ExplicitVariables), func() (Position, string, string, bool) {
+				return d.methodVariablesAt(o)
+			}) &&
+			d.body(o.Body, m.Body)
+	case *IfStatement:
+		m, ok := mod.(*IfStatement)
+		if !ok || len(o.IfElseBlock) != len(m.IfElseBlock) || (o.ElseBlock == nil) != (m.ElseBlock == nil) {
+			return false
+		}
+		if !d.expr(o.Expression, m.Expression) || !d.body(o.TrueBlock, m.TrueBlock) {
+			return false
+		}
+		for i := range o.IfElseBlock {
+			oe, ok1 := o.IfElseBlock[i].(*IfStatement)
+			me, ok2 := m.IfElseBlock[i].(*IfStatement)
+			if !ok1 || !ok2 || !d.expr(oe.Expression, me.Expression) || !d.body(oe.TrueBlock, me.TrueBlock) {
+				return false
+			}
+		}
+		return d.body(o.ElseBlock, m.ElseBlock)
+	case *LoopStatement:
+		m, ok := mod.(*LoopStatement)
+		if !ok || reflect.TypeOf(o.For) != reflect.TypeOf(m.For) || (o.To == nil) != (m.To == nil) ||
+			(o.In == nil) != (m.In == nil) || (o.WhileExpr == nil) != (m.WhileExpr == nil) {
+			return false
+		}
+		return d.expr(o.For, m.For) && d.expr(o.To, m.To) && d.expr(o.In, m.In) && d.expr(o.WhileExpr, m.WhileExpr) && d.body(o.Body, m.Body)
+	case TryStatement:
+		m, ok := mod.(TryStatement)
+		return ok && d.body(o.Body, m.Body) && d.body(o.Catch, m.Catch)
+	case ThrowStatement:
+		m, ok := mod.(ThrowStatement)
+		return ok && o.Param != nil && m.Param != nil && d.expr(o.Param, m.Param)
+	case *ReturnStatement:
+		m, ok := mod.(*ReturnStatement)
+		return ok && o.Param != nil && m.Param != nil && d.expr(o.Param, m.Param)
+	case AssignmentStatement:
+		if len(o.Expr.Statements) == 1 && reflect.DeepEqual(o.Expr.Statements[0], mod) && !prefixed(mod) &&
+			PositionOf(mod).Offset > o.Pos.Offset {
+			// от присваивания осталась правая часть, например вызов: убираем левую часть вместе с "="
+			d.replace(Range{Start: o.Pos, End: PositionOf(mod)}, "")
+			return true
+		}
+
+		m, ok := mod.(AssignmentStatement)
+		return ok && d.expr(o.Var, m.Var) && d.exprs(o.Expr.Statements, m.Expr.Statements)
+	case MethodStatement:
+		m, ok := mod.(MethodStatement)
+		if !ok || o.Name != m.Name || o.addStatementField != m.addStatementField {
+			return false
+		}
+		if len(o.Param.Statements) != len(m.Param.Statements) {
+			return d.arguments(o, m)
+		}
+		return d.exprs(o.Param.Statements, m.Param.Statements)
+	case NewObjectStatement:
+		m, ok := mod.(NewObjectStatement)
+		return ok && o.Constructor == m.Constructor && (o.Param.Statements == nil) == (m.Param.Statements == nil) &&
+			d.exprs(o.Param.Statements, m.Param.Statements)
+	case CallChainStatement:
+		m, ok := mod.(CallChainStatement)
+		return ok && o.addStatementField == m.addStatementField &&
+			d.node(o.Call, m.Call, slotChain, precLowest) && d.node(o.Unit, m.Unit, slotMember, precLowest)
+	case ItemStatement:
+		m, ok := mod.(ItemStatement)
+		return ok && d.node(o.Object, m.Object, slot, precLowest) && d.expr(o.Item, m.Item)
+	case TernaryStatement:
+		m, ok := mod.(TernaryStatement)
+		return ok && d.expr(o.Expression, m.Expression) && d.expr(o.TrueBlock, m.TrueBlock) && d.expr(o.ElseBlock, m.ElseBlock)
+	case ExprStatements:
+		m, ok := mod.(ExprStatements)
+		return ok && o.addStatementField == m.addStatementField && d.exprs(o.Statements, m.Statements)
+	case *ExpStatement:
+		m, ok := mod.(*ExpStatement)
+		if !ok || o.Operation != m.Operation || o.addStatementField != m.addStatementField {
+			return false
+		}
+
+		prec := o.Operation.precedence()
+		return d.node(o.Left, m.Left, slotExpression, prec) && d.node(o.Right, m.Right, slotExpression, prec+1)
+	}
+
+	return false
+}
+
+func (d *cstDiff) expr(orig, mod Statement) bool {
+	return d.node(orig, mod, slotExpression, precLowest)
+}
+
+func (d *cstDiff) exprs(orig, mod Statements) bool {
+	if len(orig) != len(mod) {
+		return false
+	}
+
+	for i := range orig {
+		if !d.expr(orig[i], mod[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// params сравнивает параметры метода. Удаленные параметры убираются из заголовка вместе с Знач, значением
+// по умолчанию и запятой, любое другое изменение печатает метод заново
+func (d *cstDiff) params(orig, mod *FunctionOrProcedure) bool {
+	if reflect.DeepEqual(orig.Params, mod.Params) {
+		return true
+	}
+
+	removed, ok := removedItems(len(orig.Params), len(mod.Params), func(i, j int) bool {
+		return reflect.DeepEqual(orig.Params[i], mod.Params[j])
+	})
+	if !ok || !d.scan() {
+		return false
+	}
+
+	open := d.tokenAt(orig.Pos.Offset)
+	for open >= 0 && open < len(d.tokens) && d.tokens[open].Type != '(' {
+		open++
+	}
+
+	return open >= 0 && d.deleteItems(open, removed)
+}
+
+// arguments удаляет из вызова аргументы, которых нет в измененном вызове. Остальные аргументы должны совпадать
+func (d *cstDiff) arguments(orig, mod MethodStatement) bool {
+	removed, ok := removedItems(len(orig.Param.Statements), len(mod.Param.Statements), func(i, j int) bool {
+		return reflect.DeepEqual(orig.Param.Statements[i], mod.Param.Statements[j])
+	})
+	if !ok || !d.scan() {
+		return false
+	}
+
+	// после имени метода идет скобка
+	open := d.tokenAt(orig.Pos.Offset)
+	return open >= 0 && open+1 < len(d.tokens) && d.tokens[open+1].Type == '(' && d.deleteItems(open+1, removed)
+}
+
+// removedItems сопоставляет список из n элементов со списком из m, полученным из него удалением, и отмечает
+// удаленные элементы. equal сравнивает i-й элемент исходного списка с j-м измененного
+func removedItems(n, m int, equal func(i, j int) bool) ([]bool, bool) {
+	if m >= n {
+		return nil, false
+	}
+
+	removed := make([]bool, n)
+	j := 0
+	for i := range removed {
+		if j < m && equal(i, j) {
+			j++
+		} else {
+			removed[i] = true
+		}
+	}
+
+	return removed, j == m
+}
+
+// cstItem элемент списка через запятую: участок [start, end) от первого до конца последнего токена
+// (у пропущенного аргумента пустой, перед запятой) и конец запятой после элемента
+type cstItem struct {
+	start, end, comma int
+}
+
+// deleteItems удаляет из списка в скобках, который открывает токен open, отмеченные элементы. Подряд идущие
+// элементы удаляются вместе с запятой и пробелами после нее, а в конце списка - с запятой перед ними
+func (d *cstDiff) deleteItems(open int, removed []bool) bool {
+	var items []cstItem
+	item, depth := cstItem{start: -1}, 0
+tokens:
+	for i := open + 1; i < len(d.tokens); i++ {
+		t := d.tokens[i]
+		switch {
+		case depth == 0 && (t.Type == ',' || t.Type == ')'):
+			if item.start < 0 {
+				item.start, item.end = t.Pos.Offset, t.Pos.Offset
+			}
+			if t.Type == ')' {
+				items = append(items, item)
+				break tokens
+			}
+
+			item.comma = t.Pos.Offset + len(t.Text)
+			items = append(items, item)
+			item = cstItem{start: -1}
+			continue
+		case t.Type == '(' || t.Type == '[':
+			depth++
+		case t.Type == ')' || t.Type == ']':
+			depth--
+		}
+
+		if item.start < 0 {
+			item.start = t.Pos.Offset
+		}
+		item.end = t.Pos.Offset + len(t.Text)
+	}
+
+	// Метод() - список без элементов, а не с одним пропущенным
+	if len(items) == 1 && items[0].start == items[0].end {
+		items = nil
+	}
+	if len(items) != len(removed) {
+		return false
+	}
+
+	for from := 0; from < len(items); from++ {
+		if !removed[from] {
+			continue
+		}
+		to := from
+		for to < len(items) && removed[to] {
+			to++
+		}
+
+		start, end := items[from].start, items[to-1].end
+		switch {
+		case to < len(items):
+			end = skipBlanks(d.source, items[to-1].comma)
+		case from > 0:
+			start = items[from-1].end
+		}
+		d.replace(Range{Start: offsetPosition(d.source, start), End: offsetPosition(d.source, end)}, "")
+		from = to
+	}
+
+	return true
+}
+
+func (d *cstDiff) replace(r Range, text string) {
+	d.edits = append(d.edits, TextEdit{Range: r, NewText: text})
+}
+
+// print печатает узел для вставки на место slot. minPrec - приоритет операции, операндом которой является узел
+func (d *cstDiff) print(stm Statement, slot cstSlot, minPrec int, indent string) string {
+	builder := &strings.Builder{}
+	p := newAstPrint(builder, d.conf, d.module, false)
+
+	switch slot {
+	case slotStatement:
+		if pf, ok := stm.(*FunctionOrProcedure); ok {
+			p.printFunctionOrProcedure(pf)
+		} else {
+			p.printStatement(stm, 0)
+		}
+	case slotMember:
+		p.printMember(stm)
+	case slotChain:
+		p.printCallChainStatement(stm)
+	default:
+		// префиксная операция в середине выражения захватила бы следующие операции, поэтому тоже в скобках
+		prec := expressionPrecedence(stm)
+		if prec < minPrec || (minPrec > precLowest && (prec == precNot || prec == precUnary)) {
+			p.write("(")
+			p.printExpression(stm, 0)
+			p.write(")")
+		} else {
+			p.printExpression(stm, 0)
+		}
+	}
+
+	// принтер оставляет пробелы в конце строк, в исходном коде они были бы лишними.
+	// Вложенные строки выравниваем по строке, на которой стоял замененный узел
+	text := strings.ReplaceAll(strings.TrimRight(builder.String(), " \n"), " \n", "\n")
+	return strings.ReplaceAll(text, "\n", "\n"+indent)
+}
+
+func (d *cstDiff) printList(items Statements, indent string) string {
+	builder := strings.Builder{}
+	for i, item := range items {
+		if i > 0 {
+			builder.WriteString(separator(items[i-1], indent))
+		}
+		builder.WriteString(d.print(item, slotStatement, precLowest, indent))
+	}
+
+	return builder.String()
+}
+
+func isLabel(stm Statement) bool {
+	_, ok := stm.(*GoToLabelStatement)
+	return ok
+}
+
+// separator текст между оператором и следующим за ним
+func separator(item Statement, indent string) string {
+	if _, ok := item.(*FunctionOrProcedure); ok {
+		return "\n\n" + indent
+	}
+
+	return ";\n" + indent
+}
+
+// prefixed вернет true для узлов с "Не" или унарным минусом, эти операторы не входят в участок узла
+func prefixed(stm Statement) bool {
+	switch v := stm.(type) {
+	case VarStatement:
+		return v.not || v.unaryMinus
+	case CallChainStatement:
+		return v.not || v.unaryMinus
+	case MethodStatement:
+		return v.not
+	case *ExpStatement:
+		return v.not || v.unaryMinus
+	default:
+		return false
+	}
+}
+
+// lineIndent возвращает пробелы и табуляции в начале строки, на которой находится offset
+func lineIndent(code string, offset int) string {
+	start := strings.LastIndexByte(code[:offset], '\n') + 1
+	end := start
+	for end < offset && (code[end] == ' ' || code[end] == '\t') {
+		end++
+	}
+
+	return code[start:end]
+}
+
+// offsetPosition переводит смещение в байтах в строку и колонку
+func offsetPosition(code string, offset int) Position {
+	lineStart := strings.LastIndexByte(code[:offset], '\n') + 1
+
+	return Position{
+		Line:   strings.Count(code[:offset], "\n") + 1,
+		Column: utf8.RuneCountInString(code[lineStart:offset]) + 1,
+		Offset: offset,
+	}
+}
+
+// cstVariable объявление переменной модуля или переменной Перем метода
+type cstVariable struct {
+	Var  VarStatement
+	decl Statement // объявление целиком, по нему определяется, что переменная изменилась
+	text string    // текст объявления для вставки
+}
+
+// cstDeclaration оператор Перем в исходном коде
+type cstDeclaration struct {
+	Range  Range // от директивы или Перем до точки с запятой
+	names  []VarStatement
+	indent string
+}
+
+func (d *cstDiff) globalVariables(vars map[string]GlobalVariables) map[string]cstVariable {
+	result := make(map[string]cstVariable, len(vars))
+	for name, v := range vars {
+		result[name] = cstVariable{Var: v.Var, decl: v, text: d.printVariable(v.Directive, v.Var.Name, v.Export)}
+	}
+
+	return result
+}
+
+func (d *cstDiff) explicitVariables(vars map[string]VarStatement) map[string]cstVariable {
+	result := make(map[string]cstVariable, len(vars))
+	for name, v := range vars {
+		result[name] = cstVariable{Var: v, decl: v, text: d.printVariable(nil, v.Name, false)}
+	}
+
+	return result
+}
+
+func (d *cstDiff) printVariable(directive *DirectiveStatement, name string, export bool) string {
+	builder := &strings.Builder{}
+	p := newAstPrint(builder, d.conf, d.module, false)
+	p.printDirective(directive)
+	p.write(p.kw("Перем "), name)
+	if export {
+		p.write(p.kw(" Экспорт"))
+	}
+	p.write(";")
+
+	return builder.String()
+}
+
+// variables удаляет из операторов Перем исчезнувшие переменные и добавляет новые после последнего оставшегося
+// оператора. Если операторов Перем не было, новые вставляются в место, которое возвращает at: позиция и текст до
+// и после объявлений. Измененная переменная (например, ставшая экспортной) удаляется и объявляется заново
+func (d *cstDiff) variables(orig, mod map[string]cstVariable, at func() (Position, string, string, bool)) bool {
+	removed := map[string]bool{}
+	for name, o := range orig {
+		if m, ok := mod[name]; !ok || !reflect.DeepEqual(o.decl, m.decl) {
+			removed[NormalizeName(o.Var.Name)] = true
+		}
+	}
+
+	var added []cstVariable
+	for name, m := range mod {
+		if o, ok := orig[name]; !ok || !reflect.DeepEqual(o.decl, m.decl) {
+			added = append(added, m)
+		}
+	}
+	if len(removed) == 0 && len(added) == 0 {
+		return true
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].Var.Name < added[j].Var.Name })
+
+	declarations, ok := d.declarations(orig)
+	if !ok {
+		return false
+	}
+
+	// вставка идет раньше удалений: если удаляется последний оператор, вставка стоит в его начале
+	last := -1
+	for i, decl := range declarations {
+		for _, name := range decl.names {
+			if !removed[NormalizeName(name.Name)] {
+				last = i
+				break
+			}
+		}
+	}
+
+	if len(added) > 0 {
+		var pos Position
+		var before, after, indent string
+		switch {
+		case last >= 0:
+			pos, indent = d.lineEnd(declarations[last].Range.End), declarations[last].indent
+			before = "\n" + indent
+		case len(declarations) > 0:
+			decl := declarations[len(declarations)-1]
+			pos, indent = decl.Range.Start, decl.indent
+			after = "\n" + indent
+		default:
+			if pos, before, after, ok = at(); !ok {
+				return false
+			}
+			indent = before[strings.LastIndexByte(before, '\n')+1:]
+		}
+
+		texts := make([]string, len(added))
+		for i, v := range added {
+			texts[i] = strings.ReplaceAll(v.text, "\n", "\n"+indent)
+		}
+		d.replace(Range{Start: pos, End: pos}, before+strings.Join(texts, "\n"+indent)+after)
+	}
+
+	for _, decl := range declarations {
+		var kept []string
+		for _, name := range decl.names {
+			if !removed[NormalizeName(name.Name)] {
+				kept = append(kept, name.Name)
+			}
+		}
+
+		switch {
+		case len(kept) == 0:
+			d.replace(Range{Start: decl.Range.Start, End: d.lineRest(decl.Range.End)}, "")
+		case len(kept) < len(decl.names):
+			d.replace(Range{Start: decl.names[0].Pos, End: decl.names[len(decl.names)-1].End}, strings.Join(kept, ", "))
+		}
+	}
+
+	return true
+}
+
+// declarations находит в исходном коде операторы Перем, в которых объявлены переменные vars
+func (d *cstDiff) declarations(vars map[string]cstVariable) ([]cstDeclaration, bool) {
+	if !d.scan() {
+		return nil, false
+	}
+
+	byStart := map[int]*cstDeclaration{}
+	var result []*cstDeclaration
+	for _, v := range vars {
+		i := d.tokenAt(v.Var.Pos.Offset)
+		if i < 0 || v.Var.End.Line == 0 {
+			return nil, false
+		}
+
+		start := i
+		for start >= 0 && d.tokens[start].Type != Var {
+			start--
+		}
+		end := i
+		for end < len(d.tokens) && d.tokens[end].Type != ';' {
+			end++
+		}
+		if start < 0 || end == len(d.tokens) {
+			return nil, false
+		}
+
+		// директива переменной модуля: &НаКлиенте или &Вместо("Имя")
+		switch {
+		case start >= 1 && d.tokens[start-1].Type == Directive:
+			start--
+		case start >= 4 && d.tokens[start-1].Type == ')' && d.tokens[start-2].Type == String && d.tokens[start-3].Type == '(' && d.tokens[start-4].Type == ExtDirective:
+			start -= 4
+		}
+
+		decl, ok := byStart[start]
+		if !ok {
+			from, to := d.tokens[start].Pos, d.tokens[end]
+			decl = &cstDeclaration{
+				Range:  Range{Start: from, End: offsetPosition(d.source, to.Pos.Offset+len(to.Text))},
+				indent: lineIndent(d.source, from.Offset),
+			}
+			byStart[start] = decl
+			result = append(result, decl)
+		}
+		decl.names = append(decl.names, v.Var)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Range.Start.Offset < result[j].Range.Start.Offset })
+	declarations := make([]cstDeclaration, len(result))
+	for i, decl := range result {
+		sort.Slice(decl.names, func(i, j int) bool { return decl.names[i].Pos.Offset < decl.names[j].Pos.Offset })
+		declarations[i] = *decl
+	}
+
+	return declarations, true
+}
+
+// scan читает токены исходного кода, если их еще нет
+func (d *cstDiff) scan() bool {
+	if d.tokens == nil {
+		tokens, _, err := scanTokens(d.source)
+		if err != nil {
+			return false
+		}
+		d.tokens = tokens
+	}
+
+	return true
+}
+
+// tokenAt возвращает индекс токена, который начинается со смещения offset, или -1
+func (d *cstDiff) tokenAt(offset int) int {
+	i := sort.Search(len(d.tokens), func(i int) bool { return d.tokens[i].Pos.Offset >= offset })
+	if i == len(d.tokens) || d.tokens[i].Pos.Offset != offset {
+		return -1
+	}
+
+	return i
+}
+
+// lineRest продлевает конец удаляемого участка до начала следующей строки, если до конца строки только пробелы
+func (d *cstDiff) lineRest(end Position) Position {
+	rest := strings.TrimLeft(d.source[end.Offset:], " \t\r")
+	if rest != "" && rest[0] != '\n' {
+		return end
+	}
+
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "\n"), " \t")
+	return offsetPosition(d.source, len(d.source)-len(rest))
+}
+
+// lineEnd переносит позицию в конец строки, если после нее до конца строки только комментарий
+func (d *cstDiff) lineEnd(pos Position) Position {
+	line := d.source[pos.Offset:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	if rest := strings.TrimSpace(line); rest != "" && !strings.HasPrefix(rest, "//") {
+		return pos
+	}
+
+	return offsetPosition(d.source, pos.Offset+len(strings.TrimRight(line, "\r")))
+}
+
+// moduleVariablesAt место для переменных модуля, если в исходном коде их не было: перед первым токеном
+func (d *cstDiff) moduleVariablesAt() (Position, string, string, bool) {
+	after := "\n"
+	if len(d.module.Body) > 0 {
+		after = "\n\n"
+	}
+
+	if len(d.tokens) == 0 {
+		return offsetPosition(d.source, len(d.source)), "", after, true
+	}
+
+	return d.tokens[0].Pos, "", after, true
+}
+
+// methodVariablesAt место для переменных Перем, если в методе их не было: после заголовка метода
+func (d *cstDiff) methodVariablesAt(pf *FunctionOrProcedure) (Position, string, string, bool) {
+	i := d.tokenAt(pf.Pos.Offset)
+	if i < 0 {
+		return Position{}, "", "", false
+	}
+	for i < len(d.tokens) && d.tokens[i].Type != ')' {
+		i++
+	}
+	if i == len(d.tokens) {
+		return Position{}, "", "", false
+	}
+	if i+1 < len(d.tokens) && d.tokens[i+1].Type == Export {
+		i++
+	}
+
+	indent := lineIndent(d.source, pf.Pos.Offset) + strings.Repeat(" ", d.conf.Margin)
+	if len(pf.Body) > 0 {
+		indent = lineIndent(d.source, RangeOf(pf.Body[0]).Start.Offset)
+	}
+
+	end := d.tokens[i]
+	return offsetPosition(d.source, end.Pos.Offset+len(end.Text)), "\n" + indent, "", true
+}
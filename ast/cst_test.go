@@ -0,0 +1,269 @@
+package ast
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const cstCode = `// Модуль для проверки
+#Область ПрограммныйИнтерфейс
+
+&НаСервере
+Функция  Сумма(а, Знач б = 10)   Экспорт
+	Если а > 0 тогда // положительное
+		Возврат а * б;
+	КонецЕсли;
+
+	текст = "первая строка
+	|вторая строка";
+	Сообщить(текст) ;
+	Возврат   0;
+КонецФункции
+
+#КонецОбласти
+`
+
+func TestConcreteTree(t *testing.T) {
+	parse := func(code string) (*AstNode, *ConcreteTree) {
+		a := NewAST(code)
+		if !assert.NoError(t, a.Parse()) {
+			t.FailNow()
+		}
+
+		cst, err := a.CST()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		return a, cst
+	}
+	print := func(cst *ConcreteTree) string {
+		text, err := cst.Print(PrintConf{Margin: 4})
+		assert.NoError(t, err)
+		return text
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		fileData, err := os.ReadFile("testdata")
+		assert.NoError(t, err)
+
+		_, cst := parse(string(fileData))
+		assert.Equal(t, string(fileData), cst.String())
+		assert.Equal(t, string(fileData), print(cst))
+
+		_, cst = parse(cstCode)
+		assert.Equal(t, cstCode, cst.String())
+		assert.Equal(t, cstCode, print(cst))
+		assert.Equal(t, "&НаСервере", cst.Tokens[0].Text)
+		assert.Equal(t, "// Модуль для проверки\n#Область ПрограммныйИнтерфейс\n\n", cst.Tokens[0].Leading)
+		assert.Equal(t, "\n\n#КонецОбласти\n", cst.Trailing)
+	})
+	t.Run("tokens of node", func(t *testing.T) {
+		a, cst := parse(cstCode)
+
+		ifStm := a.ModuleStatement.Body[0].(*FunctionOrProcedure).Body[0].(*IfStatement)
+		tokens := cst.TokensOf(ifStm)
+		if assert.Len(t, tokens, 11) {
+			assert.Equal(t, "Если", tokens[0].Text)
+			assert.Equal(t, "тогда", tokens[4].Text)
+			assert.Equal(t, " // положительное\n\t\t", tokens[5].Leading)
+			assert.Equal(t, "КонецЕсли", tokens[10].Text)
+		}
+	})
+	t.Run("change expression", func(t *testing.T) {
+		a, cst := parse(cstCode)
+
+		pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+		ret := pf.Body[0].(*IfStatement).TrueBlock[0].(*ReturnStatement)
+		ret.Param.(*ExpStatement).Right = &ExpStatement{Operation: OpPlus, Left: VarStatement{Name: "б"}, Right: 1.0}
+
+		expected := `// Модуль для проверки
+#Область ПрограммныйИнтерфейс
+
+&НаСервере
+Функция  Сумма(а, Знач б = 10)   Экспорт
+	Если а > 0 тогда // положительное
+		Возврат а * (б + 1);
+	КонецЕсли;
+
+	текст = "первая строка
+	|вторая строка";
+	Сообщить(текст) ;
+	Возврат   0;
+КонецФункции
+
+#КонецОбласти
+`
+		assert.Equal(t, expected, print(cst))
+	})
+	t.Run("insert and delete statements", func(t *testing.T) {
+		a, cst := parse(cstCode)
+
+		pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+		pf.Body = append(pf.Body[:2], pf.Body[3:]...) // Сообщить(текст)
+		pf.Body[0].(*IfStatement).TrueBlock = append(Statements{MethodStatement{Name: "Сообщить", Param: ExprStatements{Statements: Statements{"ок"}}}}, pf.Body[0].(*IfStatement).TrueBlock...)
+
+		expected := `// Модуль для проверки
+#Область ПрограммныйИнтерфейс
+
+&НаСервере
+Функция  Сумма(а, Знач б = 10)   Экспорт
+	Если а > 0 тогда // положительное
+		Сообщить("ок");
+		Возврат а * б;
+	КонецЕсли;
+
+	текст = "первая строка
+	|вторая строка";
+	Возврат   0;
+КонецФункции
+
+#КонецОбласти
+`
+		assert.Equal(t, expected, print(cst))
+	})
+	t.Run("replace statement", func(t *testing.T) {
+		a, cst := parse(cstCode)
+
+		pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+		pf.Body[0] = &LoopStatement{WhileExpr: VarStatement{Name: "Истина"}, Body: Statements{BreakStatement{}}}
+
+		expected := `// Модуль для проверки
+#Область ПрограммныйИнтерфейс
+
+&НаСервере
+Функция  Сумма(а, Знач б = 10)   Экспорт
+	Пока Истина Цикл
+	    Прервать;
+	КонецЦикла;
+
+	текст = "первая строка
+	|вторая строка";
+	Сообщить(текст) ;
+	Возврат   0;
+КонецФункции
+
+#КонецОбласти
+`
+		assert.Equal(t, expected, print(cst))
+	})
+	t.Run("delete label", func(t *testing.T) {
+		code := "Процедура П()\n\tПерейти ~Конец;\n\t~Лишняя:\n\tа = 1;\n\t~Конец:\n\tВозврат;\nКонецПроцедуры"
+		a, cst := parse(code)
+
+		pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+		pf.Body = append(pf.Body[:1], pf.Body[3:]...)
+
+		assert.Equal(t, "Процедура П()\n\tПерейти ~Конец;\n\t~Конец:\n\tВозврат;\nКонецПроцедуры", print(cst))
+	})
+	t.Run("delete statements with comments", func(t *testing.T) {
+		code := "Процедура П()\n\t// про а\n\tа = 1; // хвост а\n\t// про б\n\tб = 2; // хвост б\n\t// про в\n\tв = 3; // хвост в\nКонецПроцедуры"
+		remove := func(i int) string {
+			a, cst := parse(code)
+			pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+			pf.Body = append(pf.Body[:i:i], pf.Body[i+1:]...)
+			return print(cst)
+		}
+
+		assert.Equal(t, "Процедура П()\n\t// про а\n\t// про б\n\tб = 2; // хвост б\n\t// про в\n\tв = 3; // хвост в\nКонецПроцедуры", remove(0))
+		assert.Equal(t, "Процедура П()\n\t// про а\n\tа = 1; // хвост а\n\t// про б\n\t// про в\n\tв = 3; // хвост в\nКонецПроцедуры", remove(1))
+		assert.Equal(t, "Процедура П()\n\t// про а\n\tа = 1; // хвост а\n\t// про б\n\tб = 2; // хвост б\n\t// про в\nКонецПроцедуры", remove(2))
+	})
+	t.Run("delete statements on one line", func(t *testing.T) {
+		a, cst := parse("а = 1; б = 2; в = 3; // хвост\nг = 4;")
+		a.ModuleStatement.Body = Statements{a.ModuleStatement.Body[1], a.ModuleStatement.Body[3]}
+		assert.Equal(t, "б = 2;\nг = 4;", print(cst))
+
+		a, cst = parse("а = 1;\n// комментарий\nб = 2;")
+		a.ModuleStatement.Body = a.ModuleStatement.Body[:1]
+		assert.Equal(t, "а = 1;\n// комментарий", print(cst))
+	})
+	t.Run("hoist statements", func(t *testing.T) {
+		a, cst := parse("Процедура П()\n\tЕсли Ложь Тогда\n\t\tг = 0;\n\tИначе\n\t\tа = 1; // первый\n\t\t// между\n\t\tб = 2\n\tКонецЕсли;\nКонецПроцедуры")
+		pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+		pf.Body = pf.Body[0].(*IfStatement).ElseBlock
+
+		assert.Equal(t, "Процедура П()\n\tа = 1; // первый\n\t// между\n\tб = 2;\nКонецПроцедуры", print(cst))
+	})
+	t.Run("module variables", func(t *testing.T) {
+		code := "// переменные\nПерем а, б Экспорт; // комментарий\n&НаКлиенте\nПерем в;\n\nПроцедура П()\nКонецПроцедуры\n"
+		a, cst := parse(code)
+
+		vars := a.ModuleStatement.GlobalVariables
+		delete(vars, "б")
+		delete(vars, "в")
+		vars["г"] = GlobalVariables{Var: VarStatement{Name: "г"}, Export: true}
+
+		assert.Equal(t, "// переменные\nПерем а Экспорт; // комментарий\nПерем г Экспорт;\n\nПроцедура П()\nКонецПроцедуры\n", print(cst))
+	})
+	t.Run("first module variable", func(t *testing.T) {
+		a, cst := parse("// комментарий\nПроцедура П()\nКонецПроцедуры")
+		a.ModuleStatement.GlobalVariables = map[string]GlobalVariables{"а": {Var: VarStatement{Name: "а"}}}
+
+		assert.Equal(t, "// комментарий\nПерем а;\n\nПроцедура П()\nКонецПроцедуры", print(cst))
+	})
+	t.Run("method variables", func(t *testing.T) {
+		code := "Процедура П() Экспорт\n\tПерем а;\n\tПерем б, в;\n\n\tа = 1;\nКонецПроцедуры\n\nПроцедура Д()\n\tб = 1;\nКонецПроцедуры"
+		a, cst := parse(code)
+
+		p := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+		delete(p.ExplicitVariables, "а")
+		delete(p.ExplicitVariables, "в")
+		d := a.ModuleStatement.Body[1].(*FunctionOrProcedure)
+		d.ExplicitVariables["б"] = VarStatement{Name: "б"}
+
+		assert.Equal(t, "Процедура П() Экспорт\n\tПерем б;\n\n\tа = 1;\nКонецПроцедуры\n\nПроцедура Д()\n\tПерем б;\n\tб = 1;\nКонецПроцедуры", print(cst))
+	})
+	t.Run("delete params and arguments", func(t *testing.T) {
+		code := "Процедура П(Знач а, б = \",\", в)\nКонецПроцедуры\n\nП(1, Строка(2, 3), 4); // вызов\nП(, 2);\nП(1,\n\t// второй\n\t2, 3);"
+		// удаляет параметры и аргументы [from, to)
+		remove := func(from, to int) string {
+			a, cst := parse(code)
+			pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+			pf.Params = append(pf.Params[:from:from], pf.Params[to:]...)
+			for i, item := range a.ModuleStatement.Body[1:] {
+				call := item.(MethodStatement)
+				args := call.Param.Statements
+				call.Param.Statements = append(args[:from:from], args[min(to, len(args)):]...)
+				a.ModuleStatement.Body[i+1] = call
+			}
+			return print(cst)
+		}
+
+		assert.Equal(t, "Процедура П(б = \",\", в)\nКонецПроцедуры\n\nП(Строка(2, 3), 4); // вызов\nП(2);\nП(\n\t// второй\n\t2, 3);", remove(0, 1))
+		assert.Equal(t, "Процедура П(Знач а, в)\nКонецПроцедуры\n\nП(1, 4); // вызов\nП();\nП(1,\n\t// второй\n\t3);", remove(1, 2))
+		assert.Equal(t, "Процедура П(Знач а)\nКонецПроцедуры\n\nП(1); // вызов\nП();\nП(1);", remove(1, 3))
+		assert.Equal(t, "Процедура П()\nКонецПроцедуры\n\nП(); // вызов\nП();\nП();", remove(0, 3))
+	})
+	t.Run("keep right side of assignment", func(t *testing.T) {
+		a, cst := parse("Процедура П()\n\tа = Вычислить(\n\t\t1); // вызов\nКонецПроцедуры")
+		pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+		pf.Body[0] = pf.Body[0].(AssignmentStatement).Expr.Statements[0]
+
+		assert.Equal(t, "Процедура П()\n\tВычислить(\n\t\t1); // вызов\nКонецПроцедуры", print(cst))
+	})
+	t.Run("delete all statements", func(t *testing.T) {
+		a, cst := parse("Процедура П()\n\t// комментарий\n\tа = 1;\n\tб = 2; // хвост\nКонецПроцедуры")
+		a.ModuleStatement.Body[0].(*FunctionOrProcedure).Body = Statements{}
+
+		assert.Equal(t, "Процедура П()\n\t// комментарий\nКонецПроцедуры", print(cst))
+	})
+	t.Run("empty module body", func(t *testing.T) {
+		a, cst := parse("Перем а; // переменная\n")
+		a.ModuleStatement.Body = Statements{&FunctionOrProcedure{Name: "П", Type: PFTypeProcedure}}
+		assert.Equal(t, "Перем а; // переменная\n\nПроцедура П()\nКонецПроцедуры", print(cst))
+
+		a, cst = parse("Процедура П()\nКонецПроцедуры\nСообщить(1) ;\n")
+		a.ModuleStatement.Body = nil
+		assert.Equal(t, "", print(cst))
+	})
+	t.Run("not local", func(t *testing.T) {
+		a, cst := parse("а = 1;\nб = 2;")
+		a.ModuleStatement.Body = append(Statements{&GoToLabelStatement{Name: "М"}}, a.ModuleStatement.Body...)
+
+		_, err := cst.Print(PrintConf{Margin: 4})
+		assert.ErrorIs(t, err, ErrNotLocal)
+	})
+}
@@ -0,0 +1,270 @@
+package ast
+
+// ScriptVariant вариант встроенного языка 1С
+type ScriptVariant int
+
+const (
+	ScriptVariantRussian ScriptVariant = iota
+	ScriptVariantEnglish
+)
+
+// dictionary двуязычный словарь, ключ - имя в нижнем регистре на любом из языков,
+// значение - пара (русское имя, английское имя), индексируется вариантом языка
+type dictionary map[string][2]string
+
+// englishKeywords ключевые слова и операторы в английском варианте языка
+var englishKeywords = map[string]string{
+	"Процедура":         "Procedure",
+	"КонецПроцедуры":    "EndProcedure",
+	"Функция":           "Function",
+	"КонецФункции":      "EndFunction",
+	"Перем":             "Var",
+	"Знач":              "Val",
+	"Экспорт":           "Export",
+	"Если":              "If",
+	"Тогда":             "Then",
+	"ИначеЕсли":         "ElsIf",
+	"Иначе":             "Else",
+	"КонецЕсли":         "EndIf",
+	"Для":               "For",
+	"Каждого":           "Each",
+	"Из":                "In",
+	"По":                "To",
+	"Пока":              "While",
+	"Цикл":              "Do",
+	"КонецЦикла":        "EndDo",
+	"Прервать":          "Break",
+	"Продолжить":        "Continue",
+	"Попытка":           "Try",
+	"Исключение":        "Except",
+	"КонецПопытки":      "EndTry",
+	"ВызватьИсключение": "Raise",
+	"Возврат":           "Return",
+	"Перейти":           "Goto",
+	"Новый":             "New",
+	"Истина":            "True",
+	"Ложь":              "False",
+	"Неопределено":      "Undefined",
+	"Не":                "Not",
+	"И":                 "And",
+	"ИЛИ":               "Or",
+}
+
+var directiveNames = newDictionary([][2]string{
+	{"&НаКлиенте", "&AtClient"},
+	{"&НаСервере", "&AtServer"},
+	{"&НаСервереБезКонтекста", "&AtServerNoContext"},
+	{"&НаКлиентеНаСервереБезКонтекста", "&AtClientAtServerNoContext"},
+	{"&НаКлиентеНаСервере", "&AtClientAtServer"},
+	{"&Перед", "&Before"},
+	{"&После", "&After"},
+	{"&Вместо", "&Around"},
+	{"&ИзменениеИКонтроль", "&ChangeAndValidate"},
+})
+
+// globalFunctions функции и процедуры глобального контекста
+var globalFunctions = newDictionary([][2]string{
+	{"Сообщить", "Message"},
+	{"Выполнить", "Execute"},
+	{"Вычислить", "Eval"},
+	{"Тип", "Type"},
+	{"ТипЗнч", "TypeOf"},
+	{"ЗначениеЗаполнено", "ValueIsFilled"},
+	{"Формат", "Format"},
+	{"Строка", "String"},
+	{"Число", "Number"},
+	{"Дата", "Date"},
+	{"Булево", "Boolean"},
+	{"НСтр", "NStr"},
+	{"СтрНайти", "StrFind"},
+	{"СтрДлина", "StrLen"},
+	{"СтрЗаменить", "StrReplace"},
+	{"СтрШаблон", "StrTemplate"},
+	{"СтрРазделить", "StrSplit"},
+	{"СтрСоединить", "StrConcat"},
+	{"СтрНачинаетсяС", "StrStartsWith"},
+	{"СтрЗаканчиваетсяНа", "StrEndsWith"},
+	{"СтрЧислоВхождений", "StrOccurrenceCount"},
+	{"СтрЧислоСтрок", "StrLineCount"},
+	{"СтрПолучитьСтроку", "StrGetLine"},
+	{"СтрСравнить", "StrCompare"},
+	{"Найти", "Find"},
+	{"Лев", "Left"},
+	{"Прав", "Right"},
+	{"Сред", "Mid"},
+	{"СокрЛ", "TrimL"},
+	{"СокрП", "TrimR"},
+	{"СокрЛП", "TrimAll"},
+	{"ВРег", "Upper"},
+	{"НРег", "Lower"},
+	{"ТРег", "Title"},
+	{"ПустаяСтрока", "IsBlankString"},
+	{"Символ", "Char"},
+	{"КодСимвола", "CharCode"},
+	{"Окр", "Round"},
+	{"Цел", "Int"},
+	{"Макс", "Max"},
+	{"Мин", "Min"},
+	{"ТекущаяДата", "CurrentDate"},
+	{"ТекущаяДатаСеанса", "CurrentSessionDate"},
+	{"ТекущаяУниверсальнаяДата", "CurrentUniversalDate"},
+	{"ДобавитьМесяц", "AddMonth"},
+	{"НачалоДня", "BegOfDay"},
+	{"КонецДня", "EndOfDay"},
+	{"НачалоМесяца", "BegOfMonth"},
+	{"КонецМесяца", "EndOfMonth"},
+	{"НачалоГода", "BegOfYear"},
+	{"КонецГода", "EndOfYear"},
+	{"Год", "Year"},
+	{"Месяц", "Month"},
+	{"День", "Day"},
+	{"Час", "Hour"},
+	{"Минута", "Minute"},
+	{"Секунда", "Second"},
+	{"ИнформацияОбОшибке", "ErrorInfo"},
+	{"ОписаниеОшибки", "ErrorDescription"},
+	{"ПодробноеПредставлениеОшибки", "DetailErrorDescription"},
+	{"КраткоеПредставлениеОшибки", "BriefErrorDescription"},
+	{"ЗаписьЖурналаРегистрации", "WriteLogEvent"},
+	{"НачатьТранзакцию", "BeginTransaction"},
+	{"ЗафиксироватьТранзакцию", "CommitTransaction"},
+	{"ОтменитьТранзакцию", "RollbackTransaction"},
+	{"ТранзакцияАктивна", "TransactionActive"},
+	{"УстановитьПривилегированныйРежим", "SetPrivilegedMode"},
+	{"ПривилегированныйРежим", "PrivilegedMode"},
+	{"РольДоступна", "IsInRole"},
+	{"ПредопределенноеЗначение", "PredefinedValue"},
+	{"ЗначениеВСтрокуВнутр", "ValueToStringInternal"},
+	{"ЗначениеИзСтрокиВнутр", "ValueFromStringInternal"},
+	{"XMLСтрока", "XMLString"},
+	{"XMLЗначение", "XMLValue"},
+	{"ПолучитьИмяВременногоФайла", "GetTempFileName"},
+	{"УдалитьФайлы", "DeleteFiles"},
+	{"КопироватьФайл", "FileCopy"},
+	{"ПереместитьФайл", "MoveFile"},
+	{"ОткрытьФорму", "OpenForm"},
+	{"ПолучитьФорму", "GetForm"},
+	{"Оповестить", "Notify"},
+	{"ПоказатьПредупреждение", "ShowMessageBox"},
+	{"ПоказатьВопрос", "ShowQueryBox"},
+	{"Предупреждение", "DoMessageBox"},
+	{"Вопрос", "DoQueryBox"},
+	{"ВыполнитьОбработкуОповещения", "ExecuteNotifyProcessing"},
+	{"ПодключитьОбработчикОжидания", "AttachIdleHandler"},
+	{"ОтключитьОбработчикОжидания", "DetachIdleHandler"},
+	{"ОткрытьСправку", "OpenHelp"},
+	{"ЗаблокироватьДанныеДляРедактирования", "LockDataForEdit"},
+	{"РазблокироватьДанныеДляРедактирования", "UnlockDataForEdit"},
+	{"ПолучитьОбщийМакет", "GetCommonTemplate"},
+	{"ПолучитьОбщуюФорму", "GetCommonForm"},
+	{"ЗаполнитьЗначенияСвойств", "FillPropertyValues"},
+	{"СтрокаСоединенияИнформационнойБазы", "InfoBaseConnectionString"},
+	{"ИмяКомпьютера", "ComputerName"},
+	{"ИмяПользователя", "UserName"},
+	{"ПолноеИмяПользователя", "UserFullName"},
+	{"Base64Строка", "Base64String"},
+	{"Base64Значение", "Base64Value"},
+})
+
+// globalProperties свойства глобального контекста, с которых начинаются цепочки вызовов
+var globalProperties = newDictionary([][2]string{
+	{"Метаданные", "Metadata"},
+	{"Справочники", "Catalogs"},
+	{"Документы", "Documents"},
+	{"Перечисления", "Enums"},
+	{"Константы", "Constants"},
+	{"РегистрыСведений", "InformationRegisters"},
+	{"РегистрыНакопления", "AccumulationRegisters"},
+	{"РегистрыБухгалтерии", "AccountingRegisters"},
+	{"РегистрыРасчета", "CalculationRegisters"},
+	{"ПланыОбмена", "ExchangePlans"},
+	{"ПланыСчетов", "ChartsOfAccounts"},
+	{"ПланыВидовХарактеристик", "ChartsOfCharacteristicTypes"},
+	{"ПланыВидовРасчета", "ChartsOfCalculationTypes"},
+	{"БизнесПроцессы", "BusinessProcesses"},
+	{"Задачи", "Tasks"},
+	{"Обработки", "DataProcessors"},
+	{"Отчеты", "Reports"},
+	{"ЖурналыДокументов", "DocumentJournals"},
+	{"Последовательности", "Sequences"},
+	{"ПользователиИнформационнойБазы", "InfoBaseUsers"},
+	{"ПараметрыСеанса", "SessionParameters"},
+	{"ВнешниеОбработки", "ExternalDataProcessors"},
+	{"ВнешниеОтчеты", "ExternalReports"},
+	{"РегламентныеЗадания", "ScheduledJobs"},
+	{"ФоновыеЗадания", "BackgroundJobs"},
+	{"ЭтотОбъект", "ThisObject"},
+	{"ЭтаФорма", "ThisForm"},
+	{"Элементы", "Items"},
+})
+
+// globalTypes типы, экземпляры которых создаются через Новый
+var globalTypes = newDictionary([][2]string{
+	{"Структура", "Structure"},
+	{"Массив", "Array"},
+	{"Соответствие", "Map"},
+	{"СписокЗначений", "ValueList"},
+	{"ТаблицаЗначений", "ValueTable"},
+	{"ДеревоЗначений", "ValueTree"},
+	{"ФиксированнаяСтруктура", "FixedStructure"},
+	{"ФиксированныйМассив", "FixedArray"},
+	{"ФиксированноеСоответствие", "FixedMap"},
+	{"Запрос", "Query"},
+	{"ПостроительЗапроса", "QueryBuilder"},
+	{"ОписаниеТипов", "TypeDescription"},
+	{"КвалификаторыСтроки", "StringQualifiers"},
+	{"КвалификаторыЧисла", "NumberQualifiers"},
+	{"КвалификаторыДаты", "DateQualifiers"},
+	{"ОписаниеОповещения", "NotifyDescription"},
+	{"БлокировкаДанных", "DataLock"},
+	{"УникальныйИдентификатор", "UUID"},
+	{"ХранилищеЗначения", "ValueStorage"},
+	{"ДвоичныеДанные", "BinaryData"},
+	{"ТекстовыйДокумент", "TextDocument"},
+	{"ТабличныйДокумент", "SpreadsheetDocument"},
+	{"ЧтениеXML", "XMLReader"},
+	{"ЗаписьXML", "XMLWriter"},
+	{"ЧтениеJSON", "JSONReader"},
+	{"ЗаписьJSON", "JSONWriter"},
+	{"ЧтениеТекста", "TextReader"},
+	{"ЗаписьТекста", "TextWriter"},
+	{"Файл", "File"},
+	{"Шрифт", "Font"},
+	{"Цвет", "Color"},
+	{"Картинка", "Picture"},
+	{"ФорматированнаяСтрока", "FormattedString"},
+	{"HTTPСоединение", "HTTPConnection"},
+	{"HTTPЗапрос", "HTTPRequest"},
+	{"ЗащищенноеСоединениеOpenSSL", "OpenSSLSecureConnection"},
+	{"МенеджерВременныхТаблиц", "TempTablesManager"},
+	{"СхемаКомпоновкиДанных", "DataCompositionSchema"},
+	{"КомпоновщикНастроекКомпоновкиДанных", "DataCompositionSettingsComposer"},
+	{"Граница", "Boundary"},
+	{"МоментВремени", "PointInTime"},
+	{"Период", "Period"},
+})
+
+func newDictionary(pairs [][2]string) dictionary {
+	result := make(dictionary, len(pairs)*2)
+	for _, pair := range pairs {
+		result[fastToLower(pair[0])] = pair
+		result[fastToLower(pair[1])] = pair
+	}
+
+	return result
+}
+
+// translate возвращает имя в нужном варианте языка. Если имя уже на нужном языке или его нет в словаре,
+// возвращается написание из исходного кода
+func (d dictionary) translate(name string, variant ScriptVariant) string {
+	pair, ok := d[fastToLower(name)]
+	if !ok {
+		return name
+	}
+
+	if target := pair[variant]; fastToLower(target) != fastToLower(name) {
+		return target
+	}
+
+	return name
+}
@@ -0,0 +1,362 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dumpNode узел отладочного представления: вид узла, ключевые поля, участок кода и дочерние узлы
+type dumpNode struct {
+	field    string // имя поля родителя, в котором лежит узел
+	kind     string
+	attrs    []string
+	span     Range
+	children []*dumpNode
+}
+
+// SExpr возвращает дерево в виде S-выражения в стиле tree-sitter:
+//
+//	(procedure [1:1-3:15] "Пример" (block (call [2:2-2:26] "Сообщить" (arguments (string "Привет, мир!")))))
+//
+// node - *AstNode, *ModuleStatement, Statements или любой узел. Участки [строка:колонка-строка:колонка]
+// выводятся только у узлов с позициями
+func SExpr(node interface{}) string {
+	builder := &strings.Builder{}
+	if n := dumpOf(node); n != nil {
+		n.sexpr(builder)
+	}
+
+	return builder.String()
+}
+
+// Dump возвращает дерево с отступами, по узлу на строку:
+//
+//	procedure "Пример" [1:1-3:15]
+//	  block
+//	    call "Сообщить" [2:2-2:26]
+//	      arguments
+//	        string "Привет, мир!"
+func Dump(node interface{}) string {
+	builder := &strings.Builder{}
+	if n := dumpOf(node); n != nil {
+		n.dump(builder, 0)
+	}
+
+	return builder.String()
+}
+
+func (n *dumpNode) sexpr(builder *strings.Builder) {
+	if n.field != "" {
+		builder.WriteString(n.field + ": ")
+	}
+
+	builder.WriteString("(" + n.kind)
+	if n.span != (Range{}) {
+		builder.WriteString(" " + spanString(n.span))
+	}
+	for _, attr := range n.attrs {
+		builder.WriteString(" " + attr)
+	}
+	for _, child := range n.children {
+		builder.WriteString(" ")
+		child.sexpr(builder)
+	}
+	builder.WriteString(")")
+}
+
+func (n *dumpNode) dump(builder *strings.Builder, depth int) {
+	builder.WriteString(strings.Repeat("  ", depth))
+	if n.field != "" {
+		builder.WriteString(n.field + ": ")
+	}
+
+	builder.WriteString(n.kind)
+	for _, attr := range n.attrs {
+		builder.WriteString(" " + attr)
+	}
+	if n.span != (Range{}) {
+		builder.WriteString(" " + spanString(n.span))
+	}
+	builder.WriteString("\n")
+
+	for _, child := range n.children {
+		child.dump(builder, depth+1)
+	}
+}
+
+func spanString(r Range) string {
+	return fmt.Sprintf("[%d:%d-%d:%d]", r.Start.Line, r.Start.Column, r.End.Line, r.End.Column)
+}
+
+func dumpOf(node interface{}) *dumpNode {
+	switch v := node.(type) {
+	case *AstNode:
+		return dumpOf(&v.ModuleStatement)
+	case ModuleStatement:
+		return dumpOf(&v)
+	case *ModuleStatement:
+		n := &dumpNode{kind: "module"}
+		if v.Name != "" {
+			n.attrs = append(n.attrs, strconv.Quote(v.Name))
+		}
+
+		// порядок переменных модуля берем из исходного кода, а не из map
+		vars := make([]GlobalVariables, 0, len(v.GlobalVariables))
+		for _, g := range v.GlobalVariables {
+			vars = append(vars, g)
+		}
+		sort.Slice(vars, func(i, j int) bool {
+			if vars[i].Var.Pos.Offset != vars[j].Var.Pos.Offset {
+				return vars[i].Var.Pos.Offset < vars[j].Var.Pos.Offset
+			}
+			return vars[i].Var.Name < vars[j].Var.Name
+		})
+		for _, g := range vars {
+			n.add("", dumpVariable(g.Var, g.Export, g.Directive))
+		}
+
+		n.addAll("", v.Body)
+		return n
+	case Statements:
+		n := &dumpNode{kind: "block"}
+		n.addAll("", v)
+		return n
+	default:
+		return dumpStatement(node)
+	}
+}
+
+func dumpVariable(v VarStatement, export bool, directive *DirectiveStatement) *dumpNode {
+	n := &dumpNode{kind: "variable", attrs: []string{strconv.Quote(v.Name)}, span: Range{Start: v.Pos, End: v.End}}
+	if export {
+		n.attrs = append(n.attrs, "export")
+	}
+	if directive != nil {
+		n.add("", dumpStatement(directive))
+	}
+
+	return n
+}
+
+func dumpStatement(stm Statement) *dumpNode {
+	n := &dumpNode{span: RangeOf(stm)}
+
+	switch v := stm.(type) {
+	case nil:
+		return nil
+	case *FunctionOrProcedure:
+		n.kind = IF(v.Type == PFTypeFunction, "function", "procedure")
+		n.attrs = append(n.attrs, strconv.Quote(v.Name))
+		if v.Export {
+			n.attrs = append(n.attrs, "export")
+		}
+		for _, d := range v.Directives {
+			n.add("", dumpStatement(d))
+		}
+		for _, p := range v.Params {
+			n.add("", dumpStatement(p))
+		}
+
+		names := make([]string, 0, len(v.ExplicitVariables))
+		for name := range v.ExplicitVariables {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return v.ExplicitVariables[names[i]].Pos.Offset < v.ExplicitVariables[names[j]].Pos.Offset
+		})
+		for _, name := range names {
+			n.add("", dumpVariable(v.ExplicitVariables[name], false, nil))
+		}
+
+		n.addBlock("", v.Body)
+	case *DirectiveStatement:
+		n.kind = "directive"
+		n.attrs = append(n.attrs, strconv.Quote(v.Name))
+		if v.Src != "" {
+			n.attrs = append(n.attrs, "src="+strconv.Quote(v.Src))
+		}
+	case ParamStatement:
+		n.kind = "param"
+		n.attrs = append(n.attrs, strconv.Quote(v.Name))
+		if v.IsValue {
+			n.attrs = append(n.attrs, "val")
+		}
+		n.add("default", dumpStatement(v.Default))
+	case AssignmentStatement:
+		n.kind = "assignment"
+		n.add("target", dumpStatement(v.Var))
+		n.add("value", dumpExprs(v.Expr))
+	case ExprStatements:
+		n.kind = "group"
+		n.flags(v.addStatementField)
+		n.addAll("", v.Statements)
+	case VarStatement:
+		n.kind = "var"
+		n.attrs = append(n.attrs, strconv.Quote(v.Name))
+		n.flags(v.addStatementField)
+	case *ExpStatement:
+		n.kind = "binary"
+		n.attrs = append(n.attrs, strconv.Quote(v.Operation.String()))
+		n.flags(v.addStatementField)
+		n.add("left", dumpStatement(v.Left))
+		n.add("right", dumpStatement(v.Right))
+	case *IfStatement:
+		n.kind = "if"
+		n.add("condition", dumpStatement(v.Expression))
+		n.addBlock("then", v.TrueBlock)
+		for _, item := range v.IfElseBlock {
+			elseIf := dumpStatement(item)
+			elseIf.kind = "elseif"
+			n.add("", elseIf)
+		}
+		if v.ElseBlock != nil {
+			n.addBlock("else", v.ElseBlock)
+		}
+	case TryStatement:
+		n.kind = "try"
+		n.addBlock("body", v.Body)
+		n.addBlock("catch", v.Catch)
+	case ThrowStatement:
+		n.kind = "throw"
+		n.add("", dumpStatement(v.Param))
+	case *ReturnStatement:
+		n.kind = "return"
+		n.add("", dumpStatement(v.Param))
+	case NewObjectStatement:
+		n.kind = "new"
+		if v.Constructor != "" {
+			n.attrs = append(n.attrs, strconv.Quote(v.Constructor))
+		}
+		n.add("", dumpArguments(v.Param))
+	case CallChainStatement:
+		n.kind = "member"
+		n.flags(v.addStatementField)
+		n.add("object", dumpStatement(v.Call))
+		n.add("member", dumpStatement(v.Unit))
+	case MethodStatement:
+		n.kind = "call"
+		n.attrs = append(n.attrs, strconv.Quote(v.Name))
+		n.flags(v.addStatementField)
+		n.add("", dumpArguments(v.Param))
+		if v.Embedded != nil {
+			n.addBlock("embedded", v.Embedded)
+		}
+	case BreakStatement:
+		n.kind = "break"
+	case ContinueStatement:
+		n.kind = "continue"
+	case *LoopStatement:
+		switch {
+		case v.WhileExpr != nil:
+			n.kind = "while"
+			n.add("condition", dumpStatement(v.WhileExpr))
+		case v.In != nil:
+			n.kind = "for_each"
+			if name, ok := v.For.(string); ok {
+				n.add("var", &dumpNode{kind: "var", attrs: []string{strconv.Quote(name)}})
+			} else {
+				n.add("var", dumpStatement(v.For))
+			}
+			n.add("in", dumpStatement(v.In))
+		default:
+			n.kind = "for"
+			n.add("from", dumpStatement(v.For))
+			n.add("to", dumpStatement(v.To))
+		}
+		n.addBlock("body", v.Body)
+	case TernaryStatement:
+		n.kind = "ternary"
+		n.add("condition", dumpStatement(v.Expression))
+		n.add("then", dumpStatement(v.TrueBlock))
+		n.add("else", dumpStatement(v.ElseBlock))
+	case ItemStatement:
+		n.kind = "index"
+		n.add("object", dumpStatement(v.Object))
+		n.add("item", dumpStatement(v.Item))
+	case GoToStatement:
+		n.kind = "goto"
+		if v.Label != nil {
+			n.attrs = append(n.attrs, strconv.Quote(v.Label.Name))
+		}
+	case *GoToLabelStatement:
+		n.kind = "label"
+		n.attrs = append(n.attrs, strconv.Quote(v.Name))
+	case string:
+		n.kind = "string"
+		n.attrs = append(n.attrs, strconv.Quote(v))
+	case float64:
+		n.kind = "number"
+		n.attrs = append(n.attrs, strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		n.kind = "boolean"
+		n.attrs = append(n.attrs, strconv.FormatBool(v))
+	case time.Time:
+		n.kind = "date"
+		n.attrs = append(n.attrs, v.Format("20060102150405"))
+	case UndefinedStatement:
+		n.kind = "undefined"
+	default:
+		n.kind = fmt.Sprintf("%T", stm)
+	}
+
+	return n
+}
+
+// dumpExprs правая часть присваивания - одно выражение, лишний уровень group не выводим
+func dumpExprs(expr ExprStatements) *dumpNode {
+	if len(expr.Statements) == 1 && expr.addStatementField == (addStatementField{}) {
+		return dumpStatement(expr.Statements[0])
+	}
+
+	return dumpStatement(expr)
+}
+
+func dumpArguments(param ExprStatements) *dumpNode {
+	n := &dumpNode{kind: "arguments"}
+	for _, arg := range param.Statements {
+		if arg == nil {
+			// пропущенный параметр Метод(1, , 3)
+			n.add("", &dumpNode{kind: "missing"})
+			continue
+		}
+		n.add("", dumpStatement(arg))
+	}
+
+	return n
+}
+
+func (n *dumpNode) add(field string, child *dumpNode) {
+	if child == nil {
+		return
+	}
+
+	child.field = field
+	n.children = append(n.children, child)
+}
+
+func (n *dumpNode) addAll(field string, items Statements) {
+	for _, item := range items {
+		n.add(field, dumpStatement(item))
+	}
+}
+
+func (n *dumpNode) addBlock(field string, items Statements) {
+	block := &dumpNode{kind: "block"}
+	block.addAll("", items)
+	n.add(field, block)
+}
+
+func (n *dumpNode) flags(f addStatementField) {
+	if f.not {
+		n.attrs = append(n.attrs, "not")
+	}
+	if f.unaryMinus {
+		n.attrs = append(n.attrs, "minus")
+	}
+	if f.unaryPlus {
+		n.attrs = append(n.attrs, "plus")
+	}
+}
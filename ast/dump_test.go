@@ -0,0 +1,84 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	code := `Перем м Экспорт;
+&НаКлиенте
+Функция Ф(Знач а, б = 1) Экспорт
+	Для Каждого Эл Из а Цикл
+		Если Не Эл.Имя = "1" Тогда
+			Продолжить;
+		ИначеЕсли б Тогда
+			в = ?(а, -б, Новый Массив(1, , 2));
+		КонецЕсли;
+	КонецЦикла;
+	Возврат м[0] + '20240101';
+КонецФункции`
+
+	a := NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	t.Run("dump", func(t *testing.T) {
+		expected := `module
+  variable "м" export [1:7-1:8]
+  function "Ф" export [2:1-12:13]
+    directive "&НаКлиенте" [2:1-2:11]
+    param "а" val [3:16-3:17]
+    param "б" [3:19-3:20]
+      default: number 1
+    block
+      for_each [4:2-10:12]
+        var: var "Эл"
+        in: var "а" [4:20-4:21]
+        body: block
+          if [5:3-9:12]
+            condition: binary "=" not [5:11-5:24]
+              left: member [5:11-5:17]
+                object: var "Эл" [5:11-5:13]
+                member: var "Имя" [5:14-5:17]
+              right: string "1"
+            then: block
+              continue [6:4-6:14]
+            elseif [7:3-8:39]
+              condition: var "б" [7:13-7:14]
+              then: block
+                assignment [8:4-8:38]
+                  target: var "в" [8:4-8:5]
+                  value: ternary [8:8-8:38]
+                    condition: var "а" [8:10-8:11]
+                    then: var "б" minus [8:14-8:15]
+                    else: new "Массив" [8:17-8:37]
+                      arguments
+                        number 1
+                        missing
+                        number 2
+      return [11:2-11:27]
+        binary "+" [11:10-11:27]
+          left: index [11:10-11:14]
+            object: var "м" [11:10-11:11]
+            item: number 0
+          right: date 20240101000000
+`
+		assert.Equal(t, expected, Dump(a))
+	})
+	t.Run("s-expression", func(t *testing.T) {
+		ret := a.ModuleStatement.Body[0].(*FunctionOrProcedure).Body[1]
+		assert.Equal(t, `(return [11:2-11:27] (binary [11:10-11:27] "+" left: (index [11:10-11:14] object: (var [11:10-11:11] "м") item: (number 0)) right: (date 20240101000000)))`, SExpr(ret))
+	})
+	t.Run("without positions", func(t *testing.T) {
+		stm := Statements{
+			AssignmentStatement{Var: VarStatement{Name: "а"}, Expr: ExprStatements{Statements: Statements{&ExpStatement{Operation: OpMul, Left: 2.5, Right: ExprStatements{Statements: Statements{VarStatement{Name: "б"}}}}}}},
+			ThrowStatement{},
+		}
+
+		assert.Equal(t, `(block (assignment target: (var "а") value: (binary "*" left: (number 2.5) right: (group (var "б")))) (throw))`, SExpr(stm))
+		assert.Equal(t, "", Dump(nil))
+	})
+}
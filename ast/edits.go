@@ -0,0 +1,397 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// TextEdit замена участка исходного кода. Пустой участок означает вставку, пустой NewText - удаление.
+// При применении правок используются смещения Range.Start.Offset и Range.End.Offset
+type TextEdit struct {
+	Range   Range
+	NewText string
+}
+
+var ErrOverlappingEdits = fmt.Errorf("edits overlap")
+
+// Edits возвращает минимальный набор правок, превращающих source в код модуля module, полученного
+// из source изменением узлов. Текст неизмененных узлов не затрагивается, измененные печатаются с настройками conf
+func Edits(source string, module *ModuleStatement, conf PrintConf) ([]TextEdit, error) {
+	original := NewAST(source)
+	if err := original.Parse(); err != nil {
+		return nil, err
+	}
+
+	c := &ConcreteTree{Module: module, source: source, original: original.ModuleStatement}
+	return c.Edits(conf)
+}
+
+// Edits возвращает правки, после применения которых к исходному коду получится результат Print
+func (c *ConcreteTree) Edits(conf PrintConf) ([]TextEdit, error) {
+	edits, err := c.edits(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	index := newLineIndex(c.source)
+
+	// замененный узел часто совпадает с напечатанным заново почти целиком, оставляем только отличающиеся части
+	var result []TextEdit
+	for _, e := range edits {
+		start, end := e.Range.Start.Offset, e.Range.End.Offset
+		result = append(result, computeEdits(c.source[start:end], e.NewText, start, index)...)
+	}
+
+	return result, nil
+}
+
+// ComputeEdits возвращает минимальные правки, превращающие текст before в after.
+// Подходит для форматирования, когда дерево не менялось и сравнить можно только текст: ComputeEdits(code, a.Print(conf))
+func ComputeEdits(before, after string) []TextEdit {
+	return computeEdits(before, after, 0, newLineIndex(before))
+}
+
+func computeEdits(before, after string, base int, index *lineIndex) []TextEdit {
+	a, b := splitLines(before), splitLines(after)
+
+	var result []TextEdit
+
+	offsetA := lineOffsets(a)
+	offsetB := lineOffsets(b)
+	for _, h := range diffLines(a, b) {
+		start, end := offsetA[h.i1], offsetA[h.i2]
+		oldText, newText := before[start:end], after[offsetB[h.j1]:offsetB[h.j2]]
+
+		// внутри различающихся строк сужаем правку до различающихся символов
+		prefix := commonPrefix(oldText, newText)
+		suffix := commonSuffix(oldText[prefix:], newText[prefix:])
+
+		start, end = base+start+prefix, base+end-suffix
+		result = append(result, TextEdit{
+			Range:   Range{Start: index.position(start), End: index.position(end)},
+			NewText: newText[prefix : len(newText)-suffix],
+		})
+	}
+
+	return result
+}
+
+// ApplyEdits применяет правки к исходному коду. Правки могут идти в любом порядке, но не должны пересекаться
+func ApplyEdits(source string, edits []TextEdit) (string, error) {
+	sorted, err := sortEdits(edits, len(source))
+	if err != nil {
+		return "", err
+	}
+
+	builder := strings.Builder{}
+	builder.Grow(len(source))
+
+	last := 0
+	for _, e := range sorted {
+		builder.WriteString(source[last:e.Range.Start.Offset])
+		builder.WriteString(e.NewText)
+		last = e.Range.End.Offset
+	}
+	builder.WriteString(source[last:])
+
+	return builder.String(), nil
+}
+
+// CheckEdits проверяет, что правки не выходят за границы исходного кода длины size и не пересекаются между собой
+func CheckEdits(edits []TextEdit, size int) error {
+	_, err := sortEdits(edits, size)
+	return err
+}
+
+func sortEdits(edits []TextEdit, size int) ([]TextEdit, error) {
+	sorted := make([]TextEdit, len(edits))
+	copy(sorted, edits)
+
+	// вставки в одно место применяются в том порядке, в котором переданы
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Range.Start.Offset < sorted[j].Range.Start.Offset })
+
+	for i, e := range sorted {
+		if e.Range.Start.Offset < 0 || e.Range.Start.Offset > e.Range.End.Offset || e.Range.End.Offset > size {
+			return nil, fmt.Errorf("incorrect edit range %d:%d", e.Range.Start.Offset, e.Range.End.Offset)
+		}
+		if i > 0 && e.Range.Start.Offset < sorted[i-1].Range.End.Offset {
+			prev := sorted[i-1].Range
+			return nil, errors.Wrap(ErrOverlappingEdits, fmt.Sprintf("line %d, column %d and line %d, column %d",
+				prev.Start.Line, prev.Start.Column, e.Range.Start.Line, e.Range.Start.Column))
+		}
+	}
+
+	return sorted, nil
+}
+
+// UnifiedDiff применяет правки и возвращает изменения в формате unified diff (как у diff -u) с тремя строками контекста
+func UnifiedDiff(name, source string, edits []TextEdit) (string, error) {
+	result, err := ApplyEdits(source, edits)
+	if err != nil {
+		return "", err
+	}
+
+	return unifiedDiff(name, source, result), nil
+}
+
+const diffContext = 3
+
+func unifiedDiff(name, before, after string) string {
+	a, b := splitLines(before), splitLines(after)
+	hunks := diffLines(a, b)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString("--- a/" + name + "\n")
+	builder.WriteString("+++ b/" + name + "\n")
+
+	for i := 0; i < len(hunks); {
+		// соседние изменения, между которыми мало строк, выводим одним блоком
+		j := i
+		for j+1 < len(hunks) && hunks[j+1].i1-hunks[j].i2 <= 2*diffContext {
+			j++
+		}
+
+		i1, j1 := max(hunks[i].i1-diffContext, 0), max(hunks[i].j1-diffContext, 0)
+		i2, j2 := min(hunks[j].i2+diffContext, len(a)), min(hunks[j].j2+diffContext, len(b))
+		fmt.Fprintf(builder, "@@ -%s +%s @@\n", hunkRange(i1, i2), hunkRange(j1, j2))
+
+		line := i1
+		for _, h := range hunks[i : j+1] {
+			writeDiffLines(builder, " ", a[line:h.i1])
+			writeDiffLines(builder, "-", a[h.i1:h.i2])
+			writeDiffLines(builder, "+", b[h.j1:h.j2])
+			line = h.i2
+		}
+		writeDiffLines(builder, " ", a[line:i2])
+
+		i = j + 1
+	}
+
+	return builder.String()
+}
+
+func hunkRange(from, to int) string {
+	switch to - from {
+	case 0:
+		return fmt.Sprintf("%d,0", from)
+	case 1:
+		return fmt.Sprintf("%d", from+1)
+	default:
+		return fmt.Sprintf("%d,%d", from+1, to-from)
+	}
+}
+
+func writeDiffLines(builder *strings.Builder, prefix string, lines []string) {
+	for _, line := range lines {
+		builder.WriteString(prefix)
+		builder.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			builder.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// lineHunk участок, в котором строки a[i1:i2] заменены строками b[j1:j2]
+type lineHunk struct {
+	i1, i2, j1, j2 int
+}
+
+// maxDiffDistance наибольшее число удаленных и вставленных строк, для которого ищется минимальная разница.
+// Память на восстановление пути растет как квадрат этого числа, при большем различии (например, у модуля
+// с новыми отступами) весь различающийся участок заменяется одной правкой
+const maxDiffDistance = 1000
+
+// diffLines сравнивает строки алгоритмом Майерса и возвращает различающиеся участки
+func diffLines(a, b []string) []lineHunk {
+	// одинаковые начало и конец в алгоритм не передаем, при небольших правках большого модуля это основная часть текста
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	// trace[d] - значения v[-d..d] перед шагом d, другие диагонали на этом шаге не используются
+	var trace [][]int
+
+	found := false
+search:
+	for d := 0; d <= min(n+m, maxDiffDistance); d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			x := 0
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				found = true
+				break search
+			}
+		}
+	}
+	if !found {
+		return []lineHunk{{i1: prefix, i2: prefix + n, j1: prefix, j2: prefix + m}}
+	}
+
+	// восстанавливаем путь с конца и отмечаем удаленные и вставленные строки
+	deleted, inserted := make([]bool, n), make([]bool, m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		}
+
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+		}
+
+		if x == prevX {
+			inserted[prevY] = true
+		} else {
+			deleted[prevX] = true
+		}
+		x, y = prevX, prevY
+	}
+
+	var hunks []lineHunk
+	for i, j := 0, 0; i < n || j < m; {
+		if i < n && j < m && !deleted[i] && !inserted[j] {
+			i++
+			j++
+			continue
+		}
+
+		h := lineHunk{i1: i, j1: j}
+		for i < n && deleted[i] {
+			i++
+		}
+		for j < m && inserted[j] {
+			j++
+		}
+		h.i2, h.j2 = i, j
+
+		h.i1, h.i2, h.j1, h.j2 = h.i1+prefix, h.i2+prefix, h.j1+prefix, h.j2+prefix
+		hunks = append(hunks, h)
+	}
+
+	return hunks
+}
+
+// splitLines делит текст на строки, перевод строки остается в конце каждой строки
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// lineOffsets возвращает смещения начала каждой строки и смещение конца текста последним элементом
+func lineOffsets(lines []string) []int {
+	result := make([]int, len(lines)+1)
+	for i, line := range lines {
+		result[i+1] = result[i] + len(line)
+	}
+
+	return result
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	// не режем многобайтовый символ пополам
+	for i > 0 && i < len(a) && !utf8.RuneStart(a[i]) {
+		i--
+	}
+
+	return i
+}
+
+func commonSuffix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[len(a)-1-i] == b[len(b)-1-i] {
+		i++
+	}
+
+	// граница должна приходиться на начало символа
+	for i > 0 && !utf8.RuneStart(a[len(a)-i]) {
+		i--
+	}
+
+	return i
+}
+
+// PositionAt возвращает строку и колонку байтового смещения offset в тексте source
+func PositionAt(source string, offset int) Position {
+	return newLineIndex(source).position(offset)
+}
+
+// lineIndex переводит смещения в строки и колонки без повторного прохода по всему тексту
+type lineIndex struct {
+	text   string
+	starts []int
+}
+
+func newLineIndex(text string) *lineIndex {
+	starts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	return &lineIndex{text: text, starts: starts}
+}
+
+func (l *lineIndex) position(offset int) Position {
+	line := sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset }) - 1
+
+	return Position{
+		Line:   line + 1,
+		Column: utf8.RuneCountInString(l.text[l.starts[line]:offset]) + 1,
+		Offset: offset,
+	}
+}
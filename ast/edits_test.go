@@ -0,0 +1,147 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEdits(t *testing.T) {
+	t.Run("changed node", func(t *testing.T) {
+		a := NewAST(cstCode)
+		assert.NoError(t, a.Parse())
+
+		pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+		ret := pf.Body[0].(*IfStatement).TrueBlock[0].(*ReturnStatement)
+		ret.Param.(*ExpStatement).Right = &ExpStatement{Operation: OpPlus, Left: VarStatement{Name: "б"}, Right: 1.0}
+
+		edits, err := Edits(cstCode, &a.ModuleStatement, PrintConf{Margin: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, []TextEdit{{
+			Range:   Range{Start: Position{Line: 7, Column: 15, Offset: 264}, End: Position{Line: 7, Column: 16, Offset: 266}},
+			NewText: "(б + 1)",
+		}}, edits)
+
+		cst, err := a.CST()
+		assert.NoError(t, err)
+
+		result, err := ApplyEdits(cstCode, edits)
+		assert.NoError(t, err)
+		printed, err := cst.Print(PrintConf{Margin: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, printed, result)
+	})
+	t.Run("format", func(t *testing.T) {
+		fileData, err := os.ReadFile("testdata")
+		assert.NoError(t, err)
+
+		a := NewAST(string(fileData))
+		assert.NoError(t, a.Parse())
+
+		formatted := a.Print(PrintConf{Margin: 4})
+		edits := ComputeEdits(string(fileData), formatted)
+		assert.NotEmpty(t, edits)
+
+		result, err := ApplyEdits(string(fileData), edits)
+		assert.NoError(t, err)
+		assert.Equal(t, formatted, result)
+
+		assert.Empty(t, ComputeEdits(formatted, formatted))
+	})
+	t.Run("overlap", func(t *testing.T) {
+		source := "а = 1;\nб = 2;\n"
+		edits := []TextEdit{
+			{Range: Range{Start: Position{Line: 2, Column: 1, Offset: 9}, End: Position{Line: 2, Column: 3, Offset: 11}}, NewText: "в"},
+			{Range: Range{Start: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 2, Column: 2, Offset: 10}}, NewText: ""},
+		}
+
+		_, err := ApplyEdits(source, edits)
+		assert.True(t, errors.Is(err, ErrOverlappingEdits))
+		assert.EqualError(t, err, "line 1, column 1 and line 2, column 1: edits overlap")
+
+		err = CheckEdits([]TextEdit{{Range: Range{Start: Position{Offset: 5}, End: Position{Offset: 100}}}}, len(source))
+		assert.EqualError(t, err, "incorrect edit range 5:100")
+
+		// вставки в одну точку не пересекаются и применяются по порядку
+		result, err := ApplyEdits(source, []TextEdit{
+			{Range: Range{Start: Position{Offset: 0}, End: Position{Offset: 0}}, NewText: "// 1\n"},
+			{Range: Range{Start: Position{Offset: 0}, End: Position{Offset: 0}}, NewText: "// 2\n"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "// 1\n// 2\nа = 1;\nб = 2;\n", result)
+	})
+	t.Run("large input", func(t *testing.T) {
+		before, after := largeModule(4000, "\t"), largeModule(4000, "    ")
+
+		// все строки различаются, минимальную разницу не ищем и заменяем участок одной правкой
+		edits := ComputeEdits(before, after)
+		assert.Len(t, edits, 1)
+		result, err := ApplyEdits(before, edits)
+		assert.NoError(t, err)
+		assert.Equal(t, after, result)
+
+		// небольшие правки в большом модуле остаются точечными
+		lines := strings.SplitAfter(before, "\n")
+		lines[100], lines[2000] = "\tа = 1;\n", "\tб = 2;\n"
+		changed := strings.Join(lines, "")
+		edits = ComputeEdits(before, changed)
+		assert.Len(t, edits, 2)
+		result, err = ApplyEdits(before, edits)
+		assert.NoError(t, err)
+		assert.Equal(t, changed, result)
+	})
+	t.Run("unified diff", func(t *testing.T) {
+		source := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+		edits := ComputeEdits(source, "1\n2\nтри\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13")
+
+		diff, err := UnifiedDiff("Модуль.bsl", source, edits)
+		assert.NoError(t, err)
+		assert.Equal(t, `--- a/Модуль.bsl
++++ b/Модуль.bsl
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++три
+ 4
+ 5
+ 6
+@@ -9,4 +9,5 @@
+ 9
+ 10
+ 11
+-12
+\ No newline at end of file
++12
++13
+\ No newline at end of file
+`, diff)
+
+		diff, err = UnifiedDiff("Модуль.bsl", source, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, diff)
+	})
+}
+
+func BenchmarkComputeEdits(b *testing.B) {
+	before, after := largeModule(4000, "\t"), largeModule(4000, "    ")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ComputeEdits(before, after)
+	}
+}
+
+// largeModule модуль из lines строк с отступом indent
+func largeModule(lines int, indent string) string {
+	builder := strings.Builder{}
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&builder, "%sПеременная%d = %d;\n", indent, i, i)
+	}
+
+	return builder.String()
+}
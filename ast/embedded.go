@@ -0,0 +1,291 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// evalPrefix выражение Вычислить разбирается как правая часть присваивания
+const evalPrefix = "_="
+
+// Warnings ошибки разбора кода в константных строках Выполнить и Вычислить. Разбор модуля они не прерывают,
+// позиции указывают в исходный код модуля
+func (ast *AstNode) Warnings() []*ParseError {
+	return ast.warnings
+}
+
+// embedCode разбирает константную строку, переданную в Выполнить или Вычислить, и добавляет к вызову ее дерево
+func embedCode(yylex yyLexer, m MethodStatement) MethodStatement {
+	ast, ok := yylex.(*AstNode)
+	if !ok || len(m.Param.Statements) != 1 {
+		return m
+	}
+	if _, ok := m.Param.Statements[0].(string); !ok {
+		return m
+	}
+
+	text, positions, ok := scanLiteral(ast.code, m.Pos.Offset+len(m.Name))
+	if !ok {
+		return m
+	}
+
+	eval := isEval(m.Name)
+	prefix := ""
+	if eval {
+		prefix = evalPrefix
+	}
+
+	// ошибки Вычислить откладываются до разбора модуля, в котором может быть свой метод Вычислить
+	warnings := &ast.warnings
+	if eval {
+		warnings = &ast.evalWarnings
+	}
+
+	sub := NewAST(prefix + text)
+	sub.embedded = true
+	mapping := &embeddedMapping{prefix: len(prefix), positions: positions, index: newLineIndex(prefix + text)}
+	if err := sub.Parse(); err != nil {
+		if parseErr, ok := err.(*ParseError); ok {
+			parseErr = mapping.parseError(parseErr)
+			parseErr.Message = fmt.Sprintf("%s argument: %s", m.Name, parseErr.Message)
+			*warnings = append(*warnings, parseErr)
+		} else {
+			*warnings = append(*warnings, &ParseError{Message: fmt.Sprintf("%s argument: %v", m.Name, err), Line: m.Pos.Line, Column: m.Pos.Column})
+		}
+		return m
+	}
+	for _, w := range sub.warnings {
+		*warnings = append(*warnings, mapping.parseError(w))
+	}
+	for _, w := range sub.evalWarnings {
+		ast.evalWarnings = append(ast.evalWarnings, mapping.parseError(w))
+	}
+
+	body := sub.Body
+	if eval {
+		assignment, ok := body[0].(AssignmentStatement)
+		if !ok || len(body) != 1 {
+			return m
+		}
+		body = assignment.Expr.Statements
+	}
+
+	visited := map[uintptr]bool{}
+	for i := range body {
+		v := reflect.ValueOf(&body[i]).Elem()
+		mapping.remap(v, visited)
+	}
+
+	m.Embedded = body
+	return m
+}
+
+// evalCall разбирает строку вызова Вычислить(...) в начале цепочки. Вычислить не ключевое слово: так
+// может называться переменная, а Объект.Вычислить() - метод другого объекта. Если так называется метод
+// модуля, вызовы обращаются к нему, и разобранный код убирает resolveEval
+func evalCall(yylex yyLexer, stm Statement) Statement {
+	if m, ok := stm.(MethodStatement); ok && isEval(m.Name) {
+		return embedCode(yylex, m)
+	}
+
+	return stm
+}
+
+// resolveEval после разбора модуля: если в модуле есть свой метод Вычислить, убирает разобранный код строк
+// у всех вызовов Вычислить вместе с ошибками разбора, иначе добавляет эти ошибки к остальным
+func (ast *AstNode) resolveEval() {
+	evalWarnings := ast.evalWarnings
+	ast.evalWarnings = nil
+
+	for _, item := range ast.ModuleStatement.Body {
+		if pf, ok := item.(*FunctionOrProcedure); ok && isEval(pf.Name) {
+			visitNodes(reflect.ValueOf(&ast.ModuleStatement.Body).Elem(), map[uintptr]bool{}, func(v reflect.Value) bool {
+				if m, ok := v.Interface().(MethodStatement); ok && isEval(m.Name) && m.Embedded != nil {
+					m.Embedded = nil
+					v.Set(reflect.ValueOf(m))
+				}
+				return true
+			})
+			return
+		}
+	}
+
+	if len(evalWarnings) > 0 {
+		ast.warnings = append(ast.warnings, evalWarnings...)
+		sort.SliceStable(ast.warnings, func(i, j int) bool {
+			a, b := ast.warnings[i], ast.warnings[j]
+			return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+		})
+	}
+}
+
+func isEval(name string) bool {
+	switch NormalizeName(name) {
+	case "вычислить", "eval":
+		return true
+	default:
+		return false
+	}
+}
+
+// scanLiteral находит строковый литерал, который начинается после смещения from (пропускаются пробелы и скобки),
+// и возвращает его текст так, как его увидит Выполнить: без кавычек, с одинарными "" и без | в начале строк.
+// positions - позиция в исходном коде каждого байта текста и позиция закрывающей кавычки последним элементом
+func scanLiteral(source string, from int) (text string, positions []Position, ok bool) {
+	i := from
+	for i < len(source) && (isSpace(rune(source[i])) || source[i] == '(') {
+		i++
+	}
+	if i >= len(source) || source[i] != '"' {
+		return "", nil, false
+	}
+
+	index := newLineIndex(source)
+	builder := strings.Builder{}
+	emit := func(s string, offset int) {
+		pos := index.position(offset)
+		for j := 0; j < len(s); j++ {
+			positions = append(positions, pos)
+		}
+		builder.WriteString(s)
+	}
+
+	for i++; i < len(source); {
+		switch c := source[i]; c {
+		case EOL:
+			emit("\n", i)
+			for i++; i < len(source); {
+				if isSpace(rune(source[i])) {
+					i++
+				} else if strings.HasPrefix(source[i:], "//") {
+					for i < len(source) && source[i] != EOL {
+						i++
+					}
+				} else {
+					break
+				}
+			}
+			if i >= len(source) || source[i] != '|' {
+				return "", nil, false
+			}
+			i++
+		case '"':
+			if i+1 < len(source) && source[i+1] == '"' {
+				emit(`"`, i)
+				i += 2
+				continue
+			}
+
+			end := i
+			for i++; i < len(source) && isSpace(rune(source[i])); i++ {
+			}
+			// "Строка1" "Строка2" считается одной строкой
+			if i < len(source) && source[i] == '"' {
+				i++
+				continue
+			}
+
+			positions = append(positions, index.position(end))
+			return builder.String(), positions, true
+		default:
+			_, size := utf8.DecodeRuneInString(source[i:])
+			emit(source[i:i+size], i)
+			i += size
+		}
+	}
+
+	return "", nil, false
+}
+
+// embeddedMapping переводит позиции в разобранной строке в позиции исходного кода модуля
+type embeddedMapping struct {
+	prefix    int // длина evalPrefix, если он добавлялся к тексту
+	positions []Position
+	index     *lineIndex
+}
+
+func (e *embeddedMapping) position(p Position) Position {
+	if p == (Position{}) {
+		return p
+	}
+
+	offset := min(max(p.Offset-e.prefix, 0), len(e.positions)-1)
+	return e.positions[offset]
+}
+
+func (e *embeddedMapping) parseError(err *ParseError) *ParseError {
+	line := min(max(err.Line, 1), len(e.index.starts)) - 1
+	offset := e.index.starts[line]
+	for column := 1; column < err.Column && offset < len(e.index.text); column++ {
+		_, size := utf8.DecodeRuneInString(e.index.text[offset:])
+		offset += size
+	}
+
+	pos := e.position(Position{Line: err.Line, Column: err.Column, Offset: offset})
+	return &ParseError{Message: err.Message, Line: pos.Line, Column: pos.Column, Literal: err.Literal}
+}
+
+// remap заменяет позиции во всех узлах v
+func (e *embeddedMapping) remap(v reflect.Value, visited map[uintptr]bool) {
+	visitNodes(v, visited, func(v reflect.Value) bool {
+		if v.Type() == positionType {
+			v.Set(reflect.ValueOf(e.position(v.Interface().(Position))))
+			return false
+		}
+		return true
+	})
+}
+
+// visitNodes вызывает f для каждой структуры внутри v, которую можно изменить. Узлы-значения внутри интерфейсов
+// не адресуемы, поэтому f получает копию, которая затем заменяет узел. Если f вернет false, поля структуры не обходятся
+func visitNodes(v reflect.Value, visited map[uintptr]bool, f func(reflect.Value) bool) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		elem := v.Elem()
+		if elem.Kind() == reflect.Ptr {
+			visitNodes(elem, visited, f)
+			return
+		}
+		if elem.Kind() != reflect.Struct && elem.Kind() != reflect.Slice {
+			return
+		}
+
+		cp := reflect.New(elem.Type()).Elem()
+		cp.Set(elem)
+		visitNodes(cp, visited, f)
+		v.Set(cp)
+	case reflect.Ptr:
+		// на одну метку ссылаются и оператор Перейти, и сама метка
+		if v.IsNil() || visited[v.Pointer()] {
+			return
+		}
+		visited[v.Pointer()] = true
+		visitNodes(v.Elem(), visited, f)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			visitNodes(v.Index(i), visited, f)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			cp := reflect.New(v.Type().Elem()).Elem()
+			cp.Set(v.MapIndex(key))
+			visitNodes(cp, visited, f)
+			v.SetMapIndex(key, cp)
+		}
+	case reflect.Struct:
+		if !f(v) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				visitNodes(field, visited, f)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbedded(t *testing.T) {
+	code := `Процедура А(Параметр)
+	Выполнить("Результат = Модуль.Метод(Параметр)");
+	Выполнить "Сообщить(""а"");
+	|Б = 1";
+	Б = Вычислить("Параметр + 1");
+	Выполнить("Если Тогда");
+	Выполнить(Параметр);
+КонецПроцедуры`
+
+	a := NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	body := a.ModuleStatement.Body[0].(*FunctionOrProcedure).Body
+
+	t.Run("execute", func(t *testing.T) {
+		embedded := body[0].(MethodStatement).Embedded
+		if assert.Len(t, embedded, 1) {
+			assert.Equal(t, Range{Start: Position{Line: 2, Column: 13, Offset: 61}, End: Position{Line: 2, Column: 47, Offset: 123}}, RangeOf(embedded[0]))
+			assert.Equal(t, `(assignment [2:13-2:47] target: (var [2:13-2:22] "Результат") value: (member [2:25-2:47] object: (var [2:25-2:31] "Модуль") `+
+				`member: (call [2:32-2:47] "Метод" (arguments (var [2:38-2:46] "Параметр")))))`, SExpr(embedded[0]))
+		}
+	})
+	t.Run("multiline", func(t *testing.T) {
+		assert.Equal(t, `(block (call [3:13-3:28] "Сообщить" (arguments (string "а"))) `+
+			`(assignment [4:3-4:8] target: (var [4:3-4:4] "Б") value: (number 1)))`, SExpr(body[1].(MethodStatement).Embedded))
+
+		// "" в строке занимает два символа исходного кода, | в начале строки в код не входит
+		embedded := body[1].(MethodStatement).Embedded
+		assert.Equal(t, Position{Line: 3, Column: 28, Offset: 172}, RangeOf(embedded[0]).End)
+		assert.Equal(t, Position{Line: 4, Column: 3, Offset: 176}, PositionOf(embedded[1]))
+	})
+	t.Run("eval", func(t *testing.T) {
+		embedded := body[2].(AssignmentStatement).Expr.Statements[0].(MethodStatement).Embedded
+		if assert.Len(t, embedded, 1) {
+			assert.Equal(t, OpPlus, embedded[0].(*ExpStatement).Operation)
+			assert.Equal(t, Position{Line: 5, Column: 17, Offset: 211}, PositionOf(embedded[0]))
+		}
+	})
+	t.Run("warnings", func(t *testing.T) {
+		assert.Nil(t, body[3].(MethodStatement).Embedded)
+		assert.Nil(t, body[4].(MethodStatement).Embedded)
+		if assert.Len(t, a.Warnings(), 1) {
+			assert.Equal(t, &ParseError{Message: "Выполнить argument: syntax error", Line: 6, Column: 17, Literal: "Тогда"}, a.Warnings()[0])
+		}
+	})
+	t.Run("resolve", func(t *testing.T) {
+		res := Resolve(&a.ModuleStatement)
+		param := res.ScopeOf(a.ModuleStatement.Body[0].(*FunctionOrProcedure)).Lookup("Параметр")
+		if assert.NotNil(t, param) {
+			// использования в строках Выполнить и Вычислить и в обычном коде
+			assert.Len(t, param.References, 3)
+		}
+	})
+	t.Run("serialization", func(t *testing.T) {
+		data, err := a.ModuleStatement.MarshalBinary()
+		assert.NoError(t, err)
+
+		module := ModuleStatement{}
+		assert.NoError(t, module.UnmarshalBinary(data))
+		assert.Equal(t, a.ModuleStatement, module)
+
+		jsonData, err := a.JSON()
+		assert.NoError(t, err)
+
+		fromJSON, err := FromJSON(jsonData)
+		assert.NoError(t, err)
+		assert.Equal(t, a.ModuleStatement.Body, fromJSON.Body)
+	})
+}
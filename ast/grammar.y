@@ -62,6 +62,7 @@ package ast
     opt_goToLabel *GoToLabelStatement
     directive *DirectiveStatement
     directives []*DirectiveStatement
+    pos Position // позиция первого токена правила, для пустых правил не заполняется
 }
 
 %token<token> Directive ExtDirective token_identifier Procedure Var EndProcedure If Then ElseIf Else EndIf For Each In To Loop EndLoop Break Not ValueParam While GoToLabel
@@ -114,8 +115,8 @@ main: global_variables {
 
 /* Директивы */
 directive:  { $$ = nil}
-        | Directive { $$ = &DirectiveStatement{ Name: $1.literal }}
-        | ExtDirective '(' String ')' { $$ = &DirectiveStatement{ Name: $1.literal, Src: $3.literal }}
+        | Directive { $$ = &DirectiveStatement{ Name: $1.literal, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }}
+        | ExtDirective '(' String ')' { $$ = &DirectiveStatement{ Name: $1.literal, Src: $3.literal, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }}
 ;
 
 opt_many_directives: directive {  if $1 != nil { $$ = []*DirectiveStatement{$1} } else { $$ = nil } }
@@ -133,19 +134,19 @@ global_variables: directive Var identifiers opt_export semicolon {
             }
 
             $$[i].Export = $4 != nil 
-            $$[i].Var = VarStatement { Name: v.literal }
+            $$[i].Var = VarStatement { Name: v.literal, Pos: v.position, End: v.end() }
         }
 };
 
 
 funcProc: opt_many_directives Function token_identifier '(' declarations_method_params ')' opt_export { isFunction(true, yylex) } opt_explicit_variables opt_body EndFunction
         {  
-            $$ = createFunctionOrProcedure(PFTypeFunction, $1, $3.literal, $5, $7, $9, $10)
+            $$ = createFunctionOrProcedure(PFTypeFunction, $2.position, nodeEnd(yylex, yyrcvr.char), $1, $3.literal, $5, $7, $9, $10)
             isFunction(false, yylex) 
         }
         | opt_many_directives Procedure token_identifier '(' declarations_method_params ')' opt_export opt_explicit_variables opt_body EndProcedure
         { 
-            $$ = createFunctionOrProcedure(PFTypeProcedure, $1, $3.literal, $5, $7, $8, $9)
+            $$ = createFunctionOrProcedure(PFTypeProcedure, $2.position, nodeEnd(yylex, yyrcvr.char), $1, $3.literal, $5, $7, $8, $9)
         }
 ;
 
@@ -200,6 +201,7 @@ explicit_variables: Var identifiers semicolon {
 /* Если Конецесли */
 stmt_if : If expr Then opt_body opt_elseif_list opt_else EndIf {
     $$ = &IfStatement {
+        Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char),
         Expression: $2,
         TrueBlock:  $4,
         IfElseBlock: $5,
@@ -209,10 +211,12 @@ stmt_if : If expr Then opt_body opt_elseif_list opt_else EndIf {
 
 /* ИначеЕсли */
 opt_elseif_list : { $$ = Statements{} }
-        | ElseIf expr Then opt_body opt_elseif_list {
-             $$ = append($5, &IfStatement{
-                Expression: $2,
-                TrueBlock:  $4,
+        | opt_elseif_list ElseIf expr Then opt_body {
+             // список леворекурсивный, так ветка разбирается целиком до следующего ИначеЕсли и ее границы в исходном коде известны
+             $$ = append($1, &IfStatement{
+                Pos: $2.position, End: nodeEnd(yylex, yyrcvr.char),
+                Expression: $3,
+                TrueBlock:  $5,
             })
         };
 
@@ -223,6 +227,7 @@ opt_else : { $$ = nil }
 /* тернарный оператор */
 ternary: '?' '(' expr comma expr comma expr ')' {
     $$ = TernaryStatement{
+            Pos: $<token>1.position, End: nodeEnd(yylex, yyrcvr.char),
             Expression: $3,
             TrueBlock: $5,
             ElseBlock: $7,
@@ -232,6 +237,7 @@ ternary: '?' '(' expr comma expr comma expr ')' {
 /* циклы */
 stmt_loop: For Each token_identifier In loopExp Loop { setLoopFlag(true, yylex) } opt_body EndLoop {
         $$ = &LoopStatement{
+            Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char),
             For: $3.literal,
             In: $5,
             Body: $8,
@@ -240,6 +246,7 @@ stmt_loop: For Each token_identifier In loopExp Loop { setLoopFlag(true, yylex)
     } 
     | For expr To expr Loop { setLoopFlag(true, yylex) } opt_body EndLoop {
         $$ = &LoopStatement{
+            Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char),
             For: $2,
             To: $4,
             Body: $7,
@@ -248,6 +255,7 @@ stmt_loop: For Each token_identifier In loopExp Loop { setLoopFlag(true, yylex)
     }
     | While expr Loop { setLoopFlag(true, yylex) } opt_body EndLoop {
         $$ = &LoopStatement{
+            Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char),
             WhileExpr: $2,
             Body: $5,
         }
@@ -265,64 +273,65 @@ loopExp: through_dot { $$ = $1 }
 stmt : through_dot EQUAL expr {
             v := $1
        	    if tok, ok := $1.(Token); ok {
-       		    v = VarStatement{ Name: tok.literal }
+       		    v = VarStatement{ Name: tok.literal, Pos: tok.position, End: tok.end() }
        	    }
-       	    $$ = AssignmentStatement{ Var: v, Expr: ExprStatements{ Statements: Statements{$3}} }
+       	    $$ = AssignmentStatement{ Var: v, Expr: ExprStatements{ Statements: Statements{$3}}, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char) }
        	}
     | expr %prec LOW_PREC { $$ = $1 }
     | stmt_if { $$ = $1 }
     | stmt_loop {$$ = $1 }
     | stmt_tryCatch { $$ = $1 }
-    | Continue { $$ = ContinueStatement{}; checkLoopOperator($1, yylex) }
-    | Break { $$ = BreakStatement{}; checkLoopOperator($1, yylex) }
-    | Throw opt_expr { $$ = ThrowStatement{ Param: $2 }; checkThrowParam($1, $2, yylex) }
-    | Return opt_expr { $$ = &ReturnStatement{ Param: $2 }; checkReturnParam($2, yylex) }
+    | Continue { $$ = ContinueStatement{ Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }; checkLoopOperator($1, yylex) }
+    | Break { $$ = BreakStatement{ Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }; checkLoopOperator($1, yylex) }
+    | Throw opt_expr { $$ = ThrowStatement{ Param: $2, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }; checkThrowParam($1, $2, yylex) }
+    | Return opt_expr { $$ = &ReturnStatement{ Param: $2, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }; checkReturnParam($2, yylex) }
 ;
 
 
 /* вызовы через точку */
-through_dot: identifier { $$ = $1 }
-        | through_dot dot identifier { $$ = CallChainStatement{ Unit: $3, Call:  $1 } }
+through_dot: identifier { $$ = evalCall(yylex, $1) }
+        | through_dot dot identifier { $$ = CallChainStatement{ Unit: $3, Call:  $1, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char) } }
 ;
 
 /* вызовы процедур, функций */
 /* вызовы выполнить */
 /* выполнить может вызываться так выполнить("что-то") или так выполнить "что-то" */
-identifier: token_identifier { $$ = VarStatement{ Name: $1.literal } }
-        | token_identifier '(' exprs ')' { $$ = MethodStatement{ Name: $1.literal, Param: $3 } }
-        | identifier '[' expr ']' { $$ = ItemStatement{ Object: $1, Item: $3 } }
-        | Execute execute_param { $$ = MethodStatement{ Name: $1.literal, Param:   ExprStatements{ Statements: Statements{$2}} } }
-        | Execute '(' expr ')' { $$ = MethodStatement{ Name: $1.literal, Param:   ExprStatements{ Statements: Statements{$3}} } }
+/* вычислить - обычный идентификатор, вызов Вычислить(...) в начале цепочки разбирает evalCall */
+identifier: token_identifier { $$ = VarStatement{ Name: $1.literal, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) } }
+        | token_identifier '(' exprs ')' { $$ = MethodStatement{ Name: $1.literal, Param: $3, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) } }
+        | identifier '[' expr ']' { $$ = ItemStatement{ Object: $1, Item: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char) } }
+        | Execute execute_param { $$ = embedCode(yylex, MethodStatement{ Name: $1.literal, Param:   ExprStatements{ Statements: Statements{$2}}, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }) }
+        | Execute '(' expr ')' { $$ = embedCode(yylex, MethodStatement{ Name: $1.literal, Param:   ExprStatements{ Statements: Statements{$3}}, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }) }
 ;
 
 execute_param: String { $$ = $1.value  }
-             | token_identifier { $$ = VarStatement{ Name: $1.literal }};
+             | token_identifier { $$ = VarStatement{ Name: $1.literal, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }};
 
 /* попытка */
 stmt_tryCatch: Try opt_body Catch { setTryFlag(true, yylex) } opt_body EndTry { 
-    $$ = TryStatement{ Body: $2, Catch: $5 }
+    $$ = TryStatement{ Body: $2, Catch: $5, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) }
     setTryFlag(false, yylex)
 };
 
 /* все что может учавствовать в выражениях */
 expr : simple_expr { $$ = $1 }
     | '(' exprs ')' { $$ = $2 }
-    | expr '+' expr { $$ = &ExpStatement{Operation: OpPlus, Left: $1, Right: $3} }
-    | expr '-' expr { $$ = &ExpStatement{Operation: OpMinus, Left: $1, Right: $3} }
-    | expr '*' expr { $$ = &ExpStatement{Operation: OpMul, Left: $1, Right: $3} }
-    | expr '/' expr { $$ = &ExpStatement{Operation: OpDiv, Left: $1, Right: $3} }
-    | expr '%' expr { $$ = &ExpStatement{Operation: OpMod, Left: $1, Right: $3} }
-    | expr '>' expr { $$ = &ExpStatement{Operation: OpGt, Left: $1, Right: $3} }
-    | expr '<' expr { $$ = &ExpStatement{Operation: OpLt, Left: $1, Right: $3} }
-    | expr EQUAL expr {$$ = &ExpStatement{Operation: OpEq, Left: $1, Right: $3 }}
-    | expr OR expr {  $$ = &ExpStatement{Operation: OpOr, Left: $1, Right: $3 } }
-    | expr And expr { $$ = &ExpStatement{Operation: OpAnd, Left: $1, Right: $3 } }
-    | expr NeEQ expr { $$ = &ExpStatement{Operation: OpNe, Left: $1, Right: $3 } }
-    | expr LE expr { $$ = &ExpStatement{Operation: OpLe, Left: $1, Right: $3 } }
-    | expr GE expr { $$ = &ExpStatement{Operation: OpGe, Left: $1, Right: $3 } }
+    | expr '+' expr { $$ = &ExpStatement{Operation: OpPlus, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr '-' expr { $$ = &ExpStatement{Operation: OpMinus, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr '*' expr { $$ = &ExpStatement{Operation: OpMul, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr '/' expr { $$ = &ExpStatement{Operation: OpDiv, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr '%' expr { $$ = &ExpStatement{Operation: OpMod, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr '>' expr { $$ = &ExpStatement{Operation: OpGt, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr '<' expr { $$ = &ExpStatement{Operation: OpLt, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr EQUAL expr { $$ = &ExpStatement{Operation: OpEq, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr OR expr { $$ = &ExpStatement{Operation: OpOr, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr And expr { $$ = &ExpStatement{Operation: OpAnd, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr NeEQ expr { $$ = &ExpStatement{Operation: OpNe, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr LE expr { $$ = &ExpStatement{Operation: OpLe, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
+    | expr GE expr { $$ = &ExpStatement{Operation: OpGe, Left: $1, Right: $3, Pos: $<pos>1, End: nodeEnd(yylex, yyrcvr.char)} }
     | Not expr { $$ = not($2) }
     | new_object { $$ = $1 }
-    | GoTo goToLabel { $$ = GoToStatement{ Label: $2 } }
+    | GoTo goToLabel { $$ = GoToStatement{ Label: $2, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) } }
     | ternary { $$ =  $1  } /* тернарный оператор */
     | through_dot {
 	    if tok, ok := $1.(Token); ok {
@@ -366,14 +375,14 @@ declarations_method_params : { $$ = []ParamStatement{} }
 // новый Структура(), новый Массив() ...
 // но так же и такие
 // Новый("РегистрСведенийКлючЗаписи.СостоянияОригиналовПервичныхДокументов", ПараметрыМассив);
-new_object:  New token_identifier { $$ = NewObjectStatement{ Constructor: $2.literal } }
-            | New token_identifier '(' exprs ')' { $$ = NewObjectStatement{ Constructor: $2.literal, Param: $4 } }
-            | New '(' exprs ')' { $$ = NewObjectStatement{ Param: $3 } }
+new_object:  New token_identifier { $$ = NewObjectStatement{ Constructor: $2.literal, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) } }
+            | New token_identifier '(' exprs ')' { $$ = NewObjectStatement{ Constructor: $2.literal, Param: $4, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) } }
+            | New '(' exprs ')' { $$ = NewObjectStatement{ Param: $3, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) } }
 ;
 
 
 
-goToLabel: GoToLabel { $$ = &GoToLabelStatement{ Name: $1.literal } }
+goToLabel: GoToLabel { $$ = &GoToLabelStatement{ Name: $1.literal, Pos: $1.position, End: nodeEnd(yylex, yyrcvr.char) } }
 
 identifiers: token_identifier %prec LOW_PREC  { $$ = []Token{$1} }
         | identifiers comma token_identifier %prec LOW_PREC {$$ = append($$, $3) }
@@ -0,0 +1,63 @@
+// Package fuzz содержит фаззинг-тесты пакета ast. Они вынесены отдельно, потому что в пакете ast
+// testdata - файл с примером модуля, а go test ищет корпус фаззинга в каталоге testdata/fuzz
+package fuzz
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	for _, code := range []string{
+		`// комментарий
+		&НаСервере
+		Функция Сумма(а, Знач б = 10) Экспорт
+			Если а > 0 И Не б Тогда
+				Возврат -а * (б + 1);
+			КонецЕсли;
+			Возврат '20240102';
+		КонецФункции`,
+		`Перем а Экспорт;
+		Процедура П()
+			Перейти ~м;
+			~м:
+			Пока а Цикл
+				Прервать;
+			КонецЦикла;
+		КонецПроцедуры`,
+	} {
+		a := ast.NewAST(code)
+		if err := a.Parse(); err != nil {
+			f.Fatal(err)
+		}
+
+		data, err := a.ModuleStatement.MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		module := ast.ModuleStatement{}
+		if err := module.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		// прочитанный модуль должен кодироваться и читаться без потерь
+		encoded, err := module.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		again := ast.ModuleStatement{}
+		if err := again.UnmarshalBinary(encoded); err != nil {
+			t.Fatal(err)
+		}
+		if reencoded, _ := again.MarshalBinary(); !bytes.Equal(encoded, reencoded) {
+			t.Fatal("unstable encoding")
+		}
+	})
+}
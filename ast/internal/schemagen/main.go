@@ -0,0 +1,33 @@
+// schemagen записывает JSON Schema и описания TypeScript формата ast.ToJSON в указанный каталог.
+// Запускается через go generate ./ast
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: schemagen <dir>")
+	}
+	dir := os.Args[1]
+
+	schema, err := ast.JSONSchema()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ast.schema.json"), append(schema, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ast.d.ts"), []byte(ast.TypeScriptDefinitions()), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,388 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// JSONVersion версия формата JSON. Меняется при любом несовместимом изменении структуры узлов.
+// Версия 2: поле Embedded у MethodStatement. JSON предыдущих версий читается, новых полей в нем просто нет
+const JSONVersion = 2
+
+// узлы в том виде, в котором их создает парсер. При чтении JSON и двоичного формата узел, который в
+// этом списке указатель, тоже восстанавливается указателем. Индекс в списке - код узла в двоичном
+// формате, новые узлы добавляются только в конец
+var serializedNodes = []interface{}{
+	ModuleStatement{},
+	GlobalVariables{},
+	Comment{},
+	&FunctionOrProcedure{},
+	&DirectiveStatement{},
+	ParamStatement{},
+	AssignmentStatement{},
+	ExprStatements{},
+	VarStatement{},
+	&ExpStatement{},
+	&IfStatement{},
+	TryStatement{},
+	ThrowStatement{},
+	UndefinedStatement{},
+	&ReturnStatement{},
+	NewObjectStatement{},
+	CallChainStatement{},
+	MethodStatement{},
+	BreakStatement{},
+	ContinueStatement{},
+	&LoopStatement{},
+	TernaryStatement{},
+	ItemStatement{},
+	GoToStatement{},
+	&GoToLabelStatement{},
+}
+
+type jsonNode struct {
+	typ     reflect.Type
+	pointer bool
+}
+
+var (
+	jsonNodeTypes = map[string]jsonNode{}
+	positionType  = reflect.TypeOf(Position{})
+	timeType      = reflect.TypeOf(time.Time{})
+)
+
+// jsonDateType тип, под которым в JSON записываются литералы дат
+const jsonDateType = "Date"
+
+func init() {
+	for _, n := range serializedNodes {
+		t := reflect.TypeOf(n)
+		node := jsonNode{typ: t, pointer: t.Kind() == reflect.Ptr}
+		if node.pointer {
+			node.typ = t.Elem()
+		}
+
+		jsonNodeTypes[node.typ.Name()] = node
+	}
+}
+
+// флаги унарных операций доступны через этот интерфейс, напрямую неэкспортируемые поля через reflect не установить
+type statementFlags interface {
+	flags() *addStatementField
+}
+
+func (a *addStatementField) flags() *addStatementField {
+	return a
+}
+
+// ToJSON сериализует модуль в JSON версии JSONVersion. В отличие от стандартного json.Marshal у каждого узла
+// есть поле "type" с именем типа, сохраняются позиции узлов и флаги унарных операций (UnaryMinus, UnaryPlus, Not).
+// Литералы записываются как есть (строка, число, булево, null для Неопределено в выражениях),
+// даты - объектом {"type": "Date", "Value": "2006-01-02T15:04:05Z"}
+func ToJSON(module *ModuleStatement) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `{"version":%d,`, JSONVersion)
+	if err := encodeJSONFields(buf, reflect.ValueOf(module).Elem()); err != nil {
+		return nil, errors.Wrap(err, "json encode error")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FromJSON восстанавливает модуль из JSON, полученного ToJSON или AstNode.JSON
+func FromJSON(data []byte) (*ModuleStatement, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, errors.Wrap(err, "json decode error")
+	}
+	if header.Version < 1 || header.Version > JSONVersion {
+		return nil, fmt.Errorf("unsupported JSON version %d", header.Version)
+	}
+
+	module := &ModuleStatement{}
+	if err := decodeJSONValue(reflect.ValueOf(module).Elem(), data); err != nil {
+		return nil, errors.Wrap(err, "json decode error")
+	}
+
+	return module, nil
+}
+
+func encodeJSONValue(buf *bytes.Buffer, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return encodeJSONValue(buf, v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSONValue(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, k)
+			buf.WriteByte(':')
+			if err := encodeJSONValue(buf, v.MapIndex(reflect.ValueOf(k))); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case reflect.Struct:
+		switch v.Type() {
+		case positionType:
+			return writeJSON(buf, v.Interface())
+		case timeType:
+			buf.WriteString(`{"type":"` + jsonDateType + `","Value":`)
+			writeJSON(buf, v.Interface().(time.Time).Format(time.RFC3339))
+			buf.WriteByte('}')
+			return nil
+		}
+
+		buf.WriteByte('{')
+		return encodeJSONFields(buf, v)
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Float64:
+		return writeJSON(buf, v.Interface())
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// encodeJSONFields пишет поля узла вместе с закрывающей скобкой, открывающую пишет вызывающий
+func encodeJSONFields(buf *bytes.Buffer, v reflect.Value) error {
+	t := v.Type()
+	if _, ok := jsonNodeTypes[t.Name()]; !ok {
+		return fmt.Errorf("unsupported type %s", t)
+	}
+
+	buf.WriteString(`"type":`)
+	writeJSON(buf, t.Name())
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			if field.Type == reflect.TypeOf(addStatementField{}) {
+				writeJSONFlags(buf, v)
+			}
+			continue
+		}
+
+		value := v.Field(i)
+		if strings.Contains(field.Tag.Get("json"), "omitempty") && value.IsZero() {
+			continue
+		}
+
+		buf.WriteString(`,"` + field.Name + `":`)
+		if err := encodeJSONValue(buf, value); err != nil {
+			return errors.Wrap(err, t.Name()+"."+field.Name)
+		}
+	}
+
+	buf.WriteByte('}')
+	return nil
+}
+
+func writeJSONFlags(buf *bytes.Buffer, v reflect.Value) {
+	// значение может быть неадресуемым, флаги читаем из копии
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	flags := c.Interface().(statementFlags).flags()
+
+	if flags.unaryMinus {
+		buf.WriteString(`,"UnaryMinus":true`)
+	}
+	if flags.unaryPlus {
+		buf.WriteString(`,"UnaryPlus":true`)
+	}
+	if flags.not {
+		buf.WriteString(`,"Not":true`)
+	}
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) error {
+	data, err := json.Marshal(v)
+	buf.Write(data)
+	return err
+}
+
+func decodeJSONValue(v reflect.Value, data json.RawMessage) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		stm, err := decodeJSONStatement(data)
+		if err != nil {
+			return err
+		}
+		if stm != nil {
+			v.Set(reflect.ValueOf(stm))
+		}
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := decodeJSONValue(p.Elem(), data); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeJSONValue(s.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+
+		m := reflect.MakeMapWithSize(v.Type(), len(items))
+		for k, item := range items {
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeJSONValue(value, item); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k), value)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		if v.Type() == positionType {
+			return json.Unmarshal(data, v.Addr().Interface())
+		}
+		return decodeJSONFields(v, data)
+	default:
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	return nil
+}
+
+func decodeJSONFields(v reflect.Value, data json.RawMessage) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	t := v.Type()
+	if typ := jsonType(fields); typ != t.Name() {
+		return fmt.Errorf("expected node %s, got %q", t.Name(), typ)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			if field.Type == reflect.TypeOf(addStatementField{}) {
+				flags := v.Addr().Interface().(statementFlags).flags()
+				flags.unaryMinus = jsonFlag(fields["UnaryMinus"])
+				flags.unaryPlus = jsonFlag(fields["UnaryPlus"])
+				flags.not = jsonFlag(fields["Not"])
+			}
+			continue
+		}
+
+		if raw, ok := fields[field.Name]; ok {
+			if err := decodeJSONValue(v.Field(i), raw); err != nil {
+				return errors.Wrap(err, t.Name()+"."+field.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeJSONStatement восстанавливает значение поля типа Statement: литерал или узел
+func decodeJSONStatement(data json.RawMessage) (Statement, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		var literal interface{}
+		err := json.Unmarshal(data, &literal)
+		return literal, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	typ := jsonType(fields)
+	if typ == jsonDateType {
+		var value string
+		if err := json.Unmarshal(fields["Value"], &value); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339, value)
+	}
+
+	node, ok := jsonNodeTypes[typ]
+	if !ok {
+		return nil, fmt.Errorf("unknown node type %q", typ)
+	}
+
+	p := reflect.New(node.typ)
+	if err := decodeJSONFields(p.Elem(), data); err != nil {
+		return nil, err
+	}
+	if node.pointer {
+		return p.Interface(), nil
+	}
+
+	return p.Elem().Interface(), nil
+}
+
+func jsonType(fields map[string]json.RawMessage) string {
+	var typ string
+	json.Unmarshal(fields["type"], &typ)
+	return typ
+}
+
+func jsonFlag(data json.RawMessage) bool {
+	var set bool
+	json.Unmarshal(data, &set)
+	return set
+}
@@ -0,0 +1,79 @@
+package ast
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		fileData, err := os.ReadFile("testdata")
+		assert.NoError(t, err)
+
+		a := NewAST(string(fileData))
+		assert.NoError(t, a.Parse())
+
+		data, err := a.JSON()
+		assert.NoError(t, err)
+
+		module, err := FromJSON(data)
+		assert.NoError(t, err)
+		assert.Equal(t, &a.ModuleStatement, module)
+
+		again, err := ToJSON(module)
+		assert.NoError(t, err)
+		assert.Equal(t, string(data), string(again))
+	})
+	t.Run("flags and literals", func(t *testing.T) {
+		code := `Процедура Проба(Знач а = Неопределено)
+					б = -а + (+1);
+					в = Не Истина И Не (а = '20240102');
+				КонецПроцедуры`
+
+		a := NewAST(code)
+		assert.NoError(t, a.Parse())
+
+		data, err := a.JSON()
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"type":"VarStatement","Name":"а","UnaryMinus":true`)
+		assert.Contains(t, string(data), `{"type":"Date","Value":"2024-01-02T00:00:00Z"}`)
+		assert.Contains(t, string(data), `"Default":{"type":"UndefinedStatement"}`)
+
+		module, err := FromJSON(data)
+		assert.NoError(t, err)
+		assert.Equal(t, &a.ModuleStatement, module)
+		assert.Equal(t, a.Print(PrintConf{}), (&AstNode{ModuleStatement: *module}).Print(PrintConf{}))
+
+		// Не (а = '20240102') - выражение в скобках с флагом not
+		not := module.Body[0].(*FunctionOrProcedure).Body[1].(AssignmentStatement).Expr.Statements[0].(*ExpStatement).Right
+		if assert.IsType(t, ExprStatements{}, not) {
+			assert.True(t, not.(ExprStatements).not)
+			assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), not.(ExprStatements).Statements[0].(*ExpStatement).Right)
+		}
+	})
+	t.Run("previous version", func(t *testing.T) {
+		// в версии 1 еще не было Embedded
+		module, err := FromJSON([]byte(`{"version":1,"type":"ModuleStatement","Body":[{"type":"MethodStatement","Name":"Выполнить",` +
+			`"Param":{"type":"ExprStatements","Statements":["а = 1"]}}]}`))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Выполнить", module.Body[0].(MethodStatement).Name)
+			assert.Nil(t, module.Body[0].(MethodStatement).Embedded)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := FromJSON([]byte(`{"type":"ModuleStatement"}`))
+		assert.EqualError(t, err, "unsupported JSON version 0")
+
+		_, err = FromJSON([]byte(`{"version":3,"type":"ModuleStatement"}`))
+		assert.EqualError(t, err, "unsupported JSON version 3")
+
+		_, err = FromJSON([]byte(`{"version":1,"type":"ModuleStatement","Body":[{"type":"SelectStatement"}]}`))
+		assert.EqualError(t, err, `json decode error: ModuleStatement.Body: unknown node type "SelectStatement"`)
+
+		_, err = FromJSON([]byte(`{"version":1,"type":"ModuleStatement","Body":[{"type":"VarStatement","Name":1}]}`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,251 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//go:generate go run ./internal/schemagen schema
+
+// schemaID адрес схемы, в него входит версия формата, так что схемы разных версий не путаются
+var schemaID = fmt.Sprintf("https://github.com/LazarenkoA/1c-language-parser/ast/schema/v%d/ast.schema.json", JSONVersion)
+
+// допустимые значения перечислений
+var schemaEnums = map[reflect.Type][]int{
+	reflect.TypeOf(OpUndefined):     enumRange(int(OpUndefined), int(OpAnd)),
+	reflect.TypeOf(PFTypeUndefined): enumRange(int(PFTypeUndefined), int(PFTypeFunction)),
+}
+
+// узлы, которые не встречаются на месте Statement
+var schemaNotStatements = map[string]bool{
+	"ModuleStatement": true,
+	"GlobalVariables": true,
+	"ParamStatement":  true,
+	"Comment":         true,
+}
+
+type schemaField struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+}
+
+// schemaFields поля узла в том порядке и виде, в котором их пишет ToJSON
+func schemaFields(t reflect.Type) []schemaField {
+	var result []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			if field.Type == reflect.TypeOf(addStatementField{}) {
+				for _, flag := range []string{"UnaryMinus", "UnaryPlus", "Not"} {
+					result = append(result, schemaField{name: flag, typ: reflect.TypeOf(true), optional: true})
+				}
+			}
+			continue
+		}
+
+		result = append(result, schemaField{
+			name:     field.Name,
+			typ:      field.Type,
+			optional: strings.Contains(field.Tag.Get("json"), "omitempty"),
+		})
+	}
+
+	return result
+}
+
+// JSONSchema возвращает JSON Schema (draft 2020-12) формата ToJSON. Схема строится по структурам узлов,
+// ее копия лежит в ast/schema/ast.schema.json
+func JSONSchema() ([]byte, error) {
+	defs := map[string]interface{}{
+		"Position": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"Line":   map[string]interface{}{"type": "integer"},
+				"Column": map[string]interface{}{"type": "integer"},
+				"Offset": map[string]interface{}{"type": "integer"},
+			},
+			"required":             []string{"Line", "Column", "Offset"},
+			"additionalProperties": false,
+		},
+		"DateLiteral": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"type":  map[string]interface{}{"const": jsonDateType},
+				"Value": map[string]interface{}{"type": "string", "format": "date-time"},
+			},
+			"required":             []string{"type", "Value"},
+			"additionalProperties": false,
+		},
+	}
+
+	statement := []interface{}{
+		map[string]interface{}{"type": []string{"string", "number", "boolean", "null"}},
+		schemaRef("DateLiteral"),
+	}
+
+	for _, n := range serializedNodes {
+		t := reflect.Indirect(reflect.ValueOf(n)).Type()
+		if !schemaNotStatements[t.Name()] {
+			statement = append(statement, schemaRef(t.Name()))
+		}
+
+		properties := map[string]interface{}{"type": map[string]interface{}{"const": t.Name()}}
+		required := []string{"type"}
+		if t.Name() == "ModuleStatement" {
+			properties["version"] = map[string]interface{}{"const": JSONVersion}
+			required = append(required, "version")
+		}
+
+		for _, f := range schemaFields(t) {
+			properties[f.name] = schemaType(f.typ)
+			if !f.optional {
+				required = append(required, f.name)
+			}
+		}
+
+		defs[t.Name()] = map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	}
+	defs["Statement"] = map[string]interface{}{"anyOf": statement}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     schemaID,
+		"title":   "1C language AST",
+		"$ref":    "#/$defs/ModuleStatement",
+		"$defs":   defs,
+	}, "", "  ")
+}
+
+func schemaType(t reflect.Type) interface{} {
+	if values, ok := schemaEnums[t]; ok {
+		return map[string]interface{}{"enum": values}
+	}
+
+	nullable := func(schema map[string]interface{}) interface{} {
+		return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return schemaRef("Statement")
+	case reflect.Ptr:
+		return nullable(schemaRef(t.Elem().Name()))
+	case reflect.Slice:
+		return nullable(map[string]interface{}{"type": "array", "items": schemaType(sliceElem(t))})
+	case reflect.Map:
+		return nullable(map[string]interface{}{"type": "object", "additionalProperties": schemaType(t.Elem())})
+	case reflect.Struct:
+		return schemaRef(t.Name())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int:
+		return map[string]interface{}{"type": "integer"}
+	default:
+		return map[string]interface{}{"type": "number"}
+	}
+}
+
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/$defs/" + name}
+}
+
+// TypeScriptDefinitions возвращает описания типов TypeScript (.d.ts) для формата ToJSON,
+// копия лежит в ast/schema/ast.d.ts
+func TypeScriptDefinitions() string {
+	builder := &strings.Builder{}
+	builder.WriteString("// Code generated by go generate ./ast; DO NOT EDIT.\n\n")
+	fmt.Fprintf(builder, "// Версия формата JSON, поле version корневого узла\nexport type JSONVersion = %d;\n\n", JSONVersion)
+
+	builder.WriteString("export interface Position {\n  Line: number;\n  Column: number;\n  Offset: number;\n}\n\n")
+	fmt.Fprintf(builder, "export interface DateLiteral {\n  type: %q;\n  Value: string;\n}\n\n", jsonDateType)
+
+	for _, t := range []reflect.Type{reflect.TypeOf(OpUndefined), reflect.TypeOf(PFTypeUndefined)} {
+		values := make([]string, 0, len(schemaEnums[t]))
+		for _, v := range schemaEnums[t] {
+			values = append(values, fmt.Sprint(v))
+		}
+		fmt.Fprintf(builder, "export type %s = %s;\n\n", t.Name(), strings.Join(values, " | "))
+	}
+
+	statement := []string{"string", "number", "boolean", "null", "DateLiteral"}
+	for _, n := range serializedNodes {
+		t := reflect.Indirect(reflect.ValueOf(n)).Type()
+		if !schemaNotStatements[t.Name()] {
+			statement = append(statement, t.Name())
+		}
+	}
+	fmt.Fprintf(builder, "export type Statement =\n  | %s;\n", strings.Join(statement, "\n  | "))
+
+	for _, n := range serializedNodes {
+		t := reflect.Indirect(reflect.ValueOf(n)).Type()
+
+		fmt.Fprintf(builder, "\nexport interface %s {\n", t.Name())
+		if t.Name() == "ModuleStatement" {
+			builder.WriteString("  version: JSONVersion;\n")
+		}
+		fmt.Fprintf(builder, "  type: %q;\n", t.Name())
+		for _, f := range schemaFields(t) {
+			optional := ""
+			if f.optional {
+				optional = "?"
+			}
+			fmt.Fprintf(builder, "  %s%s: %s;\n", f.name, optional, typeScriptType(f.typ))
+		}
+		builder.WriteString("}\n")
+	}
+
+	return builder.String()
+}
+
+func typeScriptType(t reflect.Type) string {
+	if _, ok := schemaEnums[t]; ok {
+		return t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return "Statement"
+	case reflect.Ptr:
+		return t.Elem().Name() + " | null"
+	case reflect.Slice:
+		return typeScriptType(sliceElem(t)) + "[] | null"
+	case reflect.Map:
+		return "Record<string, " + typeScriptType(t.Elem()) + "> | null"
+	case reflect.Struct:
+		return t.Name()
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return "number"
+	}
+}
+
+func enumRange(from, to int) []int {
+	result := make([]int, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		result = append(result, i)
+	}
+
+	return result
+}
+
+// sliceElem тип элементов списка, nil в списках узлов не бывает, поэтому указатель не учитываем
+func sliceElem(t reflect.Type) reflect.Type {
+	if t.Elem().Kind() == reflect.Ptr {
+		return t.Elem().Elem()
+	}
+
+	return t.Elem()
+}
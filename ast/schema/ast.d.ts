@@ -0,0 +1,259 @@
+// Code generated by go generate ./ast; DO NOT EDIT.
+
+// Версия формата JSON, поле version корневого узла
+export type JSONVersion = 2;
+
+export interface Position {
+  Line: number;
+  Column: number;
+  Offset: number;
+}
+
+export interface DateLiteral {
+  type: "Date";
+  Value: string;
+}
+
+export type OperationType = 0 | 1 | 2 | 3 | 4 | 5 | 6 | 7 | 8 | 9 | 10 | 11 | 12 | 13;
+
+export type StatementType = 0 | 1 | 2;
+
+export type Statement =
+  | string
+  | number
+  | boolean
+  | null
+  | DateLiteral
+  | FunctionOrProcedure
+  | DirectiveStatement
+  | AssignmentStatement
+  | ExprStatements
+  | VarStatement
+  | ExpStatement
+  | IfStatement
+  | TryStatement
+  | ThrowStatement
+  | UndefinedStatement
+  | ReturnStatement
+  | NewObjectStatement
+  | CallChainStatement
+  | MethodStatement
+  | BreakStatement
+  | ContinueStatement
+  | LoopStatement
+  | TernaryStatement
+  | ItemStatement
+  | GoToStatement
+  | GoToLabelStatement;
+
+export interface ModuleStatement {
+  version: JSONVersion;
+  type: "ModuleStatement";
+  Name: string;
+  GlobalVariables?: Record<string, GlobalVariables> | null;
+  Body: Statement[] | null;
+  Comments?: Comment[] | null;
+}
+
+export interface GlobalVariables {
+  type: "GlobalVariables";
+  Directive: DirectiveStatement | null;
+  Var: VarStatement;
+  Export: boolean;
+}
+
+export interface Comment {
+  type: "Comment";
+  Text: string;
+  Pos: Position;
+  End: Position;
+}
+
+export interface FunctionOrProcedure {
+  type: "FunctionOrProcedure";
+  ExplicitVariables: Record<string, VarStatement> | null;
+  Name: string;
+  Directives: DirectiveStatement[] | null;
+  Body: Statement[] | null;
+  Params: ParamStatement[] | null;
+  Type: StatementType;
+  Export: boolean;
+  Pos: Position;
+  End: Position;
+}
+
+export interface DirectiveStatement {
+  type: "DirectiveStatement";
+  Name: string;
+  Src: string;
+  Pos: Position;
+  End: Position;
+}
+
+export interface ParamStatement {
+  type: "ParamStatement";
+  Default?: Statement;
+  Name: string;
+  IsValue?: boolean;
+  Pos: Position;
+  End: Position;
+}
+
+export interface AssignmentStatement {
+  type: "AssignmentStatement";
+  Var: Statement;
+  Expr: ExprStatements;
+  Pos: Position;
+  End: Position;
+}
+
+export interface ExprStatements {
+  type: "ExprStatements";
+  Statements: Statement[] | null;
+  UnaryMinus?: boolean;
+  UnaryPlus?: boolean;
+  Not?: boolean;
+}
+
+export interface VarStatement {
+  type: "VarStatement";
+  Name: string;
+  UnaryMinus?: boolean;
+  UnaryPlus?: boolean;
+  Not?: boolean;
+  Pos: Position;
+  End: Position;
+}
+
+export interface ExpStatement {
+  type: "ExpStatement";
+  Left: Statement;
+  Right: Statement;
+  Operation: OperationType;
+  UnaryMinus?: boolean;
+  UnaryPlus?: boolean;
+  Not?: boolean;
+  Pos: Position;
+  End: Position;
+}
+
+export interface IfStatement {
+  type: "IfStatement";
+  Expression: Statement;
+  TrueBlock: Statement[] | null;
+  IfElseBlock: Statement[] | null;
+  ElseBlock: Statement[] | null;
+  Pos: Position;
+  End: Position;
+}
+
+export interface TryStatement {
+  type: "TryStatement";
+  Body: Statement[] | null;
+  Catch: Statement[] | null;
+  Pos: Position;
+  End: Position;
+}
+
+export interface ThrowStatement {
+  type: "ThrowStatement";
+  Param: Statement;
+  Pos: Position;
+  End: Position;
+}
+
+export interface UndefinedStatement {
+  type: "UndefinedStatement";
+}
+
+export interface ReturnStatement {
+  type: "ReturnStatement";
+  Param: Statement;
+  Pos: Position;
+  End: Position;
+}
+
+export interface NewObjectStatement {
+  type: "NewObjectStatement";
+  Constructor: string;
+  Param: ExprStatements;
+  Pos: Position;
+  End: Position;
+}
+
+export interface CallChainStatement {
+  type: "CallChainStatement";
+  Unit: Statement;
+  Call: Statement;
+  UnaryMinus?: boolean;
+  UnaryPlus?: boolean;
+  Not?: boolean;
+  Pos: Position;
+  End: Position;
+}
+
+export interface MethodStatement {
+  type: "MethodStatement";
+  Name: string;
+  Param: ExprStatements;
+  Embedded?: Statement[] | null;
+  UnaryMinus?: boolean;
+  UnaryPlus?: boolean;
+  Not?: boolean;
+  Pos: Position;
+  End: Position;
+}
+
+export interface BreakStatement {
+  type: "BreakStatement";
+  Pos: Position;
+  End: Position;
+}
+
+export interface ContinueStatement {
+  type: "ContinueStatement";
+  Pos: Position;
+  End: Position;
+}
+
+export interface LoopStatement {
+  type: "LoopStatement";
+  For?: Statement;
+  To?: Statement;
+  In?: Statement;
+  WhileExpr?: Statement;
+  Body: Statement[] | null;
+  Pos: Position;
+  End: Position;
+}
+
+export interface TernaryStatement {
+  type: "TernaryStatement";
+  Expression: Statement;
+  TrueBlock: Statement;
+  ElseBlock: Statement;
+  Pos: Position;
+  End: Position;
+}
+
+export interface ItemStatement {
+  type: "ItemStatement";
+  Item: Statement;
+  Object: Statement;
+  Pos: Position;
+  End: Position;
+}
+
+export interface GoToStatement {
+  type: "GoToStatement";
+  Label: GoToLabelStatement | null;
+  Pos: Position;
+  End: Position;
+}
+
+export interface GoToLabelStatement {
+  type: "GoToLabelStatement";
+  Name: string;
+  Pos: Position;
+  End: Position;
+}
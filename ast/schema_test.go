@@ -0,0 +1,78 @@
+package ast
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	t.Run("up to date", func(t *testing.T) {
+		// при изменении структур узлов схему нужно перегенерировать: go generate ./ast
+		schema, err := JSONSchema()
+		assert.NoError(t, err)
+
+		fileData, err := os.ReadFile("schema/ast.schema.json")
+		assert.NoError(t, err)
+		assert.Equal(t, string(schema)+"\n", string(fileData), "schema/ast.schema.json is stale, run go generate ./ast")
+
+		fileData, err = os.ReadFile("schema/ast.d.ts")
+		assert.NoError(t, err)
+		assert.Equal(t, TypeScriptDefinitions(), string(fileData), "schema/ast.d.ts is stale, run go generate ./ast")
+	})
+	t.Run("describes nodes", func(t *testing.T) {
+		data, err := JSONSchema()
+		assert.NoError(t, err)
+
+		var schema struct {
+			ID   string `json:"$id"`
+			Defs map[string]struct {
+				Properties map[string]json.RawMessage
+				Required   []string
+			} `json:"$defs"`
+		}
+		assert.NoError(t, json.Unmarshal(data, &schema))
+		assert.Contains(t, schema.ID, "/v2/")
+
+		assert.Contains(t, schema.Defs["ExpStatement"].Properties, "UnaryMinus")
+		assert.Equal(t, []string{"type", "Expression", "TrueBlock", "IfElseBlock", "ElseBlock", "Pos", "End"}, schema.Defs["IfStatement"].Required)
+		assert.Equal(t, []string{"type", "Body", "Pos", "End"}, schema.Defs["LoopStatement"].Required)
+		assert.Equal(t, []string{"type", "version", "Name", "Body"}, schema.Defs["ModuleStatement"].Required)
+
+		// каждый тип из JSON разобранного модуля описан в схеме
+		fileData, err := os.ReadFile("testdata")
+		assert.NoError(t, err)
+
+		a := NewAST(string(fileData))
+		assert.NoError(t, a.Parse())
+
+		module, err := a.JSON()
+		assert.NoError(t, err)
+
+		var walk func(v interface{})
+		walk = func(v interface{}) {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				if typ, ok := v["type"].(string); ok && typ != jsonDateType {
+					assert.Contains(t, schema.Defs, typ)
+					for k := range v {
+						assert.Contains(t, schema.Defs[typ].Properties, k, typ)
+					}
+				}
+				for _, item := range v {
+					walk(item)
+				}
+			case []interface{}:
+				for _, item := range v {
+					walk(item)
+				}
+			}
+		}
+
+		var tree interface{}
+		assert.NoError(t, json.Unmarshal(module, &tree))
+		walk(tree)
+	})
+}
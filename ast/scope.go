@@ -0,0 +1,439 @@
+package ast
+
+import (
+	"sort"
+	"strings"
+)
+
+// SymbolKind вид объявления, к которому относится имя
+type SymbolKind int
+
+const (
+	SymbolLocal     SymbolKind = iota // локальная переменная, объявленная первым присваиванием
+	SymbolVar                         // переменная, объявленная в методе через Перем
+	SymbolParam                       // параметр метода
+	SymbolModuleVar                   // переменная модуля
+	SymbolGlobal                      // свойство глобального контекста (Справочники, Метаданные...)
+	SymbolExternal                    // имя, не объявленное в модуле: реквизит формы или объекта, общий модуль
+)
+
+func (k SymbolKind) String() string {
+	switch k {
+	case SymbolLocal:
+		return "local"
+	case SymbolVar:
+		return "var"
+	case SymbolParam:
+		return "param"
+	case SymbolModuleVar:
+		return "module variable"
+	case SymbolGlobal:
+		return "global"
+	case SymbolExternal:
+		return "external"
+	default:
+		return ""
+	}
+}
+
+// Symbol объявленное имя. Для локальных переменных объявлением считается первое присваивание,
+// для глобальных и внешних имен объявления нет и Decl пустой
+type Symbol struct {
+	Name       string // написание из объявления
+	Kind       SymbolKind
+	Decl       Range
+	Export     bool
+	Method     *FunctionOrProcedure // метод, в котором объявлено имя, nil для модуля
+	References []*Reference
+}
+
+// Reference использование имени: чтение или присваивание
+type Reference struct {
+	Name   string
+	Range  Range
+	Write  bool
+	Loop   bool // присваивание переменной цикла Для, его нельзя убрать из кода
+	Symbol *Symbol
+	Method *FunctionOrProcedure // метод, в котором используется имя, nil для операторов модуля
+}
+
+// Scope область видимости метода или модуля
+type Scope struct {
+	Parent  *Scope
+	Method  *FunctionOrProcedure
+	symbols map[string]*Symbol
+	order   []*Symbol
+}
+
+// Resolution результат разрешения имен модуля
+type Resolution struct {
+	Module     *Scope                          // переменные модуля
+	Body       *Scope                          // операторы в конце модуля, родитель - Module
+	Methods    map[string]*FunctionOrProcedure // методы модуля по NormalizeName
+	References []*Reference                    // все использования имен в порядке обхода
+	scopes     map[*FunctionOrProcedure]*Scope
+	unresolved map[string]*Symbol
+	byOffset   map[int]*Reference
+}
+
+// NormalizeName приводит имя к виду, в котором сравнивает имена 1С: без учета регистра, ё равна е
+func NormalizeName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "ё", "е")
+}
+
+// Resolve строит области видимости модуля и его методов и связывает каждое использование переменной
+// (VarStatement) с объявлением: параметром, переменной Перем, переменной модуля или локальной переменной,
+// которую объявляет первое присваивание в методе. Имена, которые в модуле не объявлены, помечаются
+// как глобальный контекст или внешние (SymbolGlobal, SymbolExternal)
+func Resolve(module *ModuleStatement) *Resolution {
+	r := &Resolution{
+		Module:     newScope(nil, nil),
+		Methods:    map[string]*FunctionOrProcedure{},
+		scopes:     map[*FunctionOrProcedure]*Scope{},
+		unresolved: map[string]*Symbol{},
+		byOffset:   map[int]*Reference{},
+	}
+	r.Body = newScope(r.Module, nil)
+
+	for _, g := range sortedGlobalVariables(module.GlobalVariables) {
+		r.Module.declare(&Symbol{Name: g.Var.Name, Kind: SymbolModuleVar, Decl: RangeOf(g.Var), Export: g.Export})
+	}
+
+	var body Statements
+	for _, item := range module.Body {
+		if pf, ok := item.(*FunctionOrProcedure); ok {
+			r.Methods[NormalizeName(pf.Name)] = pf
+		} else {
+			body = append(body, item)
+		}
+	}
+
+	for _, item := range module.Body {
+		if pf, ok := item.(*FunctionOrProcedure); ok {
+			r.resolveMethod(pf)
+		}
+	}
+	(&resolver{Resolution: r, scope: r.Body, body: body}).statements(body)
+
+	return r
+}
+
+func (r *Resolution) resolveMethod(pf *FunctionOrProcedure) {
+	scope := newScope(r.Module, pf)
+	r.scopes[pf] = scope
+
+	for _, p := range pf.Params {
+		scope.declare(&Symbol{Name: p.Name, Kind: SymbolParam, Decl: RangeOf(p), Method: pf})
+	}
+
+	vars := make([]VarStatement, 0, len(pf.ExplicitVariables))
+	for _, v := range pf.ExplicitVariables {
+		vars = append(vars, v)
+	}
+	sortVars(vars)
+	for _, v := range vars {
+		scope.declare(&Symbol{Name: v.Name, Kind: SymbolVar, Decl: RangeOf(v), Method: pf})
+	}
+
+	(&resolver{Resolution: r, scope: scope, body: pf.Body}).statements(pf.Body)
+}
+
+// ScopeOf возвращает область видимости метода
+func (r *Resolution) ScopeOf(method *FunctionOrProcedure) *Scope {
+	return r.scopes[method]
+}
+
+// ReferenceOf возвращает использование имени по узлу. Узлы сопоставляются по позиции, поэтому
+// для узлов без позиций (созданных программно) результат не определен
+func (r *Resolution) ReferenceOf(v VarStatement) *Reference {
+	return r.byOffset[v.Pos.Offset]
+}
+
+// SymbolOf возвращает объявление, к которому относится узел
+func (r *Resolution) SymbolOf(v VarStatement) *Symbol {
+	if ref := r.ReferenceOf(v); ref != nil {
+		return ref.Symbol
+	}
+
+	return nil
+}
+
+// Unresolved возвращает глобальные и внешние имена в порядке первого использования
+func (r *Resolution) Unresolved() []*Symbol {
+	var result []*Symbol
+	for _, ref := range r.References {
+		if ref.Symbol.Kind >= SymbolGlobal && ref.Symbol.References[0] == ref {
+			result = append(result, ref.Symbol)
+		}
+	}
+
+	return result
+}
+
+// Lookup ищет имя в области видимости и ее родителях
+func (s *Scope) Lookup(name string) *Symbol {
+	key := NormalizeName(name)
+	for scope := s; scope != nil; scope = scope.Parent {
+		if sym, ok := scope.symbols[key]; ok {
+			return sym
+		}
+	}
+
+	return nil
+}
+
+// Symbols возвращает имена, объявленные в области видимости, в порядке объявления
+func (s *Scope) Symbols() []*Symbol {
+	return s.order
+}
+
+func newScope(parent *Scope, method *FunctionOrProcedure) *Scope {
+	return &Scope{Parent: parent, Method: method, symbols: map[string]*Symbol{}}
+}
+
+func (s *Scope) declare(sym *Symbol) *Symbol {
+	key := NormalizeName(sym.Name)
+	if existing, ok := s.symbols[key]; ok {
+		return existing
+	}
+
+	s.symbols[key] = sym
+	s.order = append(s.order, sym)
+	return sym
+}
+
+type resolver struct {
+	*Resolution
+	scope *Scope
+	body  Statements // операторы метода или модуля, в которых ищутся присваивания
+}
+
+func (r *resolver) statements(items Statements) {
+	for _, item := range items {
+		r.statement(item)
+	}
+}
+
+func (r *resolver) statement(stm Statement) {
+	switch v := stm.(type) {
+	case AssignmentStatement:
+		// правая часть вычисляется до присваивания: в а = а + 1 чтение идет раньше объявления
+		r.expressions(v.Expr.Statements)
+		r.target(v.Var)
+	case *IfStatement:
+		r.expression(v.Expression)
+		r.statements(v.TrueBlock)
+		r.statements(v.IfElseBlock)
+		r.statements(v.ElseBlock)
+	case *LoopStatement:
+		switch {
+		case v.WhileExpr != nil:
+			r.expression(v.WhileExpr)
+		case v.In != nil:
+			r.expression(v.In)
+			if name, ok := v.For.(string); ok {
+				// у имени переменной цикла нет своей позиции, используем позицию цикла
+				r.write(name, Range{Start: v.Pos, End: v.Pos}, true)
+			}
+		default:
+			// Для а = 0 По 10 Цикл, начальное значение разбирается как сравнение
+			if exp, ok := v.For.(*ExpStatement); ok && exp.Operation == OpEq {
+				r.expression(exp.Right)
+				if target, ok := exp.Left.(VarStatement); ok {
+					r.write(target.Name, RangeOf(target), true)
+				} else {
+					r.expression(exp.Left)
+				}
+			} else {
+				r.expression(v.For)
+			}
+			r.expression(v.To)
+		}
+		r.statements(v.Body)
+	case TryStatement:
+		r.statements(v.Body)
+		r.statements(v.Catch)
+	case ThrowStatement:
+		r.expression(v.Param)
+	case *ReturnStatement:
+		r.expression(v.Param)
+	case BreakStatement, ContinueStatement, GoToStatement, *GoToLabelStatement, nil:
+	default:
+		r.expression(stm)
+	}
+}
+
+func (r *resolver) expressions(items Statements) {
+	for _, item := range items {
+		r.expression(item)
+	}
+}
+
+func (r *resolver) expression(expr Statement) {
+	switch v := expr.(type) {
+	case VarStatement:
+		r.read(v)
+	case ExprStatements:
+		r.expressions(v.Statements)
+	case *ExpStatement:
+		r.expression(v.Left)
+		r.expression(v.Right)
+	case MethodStatement:
+		r.expressions(v.Param.Statements)
+		// код строки Выполнить выполняется в контексте вызывающего метода
+		r.statements(v.Embedded)
+	case NewObjectStatement:
+		r.expressions(v.Param.Statements)
+	case CallChainStatement:
+		r.expression(v.Call)
+		r.member(v.Unit)
+	case ItemStatement:
+		r.expression(v.Object)
+		r.expression(v.Item)
+	case TernaryStatement:
+		r.expression(v.Expression)
+		r.expression(v.TrueBlock)
+		r.expression(v.ElseBlock)
+	}
+}
+
+// member обращение после точки: имя свойства или метода объекта переменной не является
+func (r *resolver) member(unit Statement) {
+	switch v := unit.(type) {
+	case MethodStatement:
+		r.expressions(v.Param.Statements)
+	case ItemStatement:
+		r.member(v.Object)
+		r.expression(v.Item)
+	case VarStatement:
+	default:
+		r.expression(unit)
+	}
+}
+
+// target левая часть присваивания, а.б = 1 и а[0] = 1 только читают а
+func (r *resolver) target(target Statement) {
+	if v, ok := target.(VarStatement); ok {
+		r.write(v.Name, RangeOf(v), false)
+		return
+	}
+
+	r.expression(target)
+}
+
+func (r *resolver) write(name string, rng Range, loop bool) {
+	sym := r.scope.Lookup(name)
+	if sym == nil {
+		sym = r.scope.declare(&Symbol{Name: name, Kind: SymbolLocal, Decl: rng, Method: r.scope.Method})
+	}
+
+	ref := r.reference(sym, name, rng, true)
+	ref.Loop = loop
+}
+
+func (r *resolver) read(v VarStatement) {
+	sym := r.scope.Lookup(v.Name)
+	if sym == nil {
+		// локальная переменная может быть объявлена присваиванием ниже по тексту, использование до
+		// присваивания связываем с ней же, это ошибка в коде, а не внешнее имя
+		sym = r.laterLocal(v.Name)
+	}
+	if sym == nil {
+		sym = r.global(v.Name)
+	}
+
+	r.reference(sym, v.Name, RangeOf(v), false)
+}
+
+// laterLocal объявляет локальную переменную, если в текущем методе или модуле ниже есть присваивание этому имени
+func (r *resolver) laterLocal(name string) *Symbol {
+	var decl *Range
+	key := NormalizeName(name)
+	walkAssignments(r.body, func(target VarStatement) {
+		if decl == nil && NormalizeName(target.Name) == key {
+			rng := RangeOf(target)
+			decl = &rng
+		}
+	})
+	if decl == nil {
+		return nil
+	}
+
+	return r.scope.declare(&Symbol{Name: name, Kind: SymbolLocal, Decl: *decl, Method: r.scope.Method})
+}
+
+func (r *resolver) global(name string) *Symbol {
+	key := NormalizeName(name)
+	if sym, ok := r.unresolved[key]; ok {
+		return sym
+	}
+
+	sym := &Symbol{Name: name, Kind: SymbolExternal}
+	if _, ok := globalProperties[fastToLower(name)]; ok {
+		sym.Kind = SymbolGlobal
+	}
+	r.unresolved[key] = sym
+	return sym
+}
+
+func (r *resolver) reference(sym *Symbol, name string, rng Range, write bool) *Reference {
+	ref := &Reference{Name: name, Range: rng, Write: write, Symbol: sym, Method: r.scope.Method}
+	sym.References = append(sym.References, ref)
+	r.References = append(r.References, ref)
+	if rng != (Range{}) {
+		r.byOffset[rng.Start.Offset] = ref
+	}
+
+	return ref
+}
+
+// walkAssignments вызывает f для каждой переменной, которой присваивается значение
+func walkAssignments(items Statements, f func(target VarStatement)) {
+	for _, item := range items {
+		switch v := item.(type) {
+		case AssignmentStatement:
+			if target, ok := v.Var.(VarStatement); ok {
+				f(target)
+			}
+		case *IfStatement:
+			walkAssignments(v.TrueBlock, f)
+			walkAssignments(v.IfElseBlock, f)
+			walkAssignments(v.ElseBlock, f)
+		case *LoopStatement:
+			if exp, ok := v.For.(*ExpStatement); ok && exp.Operation == OpEq {
+				if target, ok := exp.Left.(VarStatement); ok {
+					f(target)
+				}
+			}
+			walkAssignments(v.Body, f)
+		case TryStatement:
+			walkAssignments(v.Body, f)
+			walkAssignments(v.Catch, f)
+		}
+	}
+}
+
+func sortedGlobalVariables(vars map[string]GlobalVariables) []GlobalVariables {
+	result := make([]GlobalVariables, 0, len(vars))
+	for _, g := range vars {
+		result = append(result, g)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Var.Pos.Offset != result[j].Var.Pos.Offset {
+			return result[i].Var.Pos.Offset < result[j].Var.Pos.Offset
+		}
+		return result[i].Var.Name < result[j].Var.Name
+	})
+
+	return result
+}
+
+func sortVars(vars []VarStatement) {
+	sort.Slice(vars, func(i, j int) bool {
+		if vars[i].Pos.Offset != vars[j].Pos.Offset {
+			return vars[i].Pos.Offset < vars[j].Pos.Offset
+		}
+		return vars[i].Name < vars[j].Name
+	})
+}
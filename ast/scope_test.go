@@ -0,0 +1,107 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	code := `Перем Счётчик Экспорт;
+Перем Кэш;
+
+Функция Посчитать(Параметр, Знач Лимит = 10)
+	Перем Итог;
+	Итог = 0;
+	Для Каждого Элемент Из Параметр Цикл
+		итог = Итог + Элемент.Количество;
+		СЧЕТЧИК = Счетчик + 1;
+	КонецЦикла;
+	Для Инд = 0 По Лимит Цикл
+		Сообщить(Инд);
+	КонецЦикла;
+	Если Врем > 0 Тогда
+		Возврат Врем;
+	КонецЕсли;
+	Врем = Справочники.Номенклатура.НайтиПоКоду(ОбщийМодуль.Код());
+	Возврат Итог;
+КонецФункции
+
+Кэш = Новый Соответствие;`
+
+	a := NewAST(code)
+	if !assert.NoError(t, a.Parse()) {
+		return
+	}
+
+	r := Resolve(&a.ModuleStatement)
+	pf := a.ModuleStatement.Body[0].(*FunctionOrProcedure)
+	assert.Equal(t, pf, r.Methods["посчитать"])
+
+	t.Run("scopes", func(t *testing.T) {
+		kinds := map[string]SymbolKind{}
+		for _, sym := range r.ScopeOf(pf).Symbols() {
+			kinds[sym.Name] = sym.Kind
+		}
+		assert.Equal(t, map[string]SymbolKind{
+			"Параметр": SymbolParam,
+			"Лимит":    SymbolParam,
+			"Итог":     SymbolVar,
+			"Элемент":  SymbolLocal,
+			"Инд":      SymbolLocal,
+			"Врем":     SymbolLocal,
+		}, kinds)
+
+		if assert.Len(t, r.Module.Symbols(), 2) {
+			assert.Equal(t, "Счётчик", r.Module.Symbols()[0].Name)
+			assert.True(t, r.Module.Symbols()[0].Export)
+			assert.Equal(t, SymbolModuleVar, r.Module.Symbols()[1].Kind)
+		}
+		assert.Empty(t, r.Body.Symbols())
+	})
+	t.Run("references", func(t *testing.T) {
+		counter := r.Module.Lookup("счетчик")
+		if assert.NotNil(t, counter) && assert.Len(t, counter.References, 2) {
+			// правая часть присваивания разрешается раньше левой
+			assert.False(t, counter.References[0].Write)
+			assert.True(t, counter.References[1].Write)
+			assert.Equal(t, "СЧЕТЧИК", counter.References[1].Name)
+			assert.Equal(t, pf, counter.References[1].Method)
+		}
+
+		total := r.ScopeOf(pf).Lookup("ИТОГ")
+		assert.Len(t, total.References, 4)
+		assert.Equal(t, Position{Line: 5, Column: 8, Offset: 152}, total.Decl.Start)
+
+		cache := r.Module.Lookup("Кэш")
+		if assert.Len(t, cache.References, 1) {
+			assert.Nil(t, cache.References[0].Method)
+			assert.True(t, cache.References[0].Write)
+		}
+
+		// чтение до первого присваивания связано с локальной переменной, объявленной ниже
+		tmp := r.ScopeOf(pf).Lookup("Врем")
+		if assert.Len(t, tmp.References, 3) {
+			assert.Equal(t, 17, tmp.Decl.Start.Line)
+			assert.False(t, tmp.References[0].Write)
+			assert.True(t, tmp.References[2].Write)
+		}
+
+		loop := pf.Body[1].(*LoopStatement)
+		assert.Equal(t, RangeOf(loop).Start, r.ScopeOf(pf).Lookup("Элемент").Decl.Start)
+		assert.Len(t, r.ScopeOf(pf).Lookup("Инд").References, 2)
+
+		assign := pf.Body[4].(AssignmentStatement)
+		assert.Equal(t, tmp, r.SymbolOf(assign.Var.(VarStatement)))
+		assert.Nil(t, r.SymbolOf(VarStatement{Name: "Врем"}))
+	})
+	t.Run("unresolved", func(t *testing.T) {
+		var names []string
+		for _, sym := range r.Unresolved() {
+			names = append(names, sym.Name+":"+sym.Kind.String())
+		}
+
+		// имена после точки (Количество, Номенклатура) и вызовы методов переменными не считаются
+		assert.Equal(t, []string{"Справочники:global", "ОбщийМодуль:external"}, names)
+	})
+}
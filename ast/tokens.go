@@ -16,18 +16,26 @@ type Iast interface {
 	SrsCode() string
 }
 
+// commentCollector реализуется разборщиком, которому нужны пропущенные лексером комментарии
+type commentCollector interface {
+	addComment(comment Comment)
+}
+
 type Position struct {
 	Line   int
 	Column int
+	Offset int // смещение в байтах от начала исходного кода
 }
 
 type Token struct {
-	ast      Iast
-	value    interface{}
-	literal  string
-	position Position
-	offset   int
-	prevDot  bool
+	ast       Iast
+	value     interface{}
+	literal   string
+	position  Position // позиция начала токена
+	offset    int
+	line      int // количество пройденных переводов строки
+	lineStart int // смещение начала текущей строки
+	prevDot   bool
 }
 
 const (
@@ -76,6 +84,43 @@ var (
 		// "массив":            Array,
 		// "структура":         Struct,
 		// "соответствие":      Dictionary,
+
+		// английский вариант встроенного языка
+		"procedure":    Procedure,
+		"var":          Var,
+		"goto":         GoTo,
+		"endprocedure": EndProcedure,
+		"val":          ValueParam,
+		"if":           If,
+		"then":         Then,
+		"elsif":        ElseIf,
+		"else":         Else,
+		"endif":        EndIf,
+		"for":          For,
+		"each":         Each,
+		"in":           In,
+		"to":           To,
+		"do":           Loop,
+		"enddo":        EndLoop,
+		"break":        Break,
+		"continue":     Continue,
+		"try":          Try,
+		"new":          New,
+		"except":       Catch,
+		"while":        While,
+		"endtry":       EndTry,
+		"function":     Function,
+		"endfunction":  EndFunction,
+		"return":       Return,
+		"raise":        Throw,
+		"and":          And,
+		"or":           OR,
+		"true":         True,
+		"false":        False,
+		"undefined":    Undefind,
+		"not":          Not,
+		"export":       Export,
+		"execute":      Execute,
 	}
 
 	// общие директивы
@@ -85,6 +130,11 @@ var (
 		"&насерверебезконтекста":          Directive,
 		"&наклиентенасерверебезконтекста": Directive,
 		"&наклиентенасервере":             Directive,
+		"&atclient":                       Directive,
+		"&atserver":                       Directive,
+		"&atservernocontext":              Directive,
+		"&atclientatservernocontext":      Directive,
+		"&atclientatserver":               Directive,
 	}
 
 	// директивы расширений
@@ -93,6 +143,10 @@ var (
 		"&после":              ExtDirective,
 		"&вместо":             ExtDirective,
 		"&изменениеиконтроль": ExtDirective,
+		"&before":             ExtDirective,
+		"&after":              ExtDirective,
+		"&around":             ExtDirective,
+		"&changeandvalidate":  ExtDirective,
 	}
 )
 
@@ -133,6 +187,7 @@ func (t *Token) next() (int, string, error) {
 	t.skipSpace()
 	t.skipComment()
 	t.skipRegions()
+	t.position = t.currentPosition()
 
 	if t.prevDot {
 		defer func() { t.prevDot = false }()
@@ -320,9 +375,13 @@ func (t *Token) skipSpace() {
 
 func (t *Token) skipComment() {
 	if t.currentLet() == '/' && t.nextLet() == '/' {
+		start := t.currentPosition()
 		for ch := t.currentLet(); ch != EOL && ch != EOF; ch = t.currentLet() {
 			t.nextPos()
 		}
+		if c, ok := t.ast.(commentCollector); ok {
+			c.addComment(t.comment(start))
+		}
 		t.skipSpace()
 	} else {
 		return
@@ -336,6 +395,19 @@ func (t *Token) skipComment() {
 	}
 }
 
+// comment возвращает комментарий от start до текущей позиции, перевод строки \r в комментарий не входит
+func (t *Token) comment(start Position) Comment {
+	end := t.currentPosition()
+	text := t.ast.SrsCode()[start.Offset:end.Offset]
+	if strings.HasSuffix(text, "\r") {
+		text = text[:len(text)-1]
+		end.Offset--
+		end.Column--
+	}
+
+	return Comment{Text: text, Pos: start, End: end}
+}
+
 func (t *Token) skipRegions() {
 	// todo пока будут пропускаться и условия типа #Если Не ВебКлиент Тогда, потом надо будет доработать
 	if t.currentLet() == '#' {
@@ -391,8 +463,38 @@ func (t *Token) GetPosition() Position {
 
 func (t *Token) nextPos() {
 	srsCode := t.ast.SrsCode()
-	_, size := utf8.DecodeRuneInString(srsCode[t.offset:])
+	char, size := utf8.DecodeRuneInString(srsCode[t.offset:])
 	t.offset += size
+
+	// номер строки считаем по ходу чтения, пересчитывать его от начала кода для каждого токена слишком дорого
+	if char == EOL {
+		t.line++
+		t.lineStart = t.offset
+	}
+}
+
+func (t *Token) currentPosition() Position {
+	srsCode := t.ast.SrsCode()
+
+	return Position{
+		Line:   t.line + 1,
+		Column: utf8.RuneCountInString(srsCode[t.lineStart:t.offset]) + 1,
+		Offset: t.offset,
+	}
+}
+
+// Position возвращает позицию начала токена в исходном коде
+func (t *Token) Position() Position {
+	return t.position
+}
+
+// end позиция сразу за токеном, годится для токенов, текст которых совпадает с литералом (идентификаторы, ключевые слова)
+func (t Token) end() Position {
+	return Position{
+		Line:   t.position.Line,
+		Column: t.position.Column + utf8.RuneCountInString(t.literal),
+		Offset: t.position.Offset + len(t.literal),
+	}
 }
 
 func (t *Token) scanNumber() (string, error) {
@@ -1,12 +1,11 @@
 // Code generated by goyacc .\grammar.y. DO NOT EDIT.
 
-//line .\grammar.y:1
-
+//line .\grammar.y:2
 package ast
 
 import __yyfmt__ "fmt"
 
-//line .\grammar.y:3
+//line .\grammar.y:2
 
 //line .\grammar.y:43
 type yySymType struct {
@@ -32,6 +31,7 @@ type yySymType struct {
 	opt_goToLabel              *GoToLabelStatement
 	directive                  *DirectiveStatement
 	directives                 []*DirectiveStatement
+	pos                        Position // позиция первого токена правила, для пустых правил не заполняется
 }
 
 const Directive = 57346
@@ -161,7 +161,7 @@ const yyEofCode = 1
 const yyErrCode = 2
 const yyInitialStackSize = 16
 
-//line .\grammar.y:387
+//line .\grammar.y:396
 
 //line yacctab:1
 var yyExca = [...]int8{
@@ -185,106 +185,105 @@ var yyExca = [...]int8{
 
 const yyPrivate = 57344
 
-const yyLast = 637
+const yyLast = 630
 
 var yyAct = [...]uint8{
-	73, 7, 126, 119, 7, 5, 154, 51, 202, 47,
-	19, 8, 22, 166, 24, 143, 167, 76, 57, 55,
-	75, 191, 153, 147, 146, 77, 72, 72, 120, 7,
-	120, 132, 72, 78, 84, 99, 98, 80, 82, 83,
-	71, 74, 68, 65, 69, 70, 57, 94, 95, 89,
-	120, 7, 145, 120, 120, 120, 63, 64, 58, 59,
-	60, 61, 62, 31, 96, 4, 18, 102, 183, 42,
-	104, 105, 106, 107, 108, 109, 110, 111, 112, 113,
-	114, 115, 116, 60, 61, 62, 185, 117, 181, 42,
-	63, 64, 58, 59, 60, 61, 62, 135, 31, 93,
-	46, 72, 41, 131, 54, 53, 32, 130, 171, 72,
-	134, 151, 148, 118, 176, 133, 101, 159, 97, 43,
-	120, 139, 7, 103, 92, 187, 79, 138, 174, 29,
-	144, 72, 168, 44, 45, 140, 88, 216, 152, 210,
-	157, 32, 7, 7, 72, 137, 204, 162, 163, 169,
-	150, 164, 158, 91, 160, 58, 59, 60, 61, 62,
-	86, 170, 87, 145, 172, 54, 53, 175, 54, 53,
-	182, 85, 177, 53, 182, 7, 186, 54, 53, 7,
-	188, 54, 53, 127, 192, 184, 142, 207, 165, 129,
-	7, 7, 128, 180, 195, 198, 199, 193, 197, 194,
-	200, 196, 122, 7, 125, 206, 56, 211, 209, 7,
-	208, 54, 53, 214, 212, 144, 54, 53, 144, 215,
-	53, 120, 217, 44, 45, 31, 141, 155, 178, 25,
-	179, 54, 53, 6, 26, 54, 53, 48, 190, 213,
-	13, 21, 201, 27, 46, 12, 28, 49, 2, 34,
-	33, 42, 3, 1, 15, 14, 30, 16, 52, 50,
-	11, 203, 37, 38, 40, 156, 39, 23, 32, 90,
-	100, 31, 36, 35, 173, 25, 9, 17, 10, 20,
-	26, 43, 0, 0, 0, 0, 13, 21, 0, 27,
-	46, 12, 28, 0, 0, 34, 33, 42, 0, 0,
-	15, 14, 0, 0, 0, 31, 0, 0, 37, 38,
-	40, 0, 39, 23, 32, 81, 0, 0, 36, 35,
-	0, 21, 0, 0, 46, 20, 0, 43, 0, 34,
-	33, 42, 0, 0, 0, 0, 0, 0, 31, 0,
-	0, 0, 37, 38, 40, 0, 39, 23, 32, 0,
-	0, 0, 36, 35, 21, 65, 0, 46, 0, 20,
-	0, 43, 34, 33, 42, 0, 0, 0, 63, 64,
-	58, 59, 60, 61, 62, 37, 38, 40, 0, 39,
-	23, 32, 0, 0, 0, 36, 35, 0, 68, 65,
-	69, 70, 20, 67, 43, 68, 65, 69, 70, 66,
-	67, 0, 63, 64, 58, 59, 60, 61, 62, 63,
-	64, 58, 59, 60, 61, 62, 68, 65, 69, 70,
-	66, 67, 136, 0, 0, 0, 0, 0, 0, 0,
-	63, 64, 58, 59, 60, 61, 62, 0, 0, 0,
-	205, 68, 65, 69, 70, 66, 67, 189, 161, 0,
-	0, 0, 0, 0, 0, 63, 64, 58, 59, 60,
-	61, 62, 120, 0, 0, 149, 68, 65, 69, 70,
-	66, 67, 0, 68, 65, 69, 70, 66, 67, 0,
-	63, 64, 58, 59, 60, 61, 62, 63, 64, 58,
-	59, 60, 61, 62, 0, 0, 68, 65, 69, 70,
-	66, 67, 124, 0, 0, 0, 0, 0, 123, 0,
-	63, 64, 58, 59, 60, 61, 62, 0, 0, 0,
-	68, 65, 69, 70, 66, 67, 121, 68, 65, 69,
+	73, 7, 119, 201, 7, 126, 8, 22, 143, 5,
+	51, 19, 165, 47, 166, 24, 76, 55, 57, 75,
+	190, 72, 72, 120, 77, 120, 120, 72, 78, 7,
+	120, 153, 80, 82, 83, 31, 120, 120, 84, 71,
+	74, 65, 94, 95, 57, 58, 59, 60, 61, 62,
+	147, 7, 4, 146, 63, 64, 58, 59, 60, 61,
+	62, 42, 102, 18, 96, 104, 105, 106, 107, 108,
+	109, 110, 111, 112, 113, 114, 115, 116, 32, 132,
+	31, 184, 117, 180, 170, 99, 98, 145, 151, 158,
+	89, 43, 93, 120, 148, 118, 72, 182, 131, 60,
+	61, 62, 42, 101, 72, 134, 130, 63, 64, 58,
+	59, 60, 61, 62, 133, 54, 53, 92, 97, 135,
+	103, 46, 7, 32, 44, 45, 72, 88, 167, 144,
+	140, 138, 29, 54, 53, 175, 145, 152, 41, 72,
+	156, 139, 7, 7, 137, 168, 91, 157, 215, 150,
+	186, 161, 162, 87, 163, 159, 54, 53, 209, 171,
+	169, 179, 79, 86, 173, 174, 176, 203, 181, 54,
+	53, 85, 181, 127, 185, 7, 183, 164, 7, 129,
+	187, 142, 128, 125, 188, 122, 53, 191, 206, 192,
+	7, 6, 196, 195, 194, 48, 193, 56, 7, 198,
+	46, 141, 7, 177, 207, 34, 33, 205, 7, 210,
+	189, 208, 200, 144, 3, 213, 144, 211, 37, 38,
+	40, 214, 39, 1, 216, 44, 45, 31, 36, 35,
+	30, 25, 16, 54, 53, 52, 26, 53, 120, 54,
+	53, 212, 13, 21, 50, 27, 46, 12, 28, 54,
+	53, 34, 33, 42, 49, 2, 15, 14, 199, 54,
+	53, 11, 202, 155, 37, 38, 40, 90, 39, 23,
+	32, 100, 172, 31, 36, 35, 154, 25, 178, 9,
+	17, 20, 26, 43, 10, 0, 0, 0, 13, 21,
+	0, 27, 46, 12, 28, 0, 0, 34, 33, 42,
+	0, 0, 15, 14, 0, 0, 0, 31, 0, 0,
+	37, 38, 40, 0, 39, 23, 32, 81, 0, 0,
+	36, 35, 0, 21, 0, 0, 46, 20, 0, 43,
+	0, 34, 33, 42, 0, 0, 0, 0, 0, 0,
+	31, 0, 0, 0, 37, 38, 40, 0, 39, 23,
+	32, 0, 0, 0, 36, 35, 21, 0, 0, 46,
+	0, 20, 0, 43, 34, 33, 42, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 37, 38, 40,
+	0, 39, 23, 32, 0, 0, 0, 36, 35, 0,
+	68, 65, 69, 70, 20, 67, 43, 68, 65, 69,
 	70, 66, 67, 0, 63, 64, 58, 59, 60, 61,
-	62, 63, 64, 58, 59, 60, 61, 62, 0, 0,
-	0, 0, 68, 65, 69, 70, 66, 67, 0, 68,
+	62, 63, 64, 58, 59, 60, 61, 62, 68, 65,
+	69, 70, 66, 67, 136, 0, 0, 0, 0, 0,
+	0, 0, 63, 64, 58, 59, 60, 61, 62, 0,
+	0, 0, 204, 68, 65, 69, 70, 66, 67, 197,
+	160, 0, 0, 0, 0, 0, 0, 63, 64, 58,
+	59, 60, 61, 62, 120, 0, 0, 149, 68, 65,
+	69, 70, 66, 67, 0, 68, 65, 69, 70, 66,
+	67, 0, 63, 64, 58, 59, 60, 61, 62, 63,
+	64, 58, 59, 60, 61, 62, 0, 0, 68, 65,
+	69, 70, 66, 67, 124, 0, 0, 0, 0, 0,
+	123, 0, 63, 64, 58, 59, 60, 61, 62, 0,
+	0, 0, 68, 65, 69, 70, 66, 67, 121, 68,
 	65, 69, 70, 66, 67, 0, 63, 64, 58, 59,
 	60, 61, 62, 63, 64, 58, 59, 60, 61, 62,
-	65, 69, 70, 0, 0, 0, 0, 65, 0, 70,
+	0, 0, 0, 0, 68, 65, 69, 70, 66, 67,
+	0, 68, 65, 69, 70, 66, 67, 0, 63, 64,
+	58, 59, 60, 61, 62, 63, 64, 58, 59, 60,
+	61, 62, 68, 65, 69, 70, 0, 0, 0, 0,
+	65, 69, 70, 0, 0, 0, 63, 64, 58, 59,
+	60, 61, 62, 63, 64, 58, 59, 60, 61, 62,
+	65, 0, 70, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 63, 64, 58, 59, 60, 61, 62,
-	63, 64, 58, 59, 60, 61, 62, 46, 0, 0,
-	0, 0, 34, 33, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 37, 38, 40, 0, 39,
-	0, 0, 0, 0, 0, 36, 35,
 }
 
 var yyPact = [...]int16{
-	216, -1000, -1000, 216, -1000, 231, -1000, -22, 519, -1000,
-	-1000, -1000, -1000, -1000, 329, 329, -1000, -1000, -46, -1000,
-	329, 329, -1000, 72, -1000, 329, 296, 329, 262, 160,
-	126, -14, 90, -1000, -1000, 329, 329, -1000, -1000, -1000,
-	-1000, -1000, 55, -27, -1000, -28, -1000, 231, -1000, -1000,
-	262, -1000, -1000, -1000, -1000, 329, 89, -1000, 329, 329,
-	329, 329, 329, 329, 329, 329, 329, 329, 329, 329,
-	329, -1000, 519, -50, -1000, 329, 49, -1000, 314, -1000,
-	512, 193, 487, 480, 173, 174, -1000, 183, 180, 329,
-	-1000, 329, -1000, -1000, -1000, -1000, -32, 329, 329, 63,
-	-1000, -1000, 519, -46, 25, 25, -1000, -1000, -1000, 99,
-	99, 36, 348, 2, 539, 546, 314, 355, -1000, 329,
-	-1000, 262, 101, 329, -1000, -1000, 114, -1000, -39, -40,
-	48, 401, 329, 47, 456, -42, -1000, -1000, 212, 54,
-	426, 262, 262, 168, 179, -1000, 123, 123, -1000, -1000,
-	44, -1000, 329, -1000, 112, 329, 92, -50, -1000, 34,
-	-1000, -1000, 207, 161, -1000, -1000, 24, 27, -1000, 176,
-	22, -1000, 456, 108, 262, 433, -1000, -43, 262, -1000,
-	-1000, 3, 123, 579, -1000, 3, 329, -1000, 231, 262,
-	262, -1000, 177, -1000, 27, -1000, 135, 376, 212, 164,
-	-1000, 135, 262, 128, 174, -1000, -1000, -1000, 262, 227,
-	174, 215, 100, -1000, 215, -1000, -1000, -1000,
+	218, -1000, -1000, 218, -1000, 245, -1000, -24, 521, -1000,
+	-1000, -1000, -1000, -1000, 331, 331, -1000, -1000, -47, -1000,
+	331, 331, -1000, 93, -1000, 331, 298, 331, 264, 160,
+	117, 27, 83, -1000, -1000, 331, 331, -1000, -1000, -1000,
+	-1000, -1000, 55, 23, -1000, 22, -1000, 245, -1000, -1000,
+	264, -1000, -1000, -1000, -1000, 331, 71, -1000, 331, 331,
+	331, 331, 331, 331, 331, 331, 331, 331, 331, 331,
+	331, -1000, 521, -50, -1000, 331, 31, -1000, 0, -1000,
+	514, 176, 489, 482, 152, 164, -1000, 173, 170, 331,
+	-1000, 331, -1000, -1000, -1000, -1000, 16, 331, 331, 85,
+	-1000, -1000, 521, -47, 41, 41, -1000, -1000, -1000, -11,
+	-11, 53, 350, 542, 549, 569, 0, 357, -1000, 331,
+	-1000, 264, 121, 331, -1000, -1000, 87, -1000, -10, -13,
+	30, 403, 331, 24, 458, -33, -1000, -1000, 245, 26,
+	428, 264, 264, 181, 168, -1000, 119, 119, -1000, -1000,
+	20, -1000, 331, -1000, 149, 113, -50, -1000, 67, -1000,
+	-1000, 255, 129, -1000, -1000, 19, 56, -1000, 167, 17,
+	-1000, 458, 133, 331, 264, -1000, -44, 264, -1000, -1000,
+	38, 119, 172, -1000, 38, 331, -1000, 435, 245, 264,
+	-1000, 235, -1000, 56, -1000, 156, 378, 264, 165, -1000,
+	156, 264, 147, 164, -1000, 245, -1000, 264, 229, 164,
+	232, 111, -1000, 232, -1000, -1000, -1000,
 }
 
 var yyPgo = [...]int16{
-	0, 247, 5, 65, 278, 277, 276, 6, 274, 270,
-	17, 11, 15, 10, 13, 16, 25, 269, 0, 265,
-	12, 14, 8, 261, 2, 260, 66, 102, 259, 7,
-	258, 257, 3, 129, 256, 253, 252, 233, 242, 238,
-	228, 226, 206, 186,
+	0, 254, 9, 52, 284, 280, 279, 276, 272, 271,
+	16, 6, 8, 11, 12, 14, 24, 267, 0, 263,
+	7, 15, 3, 262, 5, 261, 63, 138, 244, 10,
+	235, 232, 2, 132, 230, 223, 214, 191, 212, 210,
+	203, 201, 197, 181,
 }
 
 var yyR1 = [...]int8{
@@ -333,13 +332,13 @@ var yyChk = [...]int16{
 	6, 14, 9, 21, 22, 31, -24, 9, 9, 9,
 	-10, -11, 63, -10, -11, 34, 67, -16, -2, 20,
 	-11, -41, -43, -12, -32, 49, 63, 63, 64, 64,
-	-10, 64, -32, 64, -7, 15, -19, -18, -20, 63,
-	-21, 22, -2, -2, -29, 9, -14, -15, 9, 26,
-	-14, 64, -11, -8, 16, -11, 22, -20, -40, 23,
-	32, 64, -32, 41, 9, 64, -32, 17, -2, 14,
-	-39, 64, -2, -12, -15, -13, -12, -11, -2, -2,
-	23, -38, -22, -23, 11, 64, -7, 23, -22, -2,
-	11, -24, -2, 12, -24, -29, 37, -29,
+	-10, 64, -32, 64, -7, -19, -18, -20, 63, -21,
+	22, -2, -2, -29, 9, -14, -15, 9, 26, -14,
+	64, -11, -8, 15, 16, 22, -20, -40, 23, 32,
+	64, -32, 41, 9, 64, -32, 17, -11, -2, -39,
+	64, -2, -12, -15, -13, -12, -11, 14, -2, 23,
+	-38, -22, -23, 11, 64, -2, 23, -22, -2, 11,
+	-24, -2, 12, -24, -29, 37, -29,
 }
 
 var yyDef = [...]int8{
@@ -358,13 +357,13 @@ var yyDef = [...]int8{
 	114, 19, 0, 0, 41, 65, 13, 110, 0, 0,
 	0, 0, 87, 0, 0, 0, 60, 90, 32, 0,
 	0, 19, 19, 0, 0, 14, 103, 103, 59, 62,
-	0, 108, 0, 10, 34, 0, 0, 43, 44, 0,
-	46, 39, 0, 0, 15, 111, 0, 104, 100, 0,
-	0, 107, 0, 0, 19, 0, 37, 0, 19, 42,
-	66, 13, 0, 0, 101, 13, 0, 31, 35, 19,
-	19, 45, 0, 16, 105, 102, 27, 0, 32, 0,
-	40, 27, 19, 28, 0, 36, 33, 38, 19, 0,
-	0, 0, 0, 18, 0, 29, 17, 30,
+	0, 108, 0, 10, 34, 0, 43, 44, 0, 46,
+	39, 0, 0, 15, 111, 0, 104, 100, 0, 0,
+	107, 0, 0, 0, 19, 37, 0, 19, 42, 66,
+	13, 0, 0, 101, 13, 0, 31, 0, 35, 19,
+	45, 0, 16, 105, 102, 27, 0, 19, 0, 40,
+	27, 19, 28, 0, 36, 33, 38, 19, 0, 0,
+	0, 0, 18, 0, 29, 17, 30,
 }
 
 var yyTok1 = [...]int8{
@@ -732,12 +731,12 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:86
+//line .\grammar.y:87
 		{
 		}
 	case 2:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:87
+//line .\grammar.y:88
 		{
 			if ast, ok := yylex.(*AstNode); ok {
 				ast.ModuleStatement.Append(yyDollar[1].body, yylex)
@@ -745,7 +744,7 @@ yydefault:
 		}
 	case 3:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:92
+//line .\grammar.y:93
 		{
 			if ast, ok := yylex.(*AstNode); ok {
 				ast.ModuleStatement.Append(yyDollar[2].opt_body, yylex)
@@ -753,7 +752,7 @@ yydefault:
 		}
 	case 6:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:102
+//line .\grammar.y:103
 		{
 			if ast, ok := yylex.(*AstNode); ok {
 				ast.ModuleStatement.Append(yyDollar[1].global_variables, yylex)
@@ -761,7 +760,7 @@ yydefault:
 		}
 	case 7:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:107
+//line .\grammar.y:108
 		{
 			if ast, ok := yylex.(*AstNode); ok {
 				ast.ModuleStatement.Append(yyDollar[1].funcProc, yylex)
@@ -769,25 +768,25 @@ yydefault:
 		}
 	case 8:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:116
+//line .\grammar.y:117
 		{
 			yyVAL.directive = nil
 		}
 	case 9:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:117
+//line .\grammar.y:118
 		{
-			yyVAL.directive = &DirectiveStatement{Name: yyDollar[1].token.literal}
+			yyVAL.directive = &DirectiveStatement{Name: yyDollar[1].token.literal, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 10:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line .\grammar.y:118
+//line .\grammar.y:119
 		{
-			yyVAL.directive = &DirectiveStatement{Name: yyDollar[1].token.literal, Src: yyDollar[3].token.literal}
+			yyVAL.directive = &DirectiveStatement{Name: yyDollar[1].token.literal, Src: yyDollar[3].token.literal, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 11:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:121
+//line .\grammar.y:122
 		{
 			if yyDollar[1].directive != nil {
 				yyVAL.directives = []*DirectiveStatement{yyDollar[1].directive}
@@ -797,25 +796,25 @@ yydefault:
 		}
 	case 12:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:122
+//line .\grammar.y:123
 		{
 			yyVAL.directives = append(yyVAL.directives, yyDollar[2].directive)
 		}
 	case 13:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:124
+//line .\grammar.y:125
 		{
 			yyVAL.opt_export = nil
 		}
 	case 14:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:125
+//line .\grammar.y:126
 		{
 			yyVAL.opt_export = &yyDollar[1].token
 		}
 	case 15:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line .\grammar.y:128
+//line .\grammar.y:129
 		{
 			yyVAL.global_variables = make([]GlobalVariables, len(yyDollar[3].identifiers), len(yyDollar[3].identifiers))
 			for i, v := range yyDollar[3].identifiers {
@@ -824,49 +823,49 @@ yydefault:
 				}
 
 				yyVAL.global_variables[i].Export = yyDollar[4].opt_export != nil
-				yyVAL.global_variables[i].Var = VarStatement{Name: v.literal}
+				yyVAL.global_variables[i].Var = VarStatement{Name: v.literal, Pos: v.position, End: v.end()}
 			}
 		}
 	case 16:
 		yyDollar = yyS[yypt-7 : yypt+1]
-//line .\grammar.y:141
+//line .\grammar.y:142
 		{
 			isFunction(true, yylex)
 		}
 	case 17:
 		yyDollar = yyS[yypt-11 : yypt+1]
-//line .\grammar.y:142
+//line .\grammar.y:143
 		{
-			yyVAL.funcProc = createFunctionOrProcedure(PFTypeFunction, yyDollar[1].directives, yyDollar[3].token.literal, yyDollar[5].declarations_method_params, yyDollar[7].opt_export, yyDollar[9].opt_explicit_variables, yyDollar[10].opt_body)
+			yyVAL.funcProc = createFunctionOrProcedure(PFTypeFunction, yyDollar[2].token.position, nodeEnd(yylex, yyrcvr.char), yyDollar[1].directives, yyDollar[3].token.literal, yyDollar[5].declarations_method_params, yyDollar[7].opt_export, yyDollar[9].opt_explicit_variables, yyDollar[10].opt_body)
 			isFunction(false, yylex)
 		}
 	case 18:
 		yyDollar = yyS[yypt-10 : yypt+1]
-//line .\grammar.y:147
+//line .\grammar.y:148
 		{
-			yyVAL.funcProc = createFunctionOrProcedure(PFTypeProcedure, yyDollar[1].directives, yyDollar[3].token.literal, yyDollar[5].declarations_method_params, yyDollar[7].opt_export, yyDollar[8].opt_explicit_variables, yyDollar[9].opt_body)
+			yyVAL.funcProc = createFunctionOrProcedure(PFTypeProcedure, yyDollar[2].token.position, nodeEnd(yylex, yyrcvr.char), yyDollar[1].directives, yyDollar[3].token.literal, yyDollar[5].declarations_method_params, yyDollar[7].opt_export, yyDollar[8].opt_explicit_variables, yyDollar[9].opt_body)
 		}
 	case 19:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:152
+//line .\grammar.y:153
 		{
 			yyVAL.opt_body = nil
 		}
 	case 20:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:153
+//line .\grammar.y:154
 		{
 			yyVAL.opt_body = yyDollar[1].body
 		}
 	case 21:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:157
+//line .\grammar.y:158
 		{
 			yyVAL.body = Statements{yyDollar[1].stmt}
 		}
 	case 22:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:158
+//line .\grammar.y:159
 		{
 			if yyDollar[2].token.literal == ":" && len(yyDollar[1].opt_body) > 0 {
 				if _, ok := yyDollar[1].opt_body[len(yyDollar[1].opt_body)-1].(*GoToLabelStatement); !ok {
@@ -879,43 +878,43 @@ yydefault:
 		}
 	case 23:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:171
+//line .\grammar.y:172
 		{
 			yyVAL.stmt = nil
 		}
 	case 24:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:172
+//line .\grammar.y:173
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 25:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:175
+//line .\grammar.y:176
 		{
 			yyVAL.token = yyDollar[1].token
 		}
 	case 26:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:175
+//line .\grammar.y:176
 		{
 			yyVAL.token = yyDollar[1].token
 		}
 	case 27:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:179
+//line .\grammar.y:180
 		{
 			yyVAL.opt_explicit_variables = map[string]VarStatement{}
 		}
 	case 28:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:180
+//line .\grammar.y:181
 		{
 			yyVAL.opt_explicit_variables = yyDollar[1].explicit_variables
 		}
 	case 29:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:183
+//line .\grammar.y:184
 		{
 			if vars, err := appendVarStatements(map[string]VarStatement{}, yyDollar[2].identifiers); err != nil {
 				yylex.Error(err.Error())
@@ -925,7 +924,7 @@ yydefault:
 		}
 	case 30:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line .\grammar.y:190
+//line .\grammar.y:191
 		{
 			if vars, err := appendVarStatements(yyDollar[1].explicit_variables, yyDollar[3].identifiers); err != nil {
 				yylex.Error(err.Error())
@@ -935,9 +934,10 @@ yydefault:
 		}
 	case 31:
 		yyDollar = yyS[yypt-7 : yypt+1]
-//line .\grammar.y:201
+//line .\grammar.y:202
 		{
 			yyVAL.stmt_if = &IfStatement{
+				Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char),
 				Expression:  yyDollar[2].stmt,
 				TrueBlock:   yyDollar[4].opt_body,
 				IfElseBlock: yyDollar[5].opt_elseif_list,
@@ -946,36 +946,39 @@ yydefault:
 		}
 	case 32:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:211
+//line .\grammar.y:213
 		{
 			yyVAL.opt_elseif_list = Statements{}
 		}
 	case 33:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line .\grammar.y:212
+//line .\grammar.y:214
 		{
-			yyVAL.opt_elseif_list = append(yyDollar[5].opt_elseif_list, &IfStatement{
-				Expression: yyDollar[2].stmt,
-				TrueBlock:  yyDollar[4].opt_body,
+			// список леворекурсивный, так ветка разбирается целиком до следующего ИначеЕсли и ее границы в исходном коде известны
+			yyVAL.opt_elseif_list = append(yyDollar[1].opt_elseif_list, &IfStatement{
+				Pos: yyDollar[2].token.position, End: nodeEnd(yylex, yyrcvr.char),
+				Expression: yyDollar[3].stmt,
+				TrueBlock:  yyDollar[5].opt_body,
 			})
 		}
 	case 34:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:220
+//line .\grammar.y:224
 		{
 			yyVAL.opt_else = nil
 		}
 	case 35:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:221
+//line .\grammar.y:225
 		{
 			yyVAL.opt_else = yyDollar[2].opt_body
 		}
 	case 36:
 		yyDollar = yyS[yypt-8 : yypt+1]
-//line .\grammar.y:224
+//line .\grammar.y:228
 		{
 			yyVAL.stmt = TernaryStatement{
+				Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char),
 				Expression: yyDollar[3].stmt,
 				TrueBlock:  yyDollar[5].stmt,
 				ElseBlock:  yyDollar[7].stmt,
@@ -983,15 +986,16 @@ yydefault:
 		}
 	case 37:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line .\grammar.y:233
+//line .\grammar.y:238
 		{
 			setLoopFlag(true, yylex)
 		}
 	case 38:
 		yyDollar = yyS[yypt-9 : yypt+1]
-//line .\grammar.y:233
+//line .\grammar.y:238
 		{
 			yyVAL.stmt_loop = &LoopStatement{
+				Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char),
 				For:  yyDollar[3].token.literal,
 				In:   yyDollar[5].stmt,
 				Body: yyDollar[8].opt_body,
@@ -1000,15 +1004,16 @@ yydefault:
 		}
 	case 39:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line .\grammar.y:241
+//line .\grammar.y:247
 		{
 			setLoopFlag(true, yylex)
 		}
 	case 40:
 		yyDollar = yyS[yypt-8 : yypt+1]
-//line .\grammar.y:241
+//line .\grammar.y:247
 		{
 			yyVAL.stmt_loop = &LoopStatement{
+				Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char),
 				For:  yyDollar[2].stmt,
 				To:   yyDollar[4].stmt,
 				Body: yyDollar[7].opt_body,
@@ -1017,289 +1022,290 @@ yydefault:
 		}
 	case 41:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:249
+//line .\grammar.y:256
 		{
 			setLoopFlag(true, yylex)
 		}
 	case 42:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line .\grammar.y:249
+//line .\grammar.y:256
 		{
 			yyVAL.stmt_loop = &LoopStatement{
+				Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char),
 				WhileExpr: yyDollar[2].stmt,
 				Body:      yyDollar[5].opt_body,
 			}
 		}
 	case 43:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:258
+//line .\grammar.y:266
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 44:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:259
+//line .\grammar.y:267
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 45:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:260
+//line .\grammar.y:268
 		{
 			yyVAL.stmt = yyDollar[2].stmt
 		}
 	case 46:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:261
+//line .\grammar.y:269
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 47:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:265
+//line .\grammar.y:273
 		{
 			v := yyDollar[1].stmt
 			if tok, ok := yyDollar[1].stmt.(Token); ok {
-				v = VarStatement{Name: tok.literal}
+				v = VarStatement{Name: tok.literal, Pos: tok.position, End: tok.end()}
 			}
-			yyVAL.stmt = AssignmentStatement{Var: v, Expr: ExprStatements{Statements: Statements{yyDollar[3].stmt}}}
+			yyVAL.stmt = AssignmentStatement{Var: v, Expr: ExprStatements{Statements: Statements{yyDollar[3].stmt}}, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 48:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:272
+//line .\grammar.y:280
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 49:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:273
+//line .\grammar.y:281
 		{
 			yyVAL.stmt = yyDollar[1].stmt_if
 		}
 	case 50:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:274
+//line .\grammar.y:282
 		{
 			yyVAL.stmt = yyDollar[1].stmt_loop
 		}
 	case 51:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:275
+//line .\grammar.y:283
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 52:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:276
+//line .\grammar.y:284
 		{
-			yyVAL.stmt = ContinueStatement{}
+			yyVAL.stmt = ContinueStatement{Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 			checkLoopOperator(yyDollar[1].token, yylex)
 		}
 	case 53:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:277
+//line .\grammar.y:285
 		{
-			yyVAL.stmt = BreakStatement{}
+			yyVAL.stmt = BreakStatement{Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 			checkLoopOperator(yyDollar[1].token, yylex)
 		}
 	case 54:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:278
+//line .\grammar.y:286
 		{
-			yyVAL.stmt = ThrowStatement{Param: yyDollar[2].stmt}
+			yyVAL.stmt = ThrowStatement{Param: yyDollar[2].stmt, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 			checkThrowParam(yyDollar[1].token, yyDollar[2].stmt, yylex)
 		}
 	case 55:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:279
+//line .\grammar.y:287
 		{
-			yyVAL.stmt = &ReturnStatement{Param: yyDollar[2].stmt}
+			yyVAL.stmt = &ReturnStatement{Param: yyDollar[2].stmt, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 			checkReturnParam(yyDollar[2].stmt, yylex)
 		}
 	case 56:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:284
+//line .\grammar.y:292
 		{
-			yyVAL.stmt = yyDollar[1].stmt
+			yyVAL.stmt = evalCall(yylex, yyDollar[1].stmt)
 		}
 	case 57:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:285
+//line .\grammar.y:293
 		{
-			yyVAL.stmt = CallChainStatement{Unit: yyDollar[3].stmt, Call: yyDollar[1].stmt}
+			yyVAL.stmt = CallChainStatement{Unit: yyDollar[3].stmt, Call: yyDollar[1].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 58:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:291
+//line .\grammar.y:300
 		{
-			yyVAL.stmt = VarStatement{Name: yyDollar[1].token.literal}
+			yyVAL.stmt = VarStatement{Name: yyDollar[1].token.literal, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 59:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line .\grammar.y:292
+//line .\grammar.y:301
 		{
-			yyVAL.stmt = MethodStatement{Name: yyDollar[1].token.literal, Param: yyDollar[3].exprs}
+			yyVAL.stmt = MethodStatement{Name: yyDollar[1].token.literal, Param: yyDollar[3].exprs, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 60:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line .\grammar.y:293
+//line .\grammar.y:302
 		{
-			yyVAL.stmt = ItemStatement{Object: yyDollar[1].stmt, Item: yyDollar[3].stmt}
+			yyVAL.stmt = ItemStatement{Object: yyDollar[1].stmt, Item: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 61:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:294
+//line .\grammar.y:303
 		{
-			yyVAL.stmt = MethodStatement{Name: yyDollar[1].token.literal, Param: ExprStatements{Statements: Statements{yyDollar[2].stmt}}}
+			yyVAL.stmt = embedCode(yylex, MethodStatement{Name: yyDollar[1].token.literal, Param: ExprStatements{Statements: Statements{yyDollar[2].stmt}}, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)})
 		}
 	case 62:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line .\grammar.y:295
+//line .\grammar.y:304
 		{
-			yyVAL.stmt = MethodStatement{Name: yyDollar[1].token.literal, Param: ExprStatements{Statements: Statements{yyDollar[3].stmt}}}
+			yyVAL.stmt = embedCode(yylex, MethodStatement{Name: yyDollar[1].token.literal, Param: ExprStatements{Statements: Statements{yyDollar[3].stmt}}, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)})
 		}
 	case 63:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:298
+//line .\grammar.y:307
 		{
 			yyVAL.stmt = yyDollar[1].token.value
 		}
 	case 64:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:299
+//line .\grammar.y:308
 		{
-			yyVAL.stmt = VarStatement{Name: yyDollar[1].token.literal}
+			yyVAL.stmt = VarStatement{Name: yyDollar[1].token.literal, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 65:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:302
+//line .\grammar.y:311
 		{
 			setTryFlag(true, yylex)
 		}
 	case 66:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line .\grammar.y:302
+//line .\grammar.y:311
 		{
-			yyVAL.stmt = TryStatement{Body: yyDollar[2].opt_body, Catch: yyDollar[5].opt_body}
+			yyVAL.stmt = TryStatement{Body: yyDollar[2].opt_body, Catch: yyDollar[5].opt_body, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 			setTryFlag(false, yylex)
 		}
 	case 67:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:308
+//line .\grammar.y:317
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 68:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:309
+//line .\grammar.y:318
 		{
 			yyVAL.stmt = yyDollar[2].exprs
 		}
 	case 69:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:310
+//line .\grammar.y:319
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpPlus, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpPlus, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 70:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:311
+//line .\grammar.y:320
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpMinus, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpMinus, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 71:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:312
+//line .\grammar.y:321
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpMul, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpMul, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 72:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:313
+//line .\grammar.y:322
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpDiv, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpDiv, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 73:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:314
+//line .\grammar.y:323
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpMod, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpMod, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 74:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:315
+//line .\grammar.y:324
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpGt, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpGt, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 75:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:316
+//line .\grammar.y:325
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpLt, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpLt, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 76:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:317
+//line .\grammar.y:326
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpEq, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpEq, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 77:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:318
+//line .\grammar.y:327
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpOr, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpOr, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 78:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:319
+//line .\grammar.y:328
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpAnd, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpAnd, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 79:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:320
+//line .\grammar.y:329
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpNe, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpNe, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 80:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:321
+//line .\grammar.y:330
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpLe, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpLe, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 81:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:322
+//line .\grammar.y:331
 		{
-			yyVAL.stmt = &ExpStatement{Operation: OpGe, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt}
+			yyVAL.stmt = &ExpStatement{Operation: OpGe, Left: yyDollar[1].stmt, Right: yyDollar[3].stmt, Pos: yyDollar[1].pos, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 82:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:323
+//line .\grammar.y:332
 		{
 			yyVAL.stmt = not(yyDollar[2].stmt)
 		}
 	case 83:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:324
+//line .\grammar.y:333
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 84:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:325
+//line .\grammar.y:334
 		{
-			yyVAL.stmt = GoToStatement{Label: yyDollar[2].goToLabel}
+			yyVAL.stmt = GoToStatement{Label: yyDollar[2].goToLabel, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 85:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:326
+//line .\grammar.y:335
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 86:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:327
+//line .\grammar.y:336
 		{
 			if tok, ok := yyDollar[1].stmt.(Token); ok {
 				yyVAL.stmt = tok.literal
@@ -1309,169 +1315,169 @@ yydefault:
 		}
 	case 87:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:336
+//line .\grammar.y:345
 		{
 			yyVAL.stmt = nil
 		}
 	case 88:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:336
+//line .\grammar.y:345
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 89:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:338
+//line .\grammar.y:347
 		{
 			yyVAL.exprs = ExprStatements{Statements: Statements{yyDollar[1].stmt}}
 		}
 	case 90:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:339
+//line .\grammar.y:348
 		{
 			yyVAL.exprs.Statements = append(yyVAL.exprs.Statements, yyDollar[3].stmt)
 		}
 	case 91:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:342
+//line .\grammar.y:351
 		{
 			yyVAL.stmt = yyDollar[1].token.value
 		}
 	case 92:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:343
+//line .\grammar.y:352
 		{
 			yyVAL.stmt = yyDollar[1].token.value
 		}
 	case 93:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:344
+//line .\grammar.y:353
 		{
 			yyVAL.stmt = unaryMinus(yyDollar[2].stmt)
 		}
 	case 94:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:345
+//line .\grammar.y:354
 		{
 			yyVAL.stmt = yyDollar[2].stmt
 		}
 	case 95:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:346
+//line .\grammar.y:355
 		{
 			yyVAL.stmt = yyDollar[1].token.value
 		}
 	case 96:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:347
+//line .\grammar.y:356
 		{
 			yyVAL.stmt = yyDollar[1].token.value
 		}
 	case 97:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:348
+//line .\grammar.y:357
 		{
 			yyVAL.stmt = yyDollar[1].token.value
 		}
 	case 98:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:349
+//line .\grammar.y:358
 		{
 			yyVAL.stmt = UndefinedStatement{}
 		}
 	case 99:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:350
+//line .\grammar.y:359
 		{
 			yyVAL.stmt = yyDollar[1].goToLabel
 		}
 	case 100:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:354
+//line .\grammar.y:363
 		{
 			yyVAL.declarations_method_param = *(&ParamStatement{}).Fill(nil, yyDollar[1].token)
 		}
 	case 101:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:355
+//line .\grammar.y:364
 		{
 			yyVAL.declarations_method_param = *(&ParamStatement{}).Fill(&yyDollar[1].token, yyDollar[2].token)
 		}
 	case 102:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:356
+//line .\grammar.y:365
 		{
 			yyVAL.declarations_method_param = *(yyVAL.declarations_method_param.DefaultValue(yyDollar[3].stmt))
 		}
 	case 103:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line .\grammar.y:359
+//line .\grammar.y:368
 		{
 			yyVAL.declarations_method_params = []ParamStatement{}
 		}
 	case 104:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:360
+//line .\grammar.y:369
 		{
 			yyVAL.declarations_method_params = []ParamStatement{yyDollar[1].declarations_method_param}
 		}
 	case 105:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:361
+//line .\grammar.y:370
 		{
 			yyVAL.declarations_method_params = append(yyDollar[1].declarations_method_params, yyDollar[3].declarations_method_param)
 		}
 	case 106:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line .\grammar.y:369
+//line .\grammar.y:378
 		{
-			yyVAL.stmt = NewObjectStatement{Constructor: yyDollar[2].token.literal}
+			yyVAL.stmt = NewObjectStatement{Constructor: yyDollar[2].token.literal, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 107:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line .\grammar.y:370
+//line .\grammar.y:379
 		{
-			yyVAL.stmt = NewObjectStatement{Constructor: yyDollar[2].token.literal, Param: yyDollar[4].exprs}
+			yyVAL.stmt = NewObjectStatement{Constructor: yyDollar[2].token.literal, Param: yyDollar[4].exprs, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 108:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line .\grammar.y:371
+//line .\grammar.y:380
 		{
-			yyVAL.stmt = NewObjectStatement{Param: yyDollar[3].exprs}
+			yyVAL.stmt = NewObjectStatement{Param: yyDollar[3].exprs, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 109:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:376
+//line .\grammar.y:385
 		{
-			yyVAL.goToLabel = &GoToLabelStatement{Name: yyDollar[1].token.literal}
+			yyVAL.goToLabel = &GoToLabelStatement{Name: yyDollar[1].token.literal, Pos: yyDollar[1].token.position, End: nodeEnd(yylex, yyrcvr.char)}
 		}
 	case 110:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:378
+//line .\grammar.y:387
 		{
 			yyVAL.identifiers = []Token{yyDollar[1].token}
 		}
 	case 111:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line .\grammar.y:379
+//line .\grammar.y:388
 		{
 			yyVAL.identifiers = append(yyVAL.identifiers, yyDollar[3].token)
 		}
 	case 112:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:382
+//line .\grammar.y:391
 		{
 			yyVAL.token = yyDollar[1].token
 		}
 	case 113:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:383
+//line .\grammar.y:392
 		{
 			yyVAL.token = yyDollar[1].token
 		}
 	case 114:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line .\grammar.y:384
+//line .\grammar.y:393
 		{
 			yyVAL.token = yyDollar[1].token
 		}
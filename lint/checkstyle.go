@@ -0,0 +1,63 @@
+package lint
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// CheckstyleReporter выводит отчет в формате Checkstyle XML (Jenkins Warnings, reviewdog и т.п.)
+type CheckstyleReporter struct{}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func (r *CheckstyleReporter) Report(w io.Writer, diagnostics []Diagnostic) error {
+	report := checkstyleReport{Version: "8.0"}
+	files, byFile := groupByFile(diagnostics)
+	for _, file := range files {
+		item := checkstyleFile{Name: file}
+		for _, d := range byFile[file] {
+			item.Errors = append(item.Errors, checkstyleError{
+				Line:     d.Range.Start.Line,
+				Column:   d.Range.Start.Column,
+				Severity: d.Severity.String(),
+				Message:  d.Message,
+				Source:   toolName + "." + d.Rule,
+			})
+		}
+		report.Files = append(report.Files, item)
+	}
+
+	return writeXML(w, report)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
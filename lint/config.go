@@ -0,0 +1,195 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LazarenkoA/1c-language-parser/analysis"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Config настройки проверки. Пример в YAML:
+//
+//	exclude:
+//	  - "**/Ext/ObjectModule.bsl"
+//	rules:
+//	  UnusedParameter:
+//	    params:
+//	      eventHandlers: [МойОбработчик]
+//	  IgnoredFunctionResult:
+//	    enabled: false
+//	  UseBeforeAssignment:
+//	    severity: error
+//
+// Правила, не упомянутые в конфигурации, включены с важностью по умолчанию
+type Config struct {
+	Exclude []string              `json:"exclude,omitempty" yaml:"exclude,omitempty"` // шаблоны путей относительно проверяемого каталога
+	Rules   map[string]RuleConfig `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// RuleConfig настройки одного правила
+type RuleConfig struct {
+	Enabled  *bool                  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Severity string                 `json:"severity,omitempty" yaml:"severity,omitempty"` // error, warning или info
+	Params   map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// LoadConfig читает конфигурацию из файла. Формат определяется по расширению: .json или .yaml/.yml
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read config error")
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParseConfig(data, "json")
+	case ".yaml", ".yml":
+		return ParseConfig(data, "yaml")
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", filepath.Ext(path))
+	}
+}
+
+// ParseConfig разбирает конфигурацию в формате format ("json" или "yaml")
+func ParseConfig(data []byte, format string) (*Config, error) {
+	conf := &Config{}
+
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(conf); err != nil {
+			return nil, errors.Wrap(err, "config decode error")
+		}
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		// пустой файл - конфигурация по умолчанию
+		if err := decoder.Decode(conf); err != nil && !errors.Is(err, io.EOF) {
+			return nil, errors.Wrap(err, "config decode error")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+
+	return conf, conf.validate()
+}
+
+func (c *Config) validate() error {
+	for id, rule := range c.Rules {
+		if _, err := parseSeverity(rule.Severity); err != nil {
+			return errors.Wrapf(err, "rule %q", id)
+		}
+	}
+	for _, pattern := range c.Exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "exclude pattern %q", pattern)
+		}
+	}
+
+	return nil
+}
+
+// Validate проверяет, что все правила конфигурации есть в реестре: опечатка в ID иначе молча оставила бы
+// правило с настройками по умолчанию. Runner.Run вызывает ее сам
+func (c *Config) Validate(registry *Registry) error {
+	if c == nil {
+		return nil
+	}
+
+	var unknown []string
+	for id := range c.Rules {
+		if _, ok := registry.Rule(id); !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown rules %q", unknown)
+	}
+
+	return nil
+}
+
+// enabled проверяет, включено ли правило
+func (c *Config) enabled(id string) bool {
+	if c == nil {
+		return true
+	}
+	if rule, ok := c.Rules[id]; ok && rule.Enabled != nil {
+		return *rule.Enabled
+	}
+
+	return true
+}
+
+// severity возвращает важность правила id с учетом конфигурации, def - важность по умолчанию
+func (c *Config) severity(id string, def analysis.Severity) analysis.Severity {
+	if c != nil {
+		if s, _ := parseSeverity(c.Rules[id].Severity); s != nil {
+			return *s
+		}
+	}
+
+	return def
+}
+
+func (c *Config) params(id string) map[string]interface{} {
+	if c == nil {
+		return nil
+	}
+
+	return c.Rules[id].Params
+}
+
+// excluded проверяет путь файла относительно проверяемого каталога по шаблонам Exclude.
+// Кроме обычных шаблонов filepath.Match поддерживается префикс **/ - любой вложенный каталог
+func (c *Config) excluded(rel string) bool {
+	if c == nil {
+		return false
+	}
+
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range c.Exclude {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+
+		if suffix, ok := strings.CutPrefix(pattern, "**/"); ok {
+			parts := strings.Split(rel, "/")
+			for i := range parts {
+				if ok, _ := filepath.Match(suffix, strings.Join(parts[i:], "/")); ok {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func parseSeverity(s string) (*analysis.Severity, error) {
+	var result analysis.Severity
+	switch strings.ToLower(s) {
+	case "":
+		return nil, nil
+	case "error":
+		result = analysis.SeverityError
+	case "warning":
+		result = analysis.SeverityWarning
+	case "info":
+		result = analysis.SeverityInfo
+	default:
+		return nil, fmt.Errorf("unknown severity %q", s)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,91 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/analysis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+		return path
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		conf, err := LoadConfig(write("lint.yml", `
+rules:
+  UnusedVariable:
+    enabled: false
+  UseBeforeAssignment:
+    severity: Error
+    params:
+      limit: 10
+`))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.False(t, conf.enabled("UnusedVariable"))
+		assert.True(t, conf.enabled("UseBeforeAssignment"))
+		assert.True(t, conf.enabled("Unknown"))
+		assert.Equal(t, map[string]interface{}{"limit": 10}, conf.params("UseBeforeAssignment"))
+
+		assert.Equal(t, analysis.SeverityError, conf.severity("UseBeforeAssignment", analysis.SeverityWarning))
+		assert.Equal(t, analysis.SeverityInfo, conf.severity("IgnoredFunctionResult", analysis.SeverityInfo))
+	})
+	t.Run("json", func(t *testing.T) {
+		conf, err := LoadConfig(write("lint.json", `{"exclude": ["Ext/*.bsl"], "rules": {"MissingReturn": {"enabled": false}}}`))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.False(t, conf.enabled("MissingReturn"))
+		assert.True(t, conf.excluded("Ext/Module.bsl"))
+		assert.False(t, conf.excluded("Other/Ext/Module.bsl"))
+	})
+	t.Run("empty", func(t *testing.T) {
+		conf, err := LoadConfig(write("empty.yaml", ""))
+		assert.NoError(t, err)
+		assert.True(t, conf.enabled("MissingReturn"))
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := LoadConfig(write("lint.toml", ""))
+		assert.EqualError(t, err, `unsupported config format ".toml"`)
+
+		_, err = LoadConfig(filepath.Join(dir, "not-exist.json"))
+		assert.Error(t, err)
+
+		_, err = LoadConfig(write("unknown.json", `{"rule": {}}`))
+		assert.Error(t, err)
+
+		_, err = LoadConfig(write("severity.yaml", "rules:\n  MissingReturn:\n    severity: fatal\n"))
+		assert.EqualError(t, err, `rule "MissingReturn": unknown severity "fatal"`)
+	})
+	t.Run("unknown rules", func(t *testing.T) {
+		conf, err := LoadConfig(write("rules.yaml", "rules:\n  MissingReturn:\n    enabled: false\n  UnusedVariabel:\n    enabled: false\n  Other: {}\n"))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.EqualError(t, conf.Validate(DefaultRegistry), `unknown rules ["Other" "UnusedVariabel"]`)
+		assert.NoError(t, (*Config)(nil).Validate(DefaultRegistry))
+
+		_, err = NewRunner(conf).Run(dir)
+		assert.EqualError(t, err, `config error: unknown rules ["Other" "UnusedVariabel"]`)
+	})
+}
+
+func TestExcluded(t *testing.T) {
+	conf := &Config{Exclude: []string{"**/Ext/ObjectModule.bsl", "Temp/*"}}
+	assert.True(t, conf.excluded("Ext/ObjectModule.bsl"))
+	assert.True(t, conf.excluded(filepath.Join("Catalogs", "Товары", "Ext", "ObjectModule.bsl")))
+	assert.True(t, conf.excluded("Temp/Module.bsl"))
+	assert.False(t, conf.excluded("Catalogs/Ext/ManagerModule.bsl"))
+	assert.False(t, (*Config)(nil).excluded("Temp/Module.bsl"))
+}
@@ -0,0 +1,60 @@
+package lint
+
+import (
+	"fmt"
+	"io"
+)
+
+// JUnitReporter выводит отчет в формате JUnit XML, который показывают почти все серверы CI:
+// каждый файл - набор тестов, каждая диагностика - упавший тест
+type JUnitReporter struct{}
+
+type junitTestSuites struct {
+	XMLName  struct{}         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (r *JUnitReporter) Report(w io.Writer, diagnostics []Diagnostic) error {
+	report := junitTestSuites{Name: toolName, Tests: len(diagnostics), Failures: len(diagnostics)}
+	files, byFile := groupByFile(diagnostics)
+	for _, file := range files {
+		suite := junitTestSuite{Name: file, Tests: len(byFile[file]), Failures: len(byFile[file])}
+		for _, d := range byFile[file] {
+			location := fmt.Sprintf("%s:%d:%d", d.File, d.Range.Start.Line, d.Range.Start.Column)
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      fmt.Sprintf("%s %s", d.Rule, location),
+				ClassName: file,
+				Failure: junitFailure{
+					Message: d.Message,
+					Type:    d.Severity.String(),
+					Text:    fmt.Sprintf("%s: %s (%s)", location, d.Message, d.Rule),
+				},
+			})
+		}
+		report.Suites = append(report.Suites, suite)
+	}
+
+	return writeXML(w, report)
+}
@@ -0,0 +1,118 @@
+// Package lint запускает проверки модулей 1С: правила (Rule) регистрируются в реестре (Registry),
+// включаются и настраиваются файлом конфигурации (Config) в формате YAML или JSON, а Runner разбирает
+// каталог с файлами .bsl и собирает диагностики всех включенных правил
+package lint
+
+import (
+	"fmt"
+
+	"github.com/LazarenkoA/1c-language-parser/analysis"
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+// Rule правило проверки модуля. ID используется в конфигурации и в комментариях, отключающих правило
+type Rule interface {
+	ID() string
+	Severity() analysis.Severity
+	Description() string
+	Check(ctx *Context) []analysis.Diagnostic
+}
+
+// Diagnostic диагностика правила в файле. Severity уже учитывает настройку правила в конфигурации
+type Diagnostic struct {
+	File     string
+	Rule     string
+	Severity analysis.Severity
+	Message  string
+	Range    ast.Range
+	Fixes    []analysis.Fix
+}
+
+// Context модуль, который проверяет правило, и параметры правила из конфигурации
+type Context struct {
+	File   string
+	Source string
+	Module *ast.ModuleStatement
+	Params map[string]interface{}
+
+	resolution *ast.Resolution
+	shared     map[string][]analysis.Diagnostic // результаты проверок, общих для нескольких правил
+}
+
+// NewContext создает контекст для разобранного модуля
+func NewContext(file, source string, module *ast.ModuleStatement) *Context {
+	return &Context{File: file, Source: source, Module: module, shared: map[string][]analysis.Diagnostic{}}
+}
+
+// Resolution возвращает разрешение имен модуля, оно строится один раз для всех правил
+func (c *Context) Resolution() *ast.Resolution {
+	if c.resolution == nil {
+		c.resolution = ast.Resolve(c.Module)
+	}
+
+	return c.resolution
+}
+
+// Shared возвращает результат проверки name, которая выдает диагностики сразу нескольким правилам.
+// Проверка выполняется один раз на модуль для одинаковых настроек conf, остальные правила получают
+// сохраненный результат
+func (c *Context) Shared(name string, conf interface{}, check func() []analysis.Diagnostic) []analysis.Diagnostic {
+	key := fmt.Sprintf("%s %#v", name, conf)
+	if c.shared == nil {
+		return check()
+	}
+	if result, ok := c.shared[key]; ok {
+		return result
+	}
+
+	result := check()
+	c.shared[key] = result
+	return result
+}
+
+// Int возвращает числовой параметр правила или def, если параметр не задан
+func (c *Context) Int(name string, def int) int {
+	switch v := c.Params[name].(type) {
+	case int:
+		return v
+	case float64:
+		// JSON разбирает числа в float64
+		return int(v)
+	default:
+		return def
+	}
+}
+
+// String возвращает строковый параметр правила или def, если параметр не задан
+func (c *Context) String(name, def string) string {
+	if v, ok := c.Params[name].(string); ok {
+		return v
+	}
+
+	return def
+}
+
+// Bool возвращает логический параметр правила или def, если параметр не задан
+func (c *Context) Bool(name string, def bool) bool {
+	if v, ok := c.Params[name].(bool); ok {
+		return v
+	}
+
+	return def
+}
+
+// Strings возвращает параметр правила со списком строк или def, если параметр не задан
+func (c *Context) Strings(name string, def []string) []string {
+	switch v := c.Params[name].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			result = append(result, fmt.Sprint(item))
+		}
+		return result
+	default:
+		return def
+	}
+}
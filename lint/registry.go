@@ -0,0 +1,64 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry набор правил по ID
+type Registry struct {
+	mx    sync.RWMutex
+	rules map[string]Rule
+}
+
+// DefaultRegistry реестр со встроенными правилами пакета, в него же регистрируют свои правила через Register
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry создает пустой реестр
+func NewRegistry() *Registry {
+	return &Registry{rules: map[string]Rule{}}
+}
+
+// Register добавляет правило в реестр. Два правила с одним ID зарегистрировать нельзя
+func (r *Registry) Register(rule Rule) error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if _, ok := r.rules[rule.ID()]; ok {
+		return fmt.Errorf("rule %q is already registered", rule.ID())
+	}
+
+	r.rules[rule.ID()] = rule
+	return nil
+}
+
+// Rule возвращает правило по ID
+func (r *Registry) Rule(id string) (Rule, bool) {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	rule, ok := r.rules[id]
+	return rule, ok
+}
+
+// Rules возвращает все правила, упорядоченные по ID
+func (r *Registry) Rules() []Rule {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	result := make([]Rule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		result = append(result, rule)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID() < result[j].ID() })
+
+	return result
+}
+
+// Register добавляет правило в DefaultRegistry, паникует при повторной регистрации. Удобно вызывать из init
+func Register(rule Rule) {
+	if err := DefaultRegistry.Register(rule); err != nil {
+		panic(err)
+	}
+}
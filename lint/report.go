@@ -0,0 +1,86 @@
+package lint
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	toolName = "1c-language-parser"
+	toolURI  = "https://github.com/LazarenkoA/1c-language-parser"
+)
+
+// Reporter выводит диагностики в формате внешней системы (сервера CI, SonarQube, интерфейса код-ревью)
+type Reporter interface {
+	Report(w io.Writer, diagnostics []Diagnostic) error
+}
+
+// NewReporter создает Reporter по названию формата: sarif, checkstyle, junit или sonar.
+// Описания правил для форматов, которые их содержат, берутся из registry (nil - DefaultRegistry)
+func NewReporter(format string, registry *Registry) (Reporter, error) {
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	switch format {
+	case "sarif":
+		return &SARIFReporter{Registry: registry}, nil
+	case "checkstyle":
+		return &CheckstyleReporter{}, nil
+	case "junit":
+		return &JUnitReporter{}, nil
+	case "sonar":
+		return &SonarReporter{Registry: registry}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// reportRule правило, на которое ссылаются диагностики отчета
+type reportRule struct {
+	ID          string
+	Description string
+}
+
+// usedRules возвращает правила диагностик в порядке ID. Для ParseError и UnusedSuppression, которых нет в реестре,
+// описание задается здесь
+func usedRules(registry *Registry, diagnostics []Diagnostic) []reportRule {
+	var result []reportRule
+	seen := map[string]bool{}
+	for _, d := range diagnostics {
+		if seen[d.Rule] {
+			continue
+		}
+		seen[d.Rule] = true
+
+		rule := reportRule{ID: d.Rule}
+		switch d.Rule {
+		case RuleParseError:
+			rule.Description = "Синтаксическая ошибка, модуль не разобран"
+		case RuleUnusedSuppression:
+			rule.Description = "Комментарий parser:... ничего не отключает"
+		default:
+			if r, ok := registry.Rule(d.Rule); ok {
+				rule.Description = r.Description()
+			}
+		}
+		result = append(result, rule)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// groupByFile группирует диагностики по файлам в порядке первого появления файла
+func groupByFile(diagnostics []Diagnostic) (files []string, byFile map[string][]Diagnostic) {
+	byFile = map[string][]Diagnostic{}
+	for _, d := range diagnostics {
+		if _, ok := byFile[d.File]; !ok {
+			files = append(files, d.File)
+		}
+		byFile[d.File] = append(byFile[d.File], d)
+	}
+
+	return files, byFile
+}
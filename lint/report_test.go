@@ -0,0 +1,59 @@
+package lint
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "перезаписать эталонные отчеты в testdata/report")
+
+func TestReporters(t *testing.T) {
+	diagnostics, err := NewRunner(nil).Run("testdata/project")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for format, golden := range map[string]string{
+		"sarif":      "report.sarif",
+		"checkstyle": "checkstyle.xml",
+		"junit":      "junit.xml",
+		"sonar":      "sonar.json",
+	} {
+		t.Run(format, func(t *testing.T) {
+			reporter, err := NewReporter(format, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			buf := &bytes.Buffer{}
+			if !assert.NoError(t, reporter.Report(buf, diagnostics)) {
+				return
+			}
+
+			path := filepath.Join("testdata", "report", golden)
+			if *update {
+				assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+			}
+
+			expected, err := os.ReadFile(path)
+			if assert.NoError(t, err) {
+				assert.Equal(t, string(expected), buf.String())
+			}
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, (&SonarReporter{}).Report(buf, nil))
+		assert.Equal(t, "{\n  \"rules\": [],\n  \"issues\": []\n}\n", buf.String())
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := NewReporter("html", nil)
+		assert.EqualError(t, err, `unknown report format "html"`)
+	})
+}
@@ -0,0 +1,213 @@
+package lint
+
+import (
+	"github.com/LazarenkoA/1c-language-parser/analysis"
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+// analysisRule встроенное правило: диагностики одного кода из проверок пакета analysis
+type analysisRule struct {
+	id          string
+	severity    analysis.Severity
+	description string
+	check       func(ctx *Context) []analysis.Diagnostic
+}
+
+func (r *analysisRule) ID() string                  { return r.id }
+func (r *analysisRule) Severity() analysis.Severity { return r.severity }
+func (r *analysisRule) Description() string         { return r.description }
+
+func (r *analysisRule) Check(ctx *Context) []analysis.Diagnostic {
+	var result []analysis.Diagnostic
+	for _, d := range r.check(ctx) {
+		if d.Code == r.id {
+			result = append(result, d)
+		}
+	}
+
+	return result
+}
+
+func init() {
+	unused := func(ctx *Context) []analysis.Diagnostic {
+		conf := analysis.UnusedConf{
+			EventHandlers: ctx.Strings("eventHandlers", nil),
+			Undeclared:    ctx.Bool("undeclared", false),
+			Print:         ast.PrintConf{Margin: ctx.Int("margin", 4)},
+		}
+		return ctx.Shared("unused", conf, func() []analysis.Diagnostic {
+			return analysis.Unused(ctx.Source, ctx.Module, conf)
+		})
+	}
+	returns := func(ctx *Context) []analysis.Diagnostic {
+		return ctx.Shared("returns", nil, func() []analysis.Diagnostic {
+			return analysis.Returns(ctx.Module)
+		})
+	}
+	directives := func(ctx *Context) []analysis.Diagnostic {
+		return ctx.Shared("directives", nil, func() []analysis.Diagnostic {
+			return analysis.Directives(ctx.Module, analysis.DirectivesConf{})
+		})
+	}
+	transactions := func(ctx *Context) []analysis.Diagnostic {
+		return ctx.Shared("transactions", nil, func() []analysis.Diagnostic {
+			return analysis.Transactions(ctx.Module)
+		})
+	}
+	exceptions := func(ctx *Context) []analysis.Diagnostic {
+		return ctx.Shared("exceptions", nil, func() []analysis.Diagnostic {
+			return analysis.ExceptionHandlers(ctx.Module)
+		})
+	}
+
+	for _, rule := range []*analysisRule{
+		{
+			id:          analysis.CodeUnusedVariable,
+			severity:    analysis.SeverityWarning,
+			description: "Переменная Перем или локальная переменная не используется. Параметр undeclared - проверять и переменные без Перем (для модулей без реквизитов объекта или формы)",
+			check:       unused,
+		},
+		{
+			id:          analysis.CodeUnusedParameter,
+			severity:    analysis.SeverityWarning,
+			description: "Параметр метода не используется. Параметр eventHandlers - имена обработчиков событий, которые не проверяются",
+			check:       unused,
+		},
+		{
+			id:          analysis.CodeUnusedModuleVariable,
+			severity:    analysis.SeverityWarning,
+			description: "Неэкспортная переменная модуля не используется",
+			check:       unused,
+		},
+		{
+			id:          analysis.CodeUseBeforeAssignment,
+			severity:    analysis.SeverityWarning,
+			description: "Локальная переменная читается до присваивания. Параметр undeclared - проверять и имена, не объявленные в модуле (для модулей без реквизитов формы или объекта)",
+			check: func(ctx *Context) []analysis.Diagnostic {
+				return analysis.UseBeforeAssignment(ctx.Module, analysis.AssignedConf{Undeclared: ctx.Bool("undeclared", false)})
+			},
+		},
+		{
+			id:          analysis.CodeUnreachableCode,
+			severity:    analysis.SeverityWarning,
+			description: "Недостижимый код и неиспользуемые метки. Параметр margin - отступ в исправлениях",
+			check: func(ctx *Context) []analysis.Diagnostic {
+				return analysis.Unreachable(ctx.Source, ctx.Module, ast.PrintConf{Margin: ctx.Int("margin", 4)})
+			},
+		},
+		{
+			id:          analysis.CodeQueryInLoop,
+			severity:    analysis.SeverityWarning,
+			description: "Обращение к базе данных в цикле. Параметр methods - методы, которые обращаются к базе данных",
+			check: func(ctx *Context) []analysis.Diagnostic {
+				return analysis.QueriesInLoops(ctx.Module, analysis.QueryConf{Methods: ctx.Strings("methods", nil)})
+			},
+		},
+		{
+			id:          analysis.CodeClientCallFromServer,
+			severity:    analysis.SeverityError,
+			description: "Метод, выполняющийся на сервере, вызывает метод &НаКлиенте",
+			check:       directives,
+		},
+		{
+			id:          analysis.CodeContextCallFromNoContext,
+			severity:    analysis.SeverityError,
+			description: "Метод без контекста формы вызывает метод, которому нужен контекст",
+			check:       directives,
+		},
+		{
+			id:          analysis.CodeServerCallInLoop,
+			severity:    analysis.SeverityWarning,
+			description: "Клиентский метод обращается к серверу в цикле",
+			check:       directives,
+		},
+		{
+			id:          analysis.CodeTooManyServerCalls,
+			severity:    analysis.SeverityWarning,
+			description: "Клиентский метод обращается к серверу слишком много раз. Параметр maxServerCalls - допустимое количество вызовов",
+			check: func(ctx *Context) []analysis.Diagnostic {
+				return analysis.Directives(ctx.Module, analysis.DirectivesConf{MaxServerCalls: ctx.Int("maxServerCalls", 3)})
+			},
+		},
+		{
+			id:          analysis.CodeCyclomaticComplexity,
+			severity:    analysis.SeverityWarning,
+			description: "Цикломатическая сложность метода больше допустимой. Параметр max - порог, по умолчанию 20",
+			check: func(ctx *Context) []analysis.Diagnostic {
+				return analysis.ComplexMethods(ctx.Module, analysis.ComplexityConf{MaxCyclomatic: ctx.Int("max", 20)})
+			},
+		},
+		{
+			id:          analysis.CodeCognitiveComplexity,
+			severity:    analysis.SeverityWarning,
+			description: "Когнитивная сложность метода больше допустимой. Параметр max - порог, по умолчанию 15",
+			check: func(ctx *Context) []analysis.Diagnostic {
+				return analysis.ComplexMethods(ctx.Module, analysis.ComplexityConf{MaxCognitive: ctx.Int("max", 15)})
+			},
+		},
+		{
+			id:          analysis.CodeMissingReturn,
+			severity:    analysis.SeverityWarning,
+			description: "Не все пути выполнения функции возвращают значение",
+			check:       returns,
+		},
+		{
+			id:          analysis.CodeInconsistentReturn,
+			severity:    analysis.SeverityWarning,
+			description: "Функция возвращает значение не на всех Возврат",
+			check:       returns,
+		},
+		{
+			id:          analysis.CodeIgnoredFunctionResult,
+			severity:    analysis.SeverityInfo,
+			description: "Результат неэкспортной функции нигде не используется",
+			check:       returns,
+		},
+		{
+			id:          analysis.CodeCodeInjection,
+			severity:    analysis.SeverityError,
+			description: "Аргумент Выполнить или Вычислить не является константной строкой",
+			check: func(ctx *Context) []analysis.Diagnostic {
+				return analysis.CodeInjections(ctx.Module)
+			},
+		},
+		{
+			id:          analysis.CodeUnbalancedTransaction,
+			severity:    analysis.SeverityError,
+			description: "Транзакция не завершается на каком-то пути выполнения метода или завершается, когда ее может не быть",
+			check:       transactions,
+		},
+		{
+			id:          analysis.CodeNestedTransaction,
+			severity:    analysis.SeverityWarning,
+			description: "НачатьТранзакцию вызывается, когда транзакция уже может быть открыта",
+			check:       transactions,
+		},
+		{
+			id:          analysis.CodeTransactionPattern,
+			severity:    analysis.SeverityWarning,
+			description: "Транзакция оформлена не по стандарту: НачатьТранзакцию, Попытка с ЗафиксироватьТранзакцию, ОтменитьТранзакцию в Исключение",
+			check:       transactions,
+		},
+		{
+			id:          analysis.CodeTransactionInLoop,
+			severity:    analysis.SeverityWarning,
+			description: "Транзакция начинается в цикле",
+			check:       transactions,
+		},
+		{
+			id:          analysis.CodeSwallowedException,
+			severity:    analysis.SeverityWarning,
+			description: "Блок Исключение пустой или только присваивает переменные: ошибка не записывается в журнал и не вызывается повторно",
+			check:       exceptions,
+		},
+		{
+			id:          analysis.CodeRethrowOutsideCatch,
+			severity:    analysis.SeverityError,
+			description: "ВызватьИсключение без параметров вне блока Исключение",
+			check:       exceptions,
+		},
+	} {
+		Register(rule)
+	}
+}
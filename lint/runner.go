@@ -0,0 +1,194 @@
+package lint
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/LazarenkoA/1c-language-parser/analysis"
+	"github.com/LazarenkoA/1c-language-parser/ast"
+	"github.com/pkg/errors"
+)
+
+// RuleParseError ID диагностики, в которую превращается ошибка разбора модуля. Правила для такого модуля не запускаются
+const RuleParseError = "ParseError"
+
+// Runner запускает включенные правила реестра над модулями
+type Runner struct {
+	Registry *Registry // если не задан, используется DefaultRegistry
+	Config   *Config   // если не задан, включены все правила с настройками по умолчанию
+}
+
+// NewRunner создает Runner с правилами DefaultRegistry
+func NewRunner(conf *Config) *Runner {
+	return &Runner{Registry: DefaultRegistry, Config: conf}
+}
+
+// CheckFile разбирает модуль source и проверяет его. Имя file попадает в диагностики
+func (r *Runner) CheckFile(file, source string) []Diagnostic {
+	source = strings.TrimPrefix(source, "\uFEFF")
+
+	a := ast.NewAST(source)
+	if err := a.Parse(); err != nil {
+		return []Diagnostic{parseErrorDiagnostic(file, err)}
+	}
+
+	result := r.CheckModule(NewContext(file, source, &a.ModuleStatement))
+	// ошибка в строке Выполнить или Вычислить не мешает проверить модуль, поэтому это предупреждение
+	for _, w := range a.Warnings() {
+		d := parseErrorDiagnostic(file, w)
+		d.Severity = analysis.SeverityWarning
+		result = append(result, d)
+	}
+	SortDiagnostics(result)
+
+	return result
+}
+
+// CheckModule проверяет уже разобранный модуль всеми включенными правилами. Диагностики, отключенные
+// комментариями parser:off и parser:disable-next-line, не возвращаются
+func (r *Runner) CheckModule(ctx *Context) []Diagnostic {
+	var result []Diagnostic
+	ran := map[string]bool{}
+	if ctx.shared == nil {
+		ctx.shared = map[string][]analysis.Diagnostic{}
+	}
+	for _, rule := range r.registry().Rules() {
+		if !r.Config.enabled(rule.ID()) {
+			continue
+		}
+		ran[rule.ID()] = true
+
+		ruleCtx := *ctx
+		ruleCtx.Params = r.Config.params(rule.ID())
+		severity := r.Config.severity(rule.ID(), rule.Severity())
+		for _, d := range rule.Check(&ruleCtx) {
+			result = append(result, Diagnostic{
+				File:     ctx.File,
+				Rule:     rule.ID(),
+				Severity: severity,
+				Message:  d.Message,
+				Range:    d.Range,
+				Fixes:    d.Fixes,
+			})
+		}
+		// разрешение имен общее для всех правил
+		ctx.resolution = ruleCtx.resolution
+	}
+
+	result = r.suppress(ctx, result, ran)
+	SortDiagnostics(result)
+	return result
+}
+
+// Run проверяет все файлы .bsl каталога dir и вложенных каталогов, кроме исключенных в Config.Exclude.
+// Файлы разбираются параллельно, пути в диагностиках относительные от dir. Правила конфигурации,
+// которых нет в реестре, - ошибка
+func (r *Runner) Run(dir string) ([]Diagnostic, error) {
+	if err := r.Config.Validate(r.registry()); err != nil {
+		return nil, errors.Wrap(err, "config error")
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".bsl") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if !r.Config.excluded(rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "walk dir error")
+	}
+
+	results := make([][]Diagnostic, len(files))
+	errs := make([]error, len(files))
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				data, err := os.ReadFile(filepath.Join(dir, files[i]))
+				if err != nil {
+					errs[i] = errors.Wrap(err, "read file error")
+					continue
+				}
+				results[i] = r.CheckFile(filepath.ToSlash(files[i]), string(bytes.ToValidUTF8(data, nil)))
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var result []Diagnostic
+	for i := range files {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		result = append(result, results[i]...)
+	}
+
+	SortDiagnostics(result)
+	return result, nil
+}
+
+func (r *Runner) registry() *Registry {
+	if r.Registry == nil {
+		return DefaultRegistry
+	}
+
+	return r.Registry
+}
+
+// SortDiagnostics упорядочивает диагностики по файлу, позиции и правилу
+func SortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Range.Start.Offset != b.Range.Start.Offset {
+			return a.Range.Start.Offset < b.Range.Start.Offset
+		}
+		return a.Rule < b.Rule
+	})
+}
+
+func parseErrorDiagnostic(file string, err error) Diagnostic {
+	d := Diagnostic{
+		File:     file,
+		Rule:     RuleParseError,
+		Severity: analysis.SeverityError,
+		Message:  err.Error(),
+		Range:    ast.Range{Start: ast.Position{Line: 1, Column: 1}, End: ast.Position{Line: 1, Column: 1}},
+	}
+
+	var parseErr *ast.ParseError
+	if errors.As(err, &parseErr) {
+		pos := ast.Position{Line: parseErr.Line, Column: max(parseErr.Column, 1)}
+		d.Message = parseErr.Message
+		d.Range = ast.Range{Start: pos, End: pos}
+	}
+
+	return d
+}
@@ -0,0 +1,147 @@
+package lint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/LazarenkoA/1c-language-parser/analysis"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRule struct {
+	params []interface{}
+}
+
+func (r *testRule) ID() string                  { return "TestRule" }
+func (r *testRule) Severity() analysis.Severity { return analysis.SeverityInfo }
+func (r *testRule) Description() string         { return "правило для тестов" }
+
+func (r *testRule) Check(ctx *Context) []analysis.Diagnostic {
+	r.params = append(r.params, ctx.Int("limit", -1), ctx.String("name", ""), ctx.Strings("names", nil), ctx.Bool("strict", false))
+	return []analysis.Diagnostic{{Code: r.ID(), Message: fmt.Sprintf("%d methods", len(ctx.Resolution().Methods))}}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register(&testRule{}))
+	assert.EqualError(t, registry.Register(&testRule{}), `rule "TestRule" is already registered`)
+
+	rule, ok := registry.Rule("TestRule")
+	assert.True(t, ok)
+	assert.Equal(t, "TestRule", rule.ID())
+
+	var ids []string
+	for _, rule := range DefaultRegistry.Rules() {
+		ids = append(ids, rule.ID())
+	}
+	assert.Equal(t, []string{"ClientCallFromServer", "CodeInjection", "CognitiveComplexity", "ContextCallFromNoContext", "CyclomaticComplexity",
+		"IgnoredFunctionResult", "InconsistentReturn", "MissingReturn", "NestedTransaction", "QueryInLoop", "RethrowOutsideCatch", "ServerCallInLoop",
+		"SwallowedException", "TooManyServerCalls", "TransactionInLoop", "TransactionPattern", "UnbalancedTransaction", "UnreachableCode",
+		"UnusedModuleVariable", "UnusedParameter", "UnusedVariable", "UseBeforeAssignment"}, ids)
+}
+
+func TestRunner(t *testing.T) {
+	t.Run("dir", func(t *testing.T) {
+		runner := NewRunner(nil)
+		diagnostics, err := runner.Run("testdata/project")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var actual []string
+		for _, d := range diagnostics {
+			actual = append(actual, fmt.Sprintf("%s:%d:%d %s %s", d.File, d.Range.Start.Line, d.Range.Start.Column, d.Severity, d.Rule))
+		}
+		assert.Equal(t, []string{
+			"Broken/Module.bsl:2:6 error ParseError",
+			"Module.bsl:1:7 warning UnusedModuleVariable",
+			"Module.bsl:3:1 info IgnoredFunctionResult",
+			"Module.bsl:3:1 warning MissingReturn",
+		}, actual)
+	})
+	t.Run("config", func(t *testing.T) {
+		conf, err := ParseConfig([]byte(`
+exclude: ["**/Broken/*.bsl"]
+rules:
+  IgnoredFunctionResult:
+    enabled: false
+  MissingReturn:
+    severity: error
+  UnusedParameter:
+    params:
+      eventHandlers: [Сумма]
+  UnusedVariable:
+    params:
+      undeclared: true
+`), "yaml")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		diagnostics, err := NewRunner(conf).Run("testdata/project")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var actual []string
+		for _, d := range diagnostics {
+			actual = append(actual, fmt.Sprintf("%s %s %s", d.File, d.Severity, d.Rule))
+		}
+		assert.Equal(t, []string{
+			"Ext/ObjectModule.BSL warning UnusedVariable",
+			"Module.bsl warning UnusedModuleVariable",
+			"Module.bsl error MissingReturn",
+		}, actual)
+	})
+	t.Run("custom rule", func(t *testing.T) {
+		rule := &testRule{}
+		registry := NewRegistry()
+		assert.NoError(t, registry.Register(rule))
+
+		conf, err := ParseConfig([]byte(`{"rules": {"TestRule": {"params": {"limit": 3, "name": "а", "names": ["б", "в"], "strict": true}}}}`), "json")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		runner := &Runner{Registry: registry, Config: conf}
+		diagnostics := runner.CheckFile("module.bsl", "Процедура А()\nКонецПроцедуры\nПроцедура Б()\nКонецПроцедуры")
+		assert.Equal(t, []Diagnostic{{File: "module.bsl", Rule: "TestRule", Severity: analysis.SeverityInfo, Message: "2 methods"}}, diagnostics)
+		assert.Equal(t, []interface{}{3, "а", []string{"б", "в"}, true}, rule.params)
+	})
+	t.Run("shared analysis", func(t *testing.T) {
+		calls := 0
+		check := func(ctx *Context) []analysis.Diagnostic {
+			return ctx.Shared("count", nil, func() []analysis.Diagnostic {
+				calls++
+				return []analysis.Diagnostic{{Code: "First"}, {Code: "Second"}}
+			})
+		}
+
+		registry := NewRegistry()
+		assert.NoError(t, registry.Register(&analysisRule{id: "First", check: check}))
+		assert.NoError(t, registry.Register(&analysisRule{id: "Second", check: check}))
+
+		runner := &Runner{Registry: registry}
+		assert.Len(t, runner.CheckFile("module.bsl", "а = 1;"), 2)
+		assert.Len(t, runner.CheckFile("module.bsl", "б = 1;"), 2)
+		assert.Equal(t, 2, calls, "once per module")
+	})
+	t.Run("embedded code", func(t *testing.T) {
+		code := `Процедура А() Экспорт
+	Текст = "Текст";
+	Выполнить("Сообщить(Текст)");
+	Выполнить("Если Тогда");
+КонецПроцедуры`
+
+		var actual []string
+		for _, d := range NewRunner(nil).CheckFile("module.bsl", code) {
+			actual = append(actual, fmt.Sprintf("%d:%d %s %s: %s", d.Range.Start.Line, d.Range.Start.Column, d.Severity, d.Rule, d.Message))
+		}
+		// Текст используется в строке Выполнить, UnusedVariable нет
+		assert.Equal(t, []string{"4:17 warning ParseError: Выполнить argument: syntax error"}, actual)
+	})
+	t.Run("not exist", func(t *testing.T) {
+		_, err := NewRunner(nil).Run("testdata/not-exist")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,162 @@
+package lint
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/LazarenkoA/1c-language-parser/analysis"
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+// SARIFReporter выводит отчет в формате SARIF 2.1.0, его понимают GitHub code scanning и большинство IDE.
+// Колонки считаются в символах (columnKind unicodeCodePoints), исправления выводятся как fixes
+type SARIFReporter struct {
+	Registry *Registry
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+func (r *SARIFReporter) Report(w io.Writer, diagnostics []Diagnostic) error {
+	registry := r.Registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	run := sarifRun{
+		Tool:       sarifTool{Driver: sarifDriver{Name: toolName, InformationURI: toolURI, Rules: []sarifRule{}}},
+		ColumnKind: "unicodeCodePoints",
+		Results:    []sarifResult{},
+	}
+
+	index := map[string]int{}
+	for i, rule := range usedRules(registry, diagnostics) {
+		index[rule.ID] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: rule.ID, ShortDescription: sarifMessage{Text: rule.Description}})
+	}
+
+	for _, d := range diagnostics {
+		artifact := sarifArtifactLocation{URI: d.File}
+		result := sarifResult{
+			RuleID:    d.Rule,
+			RuleIndex: index[d.Rule],
+			Level:     sarifLevel(d.Severity),
+			Message:   sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifact, Region: sarifRegionOf(d.Range)}}},
+		}
+
+		for _, fix := range d.Fixes {
+			change := sarifArtifactChange{ArtifactLocation: artifact}
+			for _, edit := range fix.Edits {
+				change.Replacements = append(change.Replacements, sarifReplacement{
+					DeletedRegion:   sarifRegionOf(edit.Range),
+					InsertedContent: sarifMessage{Text: edit.NewText},
+				})
+			}
+			result.Fixes = append(result.Fixes, sarifFix{Description: sarifMessage{Text: fix.Message}, ArtifactChanges: []sarifArtifactChange{change}})
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+func sarifRegionOf(rng ast.Range) sarifRegion {
+	return sarifRegion{
+		StartLine:   rng.Start.Line,
+		StartColumn: rng.Start.Column,
+		EndLine:     rng.End.Line,
+		EndColumn:   rng.End.Column,
+	}
+}
+
+func sarifLevel(severity analysis.Severity) string {
+	switch severity {
+	case analysis.SeverityError:
+		return "error"
+	case analysis.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
@@ -0,0 +1,116 @@
+package lint
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/LazarenkoA/1c-language-parser/analysis"
+)
+
+// SonarReporter выводит отчет в формате SonarQube Generic Issue Import (SonarQube 10.3 и новее):
+//
+//	sonar.externalIssuesReportPaths=bsl-issues.json
+//
+// Колонки в этом формате считаются с нуля
+type SonarReporter struct {
+	Registry *Registry
+}
+
+type sonarReport struct {
+	Rules  []sonarRule  `json:"rules"`
+	Issues []sonarIssue `json:"issues"`
+}
+
+type sonarRule struct {
+	ID                 string        `json:"id"`
+	Name               string        `json:"name"`
+	Description        string        `json:"description"`
+	EngineID           string        `json:"engineId"`
+	CleanCodeAttribute string        `json:"cleanCodeAttribute"`
+	Impacts            []sonarImpact `json:"impacts"`
+}
+
+type sonarImpact struct {
+	SoftwareQuality string `json:"softwareQuality"`
+	Severity        string `json:"severity"`
+}
+
+type sonarIssue struct {
+	RuleID          string        `json:"ruleId"`
+	PrimaryLocation sonarLocation `json:"primaryLocation"`
+}
+
+type sonarLocation struct {
+	Message   string         `json:"message"`
+	FilePath  string         `json:"filePath"`
+	TextRange sonarTextRange `json:"textRange"`
+}
+
+type sonarTextRange struct {
+	StartLine   int  `json:"startLine"`
+	EndLine     *int `json:"endLine,omitempty"`
+	StartColumn *int `json:"startColumn,omitempty"`
+	EndColumn   *int `json:"endColumn,omitempty"`
+}
+
+func (r *SonarReporter) Report(w io.Writer, diagnostics []Diagnostic) error {
+	registry := r.Registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	// важность правила в SonarQube задается один раз, берем наибольшую из диагностик
+	severities := map[string]analysis.Severity{}
+	for _, d := range diagnostics {
+		if s, ok := severities[d.Rule]; !ok || d.Severity < s {
+			severities[d.Rule] = d.Severity
+		}
+	}
+
+	report := sonarReport{Rules: []sonarRule{}, Issues: []sonarIssue{}}
+	for _, rule := range usedRules(registry, diagnostics) {
+		quality := "MAINTAINABILITY"
+		if rule.ID == RuleParseError {
+			quality = "RELIABILITY"
+		}
+
+		report.Rules = append(report.Rules, sonarRule{
+			ID:                 rule.ID,
+			Name:               rule.ID,
+			Description:        rule.Description,
+			EngineID:           toolName,
+			CleanCodeAttribute: "CONVENTIONAL",
+			Impacts:            []sonarImpact{{SoftwareQuality: quality, Severity: sonarSeverity(severities[rule.ID])}},
+		})
+	}
+
+	for _, d := range diagnostics {
+		textRange := sonarTextRange{StartLine: d.Range.Start.Line}
+		// пустой участок SonarQube не принимает, для него указывается только строка
+		if d.Range.End.Line > d.Range.Start.Line || (d.Range.End.Line == d.Range.Start.Line && d.Range.End.Column > d.Range.Start.Column) {
+			endLine, startColumn, endColumn := d.Range.End.Line, d.Range.Start.Column-1, d.Range.End.Column-1
+			textRange.EndLine, textRange.StartColumn, textRange.EndColumn = &endLine, &startColumn, &endColumn
+		}
+
+		report.Issues = append(report.Issues, sonarIssue{
+			RuleID:          d.Rule,
+			PrimaryLocation: sonarLocation{Message: d.Message, FilePath: d.File, TextRange: textRange},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(report)
+}
+
+func sonarSeverity(severity analysis.Severity) string {
+	switch severity {
+	case analysis.SeverityError:
+		return "HIGH"
+	case analysis.SeverityWarning:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
@@ -0,0 +1,240 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LazarenkoA/1c-language-parser/analysis"
+	"github.com/LazarenkoA/1c-language-parser/ast"
+)
+
+// RuleUnusedSuppression ID диагностики о комментарии parser:..., который ничего не отключил, отключает неизвестное
+// правило или не разбирается. Ее можно отключить или поменять важность в конфигурации, как обычное правило
+const RuleUnusedSuppression = "UnusedSuppression"
+
+const suppressionPrefix = "parser:"
+
+// suppression участок кода, в котором отключено одно правило (rule == "" - все правила)
+type suppression struct {
+	comment    ast.Comment
+	directive  string
+	rule       string
+	start, end int // смещения в байтах, end не входит в участок
+	line, last int // для disable-next-line - первая и последняя строки, иначе 0
+	used       bool
+}
+
+func (s *suppression) covers(d Diagnostic) bool {
+	if s.rule != "" && s.rule != d.Rule {
+		return false
+	}
+	if s.line > 0 {
+		return s.line <= d.Range.Start.Line && d.Range.Start.Line <= s.last
+	}
+
+	return s.start <= d.Range.Start.Offset && d.Range.Start.Offset < s.end
+}
+
+// suppress убирает диагностики, отключенные комментариями модуля:
+//
+//	// parser:off ИмяПравила, ДругоеПравило - до parser:on или до конца конструкции (Если, цикла, Попытки), метода или модуля
+//	// parser:on ИмяПравила - снова включает правила, без имен включает все
+//	// parser:disable-next-line ИмяПравила - только для следующей строки с кодом. Если это начало метода,
+//	//   то и для заголовка метода вместе с директивами (&НаСервере) и параметрами
+//
+// Без имен правил отключаются все правила. Для комментариев, которые ничего не отключили, добавляется
+// диагностика RuleUnusedSuppression. ran - правила, которые запускались: отключение правила, выключенного
+// в конфигурации, неиспользуемым не считается
+func (r *Runner) suppress(ctx *Context, diagnostics []Diagnostic, ran map[string]bool) []Diagnostic {
+	var problems []Diagnostic
+	report := func(c ast.Comment, format string, args ...interface{}) {
+		problems = append(problems, Diagnostic{
+			File:    ctx.File,
+			Rule:    RuleUnusedSuppression,
+			Message: fmt.Sprintf(format, args...),
+			Range:   ast.Range{Start: c.Pos, End: c.End},
+		})
+	}
+
+	var suppressions []*suppression
+	for _, c := range ctx.Module.Comments {
+		directive, rules, ok := parseSuppression(c.Text)
+		if !ok {
+			continue
+		}
+
+		for _, rule := range rules {
+			if _, known := r.registry().Rule(rule); !known && rule != RuleUnusedSuppression {
+				report(c, "unknown rule %q in %s%s", rule, suppressionPrefix, directive)
+			}
+		}
+		if len(rules) == 0 {
+			rules = []string{""}
+		}
+
+		switch directive {
+		case "off":
+			end := scopeEnd(ctx.Module, c.Pos.Offset, len(ctx.Source))
+			for _, rule := range rules {
+				suppressions = append(suppressions, &suppression{comment: c, directive: directive, rule: rule, start: c.End.Offset, end: end})
+			}
+		case "on":
+			closed := false
+			for _, s := range suppressions {
+				if s.directive == "off" && s.start <= c.Pos.Offset && c.Pos.Offset < s.end && (rules[0] == "" || contains(rules, s.rule)) {
+					s.end = c.Pos.Offset
+					closed = true
+				}
+			}
+			if !closed {
+				report(c, "%son has no matching %soff", suppressionPrefix, suppressionPrefix)
+			}
+		case "disable-next-line":
+			line := nextCodeLine(ctx.Source, c.End.Offset, c.Pos.Line)
+			last := methodHeaderEnd(ctx, line)
+			for _, rule := range rules {
+				suppressions = append(suppressions, &suppression{comment: c, directive: directive, rule: rule, line: line, last: last})
+			}
+		default:
+			report(c, "unknown suppression directive %s%s", suppressionPrefix, directive)
+		}
+	}
+
+	result := diagnostics[:0]
+	for _, d := range diagnostics {
+		suppressed := false
+		for _, s := range suppressions {
+			if d.Rule != RuleParseError && s.covers(d) {
+				s.used = true
+				suppressed = true
+			}
+		}
+		if !suppressed {
+			result = append(result, d)
+		}
+	}
+
+	for _, s := range suppressions {
+		if s.used || (s.rule != "" && !ran[s.rule]) {
+			continue
+		}
+		if s.rule == "" {
+			report(s.comment, "%s%s does not suppress any diagnostic", suppressionPrefix, s.directive)
+		} else {
+			report(s.comment, "%s%s %s does not suppress any diagnostic", suppressionPrefix, s.directive, s.rule)
+		}
+	}
+
+	if r.Config.enabled(RuleUnusedSuppression) {
+		for i := range problems {
+			problems[i].Severity = r.Config.severity(RuleUnusedSuppression, analysis.SeverityWarning)
+		}
+		result = append(result, problems...)
+	}
+
+	return result
+}
+
+// parseSuppression разбирает текст комментария "// parser:директива Правило1, Правило2"
+func parseSuppression(text string) (directive string, rules []string, ok bool) {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "//"))
+	if !strings.HasPrefix(text, suppressionPrefix) {
+		return "", nil, false
+	}
+
+	fields := strings.FieldsFunc(text[len(suppressionPrefix):], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return "", nil, true
+	}
+
+	return fields[0], fields[1:], true
+}
+
+// scopeEnd возвращает конец самой вложенной конструкции (Если, цикла, Попытки) или метода, внутри которых
+// находится offset. Вне методов это конец модуля
+func scopeEnd(module *ast.ModuleStatement, offset, end int) int {
+	var visit func(items ast.Statements)
+	visit = func(items ast.Statements) {
+		for _, item := range items {
+			switch item.(type) {
+			case *ast.FunctionOrProcedure, *ast.IfStatement, *ast.LoopStatement, ast.TryStatement:
+			default:
+				continue
+			}
+
+			rng := ast.RangeOf(item)
+			if offset < rng.Start.Offset || offset >= rng.End.Offset {
+				continue
+			}
+
+			end = rng.End.Offset
+			switch v := item.(type) {
+			case *ast.FunctionOrProcedure:
+				visit(v.Body)
+			case *ast.IfStatement:
+				visit(v.TrueBlock)
+				for _, elseIf := range v.IfElseBlock {
+					visit(elseIf.(*ast.IfStatement).TrueBlock)
+				}
+				visit(v.ElseBlock)
+			case *ast.LoopStatement:
+				visit(v.Body)
+			case ast.TryStatement:
+				visit(v.Body)
+				visit(v.Catch)
+			}
+			return
+		}
+	}
+
+	visit(module.Body)
+	return end
+}
+
+// methodHeaderEnd последняя строка заголовка метода, который начинается в строке line (с директивы или с
+// Процедура/Функция), до последнего параметра. Если в строке line метод не начинается, возвращается line
+func methodHeaderEnd(ctx *Context, line int) int {
+	for _, item := range ctx.Module.Body {
+		pf, ok := item.(*ast.FunctionOrProcedure)
+		if !ok || ast.RangeOf(pf).Start.Line != line {
+			continue
+		}
+
+		last := pf.Pos.Line
+		if n := len(pf.Directives); n > 0 && pf.Directives[n-1] != nil {
+			end := pf.Directives[n-1].End
+			last = nextCodeLine(ctx.Source, end.Offset, end.Line)
+		}
+		for _, p := range pf.Params {
+			last = max(last, p.End.Line)
+		}
+		return last
+	}
+
+	return line
+}
+
+// nextCodeLine номер первой строки после offset, в которой есть что-то кроме пробелов и комментария
+func nextCodeLine(source string, offset, line int) int {
+	for _, text := range strings.Split(source[offset:], "\n")[1:] {
+		line++
+		text = strings.TrimSpace(text)
+		if text != "" && !strings.HasPrefix(text, "//") {
+			return line
+		}
+	}
+
+	return line + 1
+}
+
+func contains(items []string, item string) bool {
+	for _, v := range items {
+		if v == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package lint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuppress(t *testing.T) {
+	check := func(code string, conf *Config) []string {
+		// в тестах модуль без реквизитов, локальные переменные проверяются все
+		if conf == nil {
+			conf = &Config{}
+		}
+		if conf.Rules == nil {
+			conf.Rules = map[string]RuleConfig{}
+		}
+		conf.Rules["UnusedVariable"] = RuleConfig{Params: map[string]interface{}{"undeclared": true}}
+
+		var result []string
+		for _, d := range NewRunner(conf).CheckFile("module.bsl", code) {
+			result = append(result, fmt.Sprintf("%d %s %s: %s", d.Range.Start.Line, d.Severity, d.Rule, d.Message))
+		}
+		return result
+	}
+
+	t.Run("next line", func(t *testing.T) {
+		code := `Процедура А()
+	// parser:disable-next-line UnusedVariable
+	// пояснение
+	Х = 1;
+	У = 2; // parser:disable-next-line
+	Я = 3;
+КонецПроцедуры`
+
+		assert.Equal(t, []string{
+			`5 warning UnusedVariable: variable "У" is assigned but never used`,
+		}, check(code, nil))
+	})
+	t.Run("next line method", func(t *testing.T) {
+		code := `// parser:disable-next-line UnusedParameter
+&НаСервере
+Процедура А(
+	Парам)
+	Х = 1;
+КонецПроцедуры
+
+// parser:disable-next-line UnusedParameter
+Процедура Б(Парам)
+КонецПроцедуры
+
+&НаСервере
+// parser:disable-next-line UnusedParameter
+Процедура В(Парам, Второй)
+	Х = Второй;
+КонецПроцедуры`
+
+		assert.Equal(t, []string{
+			`5 warning UnusedVariable: variable "Х" is assigned but never used`,
+			`15 warning UnusedVariable: variable "Х" is assigned but never used`,
+		}, check(code, nil))
+	})
+	t.Run("block", func(t *testing.T) {
+		code := `Процедура А(Парам)
+	Если Истина Тогда
+		// parser:off UnusedVariable, UseBeforeAssignment
+		Х = 1;
+		Сообщить(Ю);
+		Ю = 1;
+	КонецЕсли;
+	У = 2;
+КонецПроцедуры
+
+// parser:off UnusedParameter
+Процедура Б(Парам)
+	// parser:on
+	Я = 3;
+КонецПроцедуры
+
+Процедура В(Парам)
+КонецПроцедуры`
+
+		assert.Equal(t, []string{
+			`1 warning UnusedParameter: parameter "Парам" is never used`,
+			`8 warning UnusedVariable: variable "У" is assigned but never used`,
+			`14 warning UnusedVariable: variable "Я" is assigned but never used`,
+			`17 warning UnusedParameter: parameter "Парам" is never used`,
+		}, check(code, nil))
+	})
+	t.Run("method", func(t *testing.T) {
+		code := `Процедура А()
+	// parser:off
+	Х = 1;
+КонецПроцедуры
+
+Процедура Б()
+	У = 2;
+КонецПроцедуры`
+
+		assert.Equal(t, []string{
+			`7 warning UnusedVariable: variable "У" is assigned but never used`,
+		}, check(code, nil))
+	})
+	t.Run("unused", func(t *testing.T) {
+		code := `Процедура А()
+	// parser:off UnusedVariable, MissingReturn
+	Х = 1;
+	// parser:on
+	// parser:on
+	// parser:disable-next-line
+	Сообщить(1);
+	// parser:skip
+	// parser:off НетТакого
+	// parser:disable-next-line IgnoredFunctionResult
+	Сообщить(2);
+КонецПроцедуры`
+
+		assert.Equal(t, []string{
+			`2 warning UnusedSuppression: parser:off MissingReturn does not suppress any diagnostic`,
+			`5 warning UnusedSuppression: parser:on has no matching parser:off`,
+			`6 warning UnusedSuppression: parser:disable-next-line does not suppress any diagnostic`,
+			`8 warning UnusedSuppression: unknown suppression directive parser:skip`,
+			`9 warning UnusedSuppression: unknown rule "НетТакого" in parser:off`,
+		}, check(code, &Config{Rules: map[string]RuleConfig{"IgnoredFunctionResult": {Enabled: new(bool)}}}))
+
+		assert.Empty(t, check(code, &Config{Rules: map[string]RuleConfig{
+			"IgnoredFunctionResult": {Enabled: new(bool)},
+			"UnusedSuppression":     {Enabled: new(bool)},
+		}}))
+	})
+	t.Run("parse error", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`2 error ParseError: syntax error`,
+		}, check("// parser:off\nЕсли Тогда", nil))
+	})
+}